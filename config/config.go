@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"strings"
 	"time"
 
@@ -28,12 +29,19 @@ import (
 	"github.com/uber/jaeger-client-go/internal/baggage/remote"
 	throttler "github.com/uber/jaeger-client-go/internal/throttler/remote"
 	"github.com/uber/jaeger-client-go/rpcmetrics"
-	"github.com/uber/jaeger-client-go/transport"
+	"github.com/uber/jaeger-client-go/x"
 	"github.com/uber/jaeger-lib/metrics"
 )
 
 const defaultSamplingProbability = 0.001
 
+// Composite sampler types, compared case-insensitively against SamplerConfig.Type.
+const (
+	samplerTypePriority    = x.SamplerTypePriority
+	samplerTypeTagMatching = x.SamplerTypeTagMatching
+	samplerTypeRules       = x.SamplerTypeRules
+)
+
 // Configuration configures and creates Jaeger Tracer
 type Configuration struct {
 	// ServiceName specifies the service name to use on the tracer.
@@ -52,19 +60,100 @@ type Configuration struct {
 	// Value can be provided by FromEnv() via the environment variable named JAEGER_TRACEID_128BIT.
 	Gen128Bit bool `yaml:"traceid_128bit"`
 
+	// MaxTagValueLength is the maximum length of string tag values, longer values are truncated.
+	// Value can be provided by FromEnv() via the environment variable named JAEGER_MAX_TAG_VALUE_LENGTH.
+	MaxTagValueLength int `yaml:"maxTagValueLength"`
+
+	// MaxLogsPerSpan limits the number of logs per span, see jaeger.TracerOptions.MaxLogsPerSpan.
+	// Value can be provided by FromEnv() via the environment variable named JAEGER_MAX_LOGS_PER_SPAN.
+	MaxLogsPerSpan int `yaml:"maxLogsPerSpan"`
+
+	// MaxTagsPerSpan limits the number of tags per span, see jaeger.TracerOptions.MaxTagsPerSpan.
+	// Value can be provided by FromEnv() via the environment variable named JAEGER_MAX_TAGS_PER_SPAN.
+	MaxTagsPerSpan int `yaml:"maxTagsPerSpan"`
+
+	// MaxReferencesPerSpan limits the number of references per span, see jaeger.TracerOptions.MaxReferencesPerSpan.
+	// Value can be provided by FromEnv() via the environment variable named JAEGER_MAX_REFERENCES_PER_SPAN.
+	MaxReferencesPerSpan int `yaml:"maxReferencesPerSpan"`
+
+	// MaxLinksPerSpan limits the number of links added to spans, see jaeger.TracerOptions.MaxLinksPerSpan.
+	// Value can be provided by FromEnv() via the environment variable named JAEGER_MAX_LINKS_PER_SPAN.
+	MaxLinksPerSpan int `yaml:"maxLinksPerSpan"`
+
+	// MaxFieldsPerLog limits the number of fields per span log, see jaeger.TracerOptions.MaxFieldsPerLog.
+	// Value can be provided by FromEnv() via the environment variable named JAEGER_MAX_FIELDS_PER_LOG.
+	MaxFieldsPerLog int `yaml:"maxFieldsPerLog"`
+
+	// MaxSpanSize limits the serialized size of spans in bytes, see jaeger.TracerOptions.MaxSpanSize.
+	// Value can be provided by FromEnv() via the environment variable named JAEGER_MAX_SPAN_SIZE.
+	MaxSpanSize int `yaml:"maxSpanSize"`
+
+	// PoolSpans enables the pooling of span objects, see jaeger.TracerOptions.PoolSpans.
+	// Value can be provided by FromEnv() via the environment variable named JAEGER_POOL_SPANS.
+	PoolSpans bool `yaml:"poolSpans"`
+
+	// PprofLabels sets pprof labels identifying the sampled local root spans on their
+	// goroutines, see jaeger.TracerOptions.PprofLabels.
+	// Value can be provided by FromEnv() via the environment variable named JAEGER_PPROF_LABELS.
+	PprofLabels bool `yaml:"pprofLabels"`
+
+	// RecordPanics makes a deferred Span.Finish record a panic on the span and report
+	// it synchronously before propagating the panic, see jaeger.TracerOptions.RecordPanics.
+	// Value can be provided by FromEnv() via the environment variable named JAEGER_RECORD_PANICS.
+	RecordPanics bool `yaml:"recordPanics"`
+
+	// ZipkinSharedRPCSpan enables Zipkin-style RPC spans shared between client and server.
+	// Value can be provided by FromEnv() via the environment variable named JAEGER_ZIPKIN_SHARED_RPC_SPAN.
+	ZipkinSharedRPCSpan bool `yaml:"zipkinSharedRPCSpan"`
+
+	// NoDebugFlagOnForcedSampling prevents the debug flag from being set on traces
+	// sampled because of the sampling.priority tag.
+	// Value can be provided by FromEnv() via the environment variable named
+	// JAEGER_NO_DEBUG_FLAG_ON_FORCED_SAMPLING.
+	NoDebugFlagOnForcedSampling bool `yaml:"noDebugFlagOnForcedSampling"`
+
 	// Tags can be provided by FromEnv() via the environment variable named JAEGER_TAGS
 	Tags []opentracing.Tag `yaml:"tags"`
 
-	Sampler             *SamplerConfig             `yaml:"sampler"`
-	Reporter            *ReporterConfig            `yaml:"reporter"`
-	Headers             *jaeger.HeadersConfig      `yaml:"headers"`
+	// ResourceDetectors are the names of the built-in resource detectors whose tags are added to
+	// the tracer-level tags: "container", "kubernetes", "go", "process" and "build" (see package
+	// resource). The detected tags do not override the Tags with the same key.
+	// Can be provided by FromEnv() via the environment variable named JAEGER_RESOURCE_DETECTORS
+	// as a comma separated list of names.
+	ResourceDetectors []string `yaml:"resourceDetectors"`
+
+	// Propagators are the names of the propagation formats used to inject and extract
+	// the span context in the TextMap and HTTPHeaders formats, e.g. "jaeger" or "b3multi".
+	// When several are listed, all of them are injected and the first one found in the
+	// carrier is extracted. "none" disables propagation. Other propagators defined by
+	// OpenTelemetry, such as "tracecontext", are not supported and are ignored.
+	// By default only the Jaeger propagation format is used.
+	// Can be provided by FromEnv() via the environment variable named OTEL_PROPAGATORS
+	Propagators []string `yaml:"propagators"`
+
+	Sampler  *SamplerConfig  `yaml:"sampler"`
+	Reporter *ReporterConfig `yaml:"reporter"`
+
+	// Headers can be provided by FromEnv() via the environment variables named
+	// JAEGER_HEADERS_DEBUG, JAEGER_HEADERS_BAGGAGE, JAEGER_HEADERS_TRACE_CONTEXT
+	// and JAEGER_HEADERS_BAGGAGE_PREFIX.
+	Headers *jaeger.HeadersConfig `yaml:"headers"`
+
+	// BaggageRestrictions can be provided by FromEnv() via the environment variables named
+	// JAEGER_BAGGAGE_RESTRICTIONS_DENY_ON_INIT_FAILURE, JAEGER_BAGGAGE_RESTRICTIONS_HOST_PORT
+	// and JAEGER_BAGGAGE_RESTRICTIONS_REFRESH_INTERVAL.
 	BaggageRestrictions *BaggageRestrictionsConfig `yaml:"baggage_restrictions"`
-	Throttler           *ThrottlerConfig           `yaml:"throttler"`
+
+	// Throttler can be provided by FromEnv() via the environment variables named
+	// JAEGER_THROTTLER_HOST_PORT, JAEGER_THROTTLER_REFRESH_INTERVAL and
+	// JAEGER_THROTTLER_SYNCHRONOUS_INITIALIZATION.
+	Throttler *ThrottlerConfig `yaml:"throttler"`
 }
 
 // SamplerConfig allows initializing a non-default sampler.  All fields are optional.
 type SamplerConfig struct {
-	// Type specifies the type of the sampler: const, probabilistic, rateLimiting, or remote.
+	// Type specifies the type of the sampler: const, probabilistic, rateLimiting, remote,
+	// priority, tagMatching, or rules.
 	// Can be provided by FromEnv() via the environment variable named JAEGER_SAMPLER_TYPE
 	Type string `yaml:"type"`
 
@@ -104,8 +193,45 @@ type SamplerConfig struct {
 	// For backwards compatibility this option is off by default.
 	OperationNameLateBinding bool `yaml:"operationNameLateBinding"`
 
+	// Delegates are the samplers consulted, in order, by the "priority" sampler
+	// (see x.PrioritySampler). The first delegate to make a positive sampling decision
+	// wins. Each delegate is configured the same way as the top-level sampler, so
+	// sampler trees of arbitrary depth can be declared.
+	// Can be provided by FromEnv() via the environment variable named JAEGER_SAMPLER_DELEGATES
+	// as a JSON or YAML list.
+	Delegates []SamplerConfig `yaml:"delegates"`
+
+	// TagKey is the span tag inspected by the "tagMatching" sampler (see x.TagMatchingSampler).
+	TagKey string `yaml:"tagKey"`
+
+	// TagMatchers are the values of TagKey that make the "tagMatching" sampler sample the trace.
+	TagMatchers []x.TagMatcher `yaml:"tagMatchers"`
+
+	// Rules are the sampling rules of the "rules" sampler (see x.RulesSampler).
+	Rules []x.SamplingRule `yaml:"rules"`
+
 	// Options can be used to programmatically pass additional options to the Remote sampler.
-	Options []jaeger.SamplerOption
+	Options []jaeger.SamplerOption `yaml:"-"`
+
+	// TLS configures the connections to an https SamplingServerURL.
+	// Can be provided by FromEnv() via the environment variables named JAEGER_SAMPLER_TLS_CA,
+	// JAEGER_SAMPLER_TLS_CERT, JAEGER_SAMPLER_TLS_KEY, JAEGER_SAMPLER_TLS_SERVER_NAME,
+	// JAEGER_SAMPLER_TLS_MIN_VERSION and JAEGER_SAMPLER_TLS_SKIP_HOST_VERIFY.
+	TLS *TLSConfig `yaml:"tls"`
+
+	// AuthToken is a bearer token sent to the sampling server.
+	// Can be provided by FromEnv() via the environment variable named JAEGER_SAMPLER_AUTH_TOKEN
+	AuthToken string `yaml:"authToken"`
+
+	// AuthTokenFile is the path of a file containing a bearer token sent to the sampling server.
+	// The file is read again when it changes, e.g. for short-lived service account tokens.
+	// Can be provided by FromEnv() via the environment variable named JAEGER_SAMPLER_AUTH_TOKEN_FILE
+	AuthTokenFile string `yaml:"authTokenFile"`
+
+	// Credentials can be used to programmatically authenticate the requests sent to the sampling
+	// server, e.g. with jaeger.NewTokenExchangeCredentials. It takes precedence over AuthToken
+	// and AuthTokenFile.
+	Credentials jaeger.HTTPCredentials `yaml:"-"`
 }
 
 // ReporterConfig configures the reporter. All fields are optional.
@@ -119,7 +245,7 @@ type ReporterConfig struct {
 	// BufferFlushInterval controls how often the buffer is force-flushed, even if it's not full.
 	// It is generally not useful, as it only matters for very low traffic services.
 	// Can be provided by FromEnv() via the environment variable named JAEGER_REPORTER_FLUSH_INTERVAL
-	BufferFlushInterval time.Duration
+	BufferFlushInterval time.Duration `yaml:"bufferFlushInterval"`
 
 	// LogSpans, when true, enables LoggingReporter that runs in parallel with the main reporter
 	// and logs all submitted spans. Main Configuration.Logger must be initialized in the code
@@ -140,12 +266,32 @@ type ReporterConfig struct {
 	// AttemptReconnectInterval controls how often the agent client re-resolves the provided hostname
 	// in order to detect address changes. This option only applies if DisableAttemptReconnecting is false.
 	// Can be provided by FromEnv() via the environment variable named JAEGER_REPORTER_ATTEMPT_RECONNECT_INTERVAL
-	AttemptReconnectInterval time.Duration
+	AttemptReconnectInterval time.Duration `yaml:"attemptReconnectInterval"`
 
 	// CollectorEndpoint instructs reporter to send spans to jaeger-collector at this URL.
+	// The transport is selected by the URL scheme among the ones registered via RegisterTransport,
+	// http and https URLs use the built-in HTTPTransport.
 	// Can be provided by FromEnv() via the environment variable named JAEGER_ENDPOINT
 	CollectorEndpoint string `yaml:"collectorEndpoint"`
 
+	// CollectorEndpoints are additional collector URLs with the same scheme as the CollectorEndpoint.
+	// The built-in HTTPTransport balances the batches of spans across all the endpoints in round robin,
+	// skipping the unhealthy ones and retrying a failed batch on the next endpoint.
+	// Can be provided by FromEnv() via the environment variable named JAEGER_ENDPOINTS
+	// as a comma separated list of URLs.
+	CollectorEndpoints []string `yaml:"collectorEndpoints"`
+
+	// CollectorSRV is the name of a DNS SRV record, e.g. "_jaeger-collector._tcp.example.com",
+	// periodically resolved by the built-in HTTPTransport into the collector endpoints. Their URLs
+	// are built from the CollectorEndpoint, replacing its host with the target and port of each record.
+	// Can be provided by FromEnv() via the environment variable named JAEGER_ENDPOINT_SRV
+	CollectorSRV string `yaml:"collectorSRV"`
+
+	// TransportOptions are passed to the transport registered for the scheme of the CollectorEndpoint.
+	// Can be provided by FromEnv() via the environment variable named JAEGER_REPORTER_TRANSPORT_OPTIONS
+	// as a comma separated list of name=value pairs.
+	TransportOptions map[string]interface{} `yaml:"transportOptions"`
+
 	// User instructs reporter to include a user for basic http authentication when sending spans to jaeger-collector.
 	// Can be provided by FromEnv() via the environment variable named JAEGER_USER
 	User string `yaml:"user"`
@@ -157,7 +303,34 @@ type ReporterConfig struct {
 
 	// HTTPHeaders instructs the reporter to add these headers to the http request when reporting spans.
 	// This field takes effect only when using HTTPTransport by setting the CollectorEndpoint.
+	// Can be provided by FromEnv() via the environment variable named JAEGER_REPORTER_HTTP_HEADERS
+	// as a comma separated list of name=value pairs.
 	HTTPHeaders map[string]string `yaml:"http_headers"`
+
+	// TLS configures the connections to an https CollectorEndpoint.
+	// Can be provided by FromEnv() via the environment variables named JAEGER_REPORTER_TLS_CA,
+	// JAEGER_REPORTER_TLS_CERT, JAEGER_REPORTER_TLS_KEY, JAEGER_REPORTER_TLS_SERVER_NAME,
+	// JAEGER_REPORTER_TLS_MIN_VERSION and JAEGER_REPORTER_TLS_SKIP_HOST_VERIFY.
+	TLS *TLSConfig `yaml:"tls"`
+
+	// AuthToken instructs reporter to include a bearer token when sending spans to jaeger-collector.
+	// Can be provided by FromEnv() via the environment variable named JAEGER_AUTH_TOKEN
+	AuthToken string `yaml:"authToken"`
+
+	// AuthTokenFile instructs reporter to include the bearer token read from this file when sending
+	// spans to jaeger-collector. The file is read again when it changes, e.g. for short-lived
+	// service account tokens.
+	// Can be provided by FromEnv() via the environment variable named JAEGER_AUTH_TOKEN_FILE
+	AuthTokenFile string `yaml:"authTokenFile"`
+
+	// Credentials can be used to programmatically authenticate the requests sent to jaeger-collector,
+	// e.g. with jaeger.NewTokenExchangeCredentials. It takes precedence over AuthToken, AuthTokenFile,
+	// User and Password.
+	Credentials jaeger.HTTPCredentials `yaml:"-"`
+
+	// envWarnings holds the environment variables ignored by FromEnv, logged by NewTracer
+	// through the configured logger.
+	envWarnings []string
 }
 
 // BaggageRestrictionsConfig configures the baggage restrictions manager which can be used to whitelist
@@ -225,7 +398,16 @@ func (c Configuration) NewTracer(options ...Option) (opentracing.Tracer, io.Clos
 		return nil, nil, errors.New("no service name provided")
 	}
 
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+
 	opts := applyOptions(options...)
+	if c.Reporter != nil {
+		for _, warning := range c.Reporter.envWarnings {
+			opts.logger.Error(warning)
+		}
+	}
 	tracerMetrics := jaeger.NewMetrics(opts.metrics, nil)
 	if c.RPCMetrics {
 		Observer(
@@ -235,42 +417,74 @@ func (c Configuration) NewTracer(options ...Option) (opentracing.Tracer, io.Clos
 			),
 		)(&opts) // adds to c.observers
 	}
-	if c.Sampler == nil {
-		c.Sampler = &SamplerConfig{
-			Type:  jaeger.SamplerTypeRemote,
-			Param: defaultSamplingProbability,
-		}
+	sampler, reporter, err := c.newSamplerAndReporter(opts, tracerMetrics)
+	if err != nil {
+		return nil, nil, err
 	}
-	if c.Reporter == nil {
-		c.Reporter = &ReporterConfig{}
+
+	tracerOptions := []jaeger.TracerOption{
+		jaeger.TracerOptions.Metrics(tracerMetrics),
+		jaeger.TracerOptions.Logger(opts.logger),
+		jaeger.TracerOptions.CustomHeaderKeys(c.Headers),
+		jaeger.TracerOptions.PoolSpans(c.PoolSpans || opts.poolSpans),
+		jaeger.TracerOptions.PprofLabels(c.PprofLabels || opts.pprofLabels),
+		jaeger.TracerOptions.RecordPanics(c.RecordPanics || opts.recordPanics),
+		jaeger.TracerOptions.ZipkinSharedRPCSpan(c.ZipkinSharedRPCSpan || opts.zipkinSharedRPCSpan),
+		jaeger.TracerOptions.NoDebugFlagOnForcedSampling(c.NoDebugFlagOnForcedSampling || opts.noDebugFlagOnForcedSampling),
 	}
 
-	sampler := opts.sampler
-	if sampler == nil {
-		s, err := c.Sampler.NewSampler(c.ServiceName, tracerMetrics)
-		if err != nil {
-			return nil, nil, err
-		}
-		sampler = s
+	maxTagValueLength := c.MaxTagValueLength
+	if opts.maxTagValueLength != 0 {
+		maxTagValueLength = opts.maxTagValueLength
 	}
+	tracerOptions = append(tracerOptions, jaeger.TracerOptions.MaxTagValueLength(maxTagValueLength))
 
-	reporter := opts.reporter
-	if reporter == nil {
-		r, err := c.Reporter.NewReporter(c.ServiceName, tracerMetrics, opts.logger)
-		if err != nil {
-			return nil, nil, err
-		}
-		reporter = r
+	maxLogsPerSpan := c.MaxLogsPerSpan
+	if opts.maxLogsPerSpan != 0 {
+		maxLogsPerSpan = opts.maxLogsPerSpan
+	}
+	if maxLogsPerSpan != 0 {
+		tracerOptions = append(tracerOptions, jaeger.TracerOptions.MaxLogsPerSpan(maxLogsPerSpan))
 	}
 
-	tracerOptions := []jaeger.TracerOption{
-		jaeger.TracerOptions.Metrics(tracerMetrics),
-		jaeger.TracerOptions.Logger(opts.logger),
-		jaeger.TracerOptions.CustomHeaderKeys(c.Headers),
-		jaeger.TracerOptions.PoolSpans(opts.poolSpans),
-		jaeger.TracerOptions.ZipkinSharedRPCSpan(opts.zipkinSharedRPCSpan),
-		jaeger.TracerOptions.MaxTagValueLength(opts.maxTagValueLength),
-		jaeger.TracerOptions.NoDebugFlagOnForcedSampling(opts.noDebugFlagOnForcedSampling),
+	maxTagsPerSpan := c.MaxTagsPerSpan
+	if opts.maxTagsPerSpan != 0 {
+		maxTagsPerSpan = opts.maxTagsPerSpan
+	}
+	if maxTagsPerSpan != 0 {
+		tracerOptions = append(tracerOptions, jaeger.TracerOptions.MaxTagsPerSpan(maxTagsPerSpan))
+	}
+
+	maxReferencesPerSpan := c.MaxReferencesPerSpan
+	if opts.maxReferencesPerSpan != 0 {
+		maxReferencesPerSpan = opts.maxReferencesPerSpan
+	}
+	if maxReferencesPerSpan != 0 {
+		tracerOptions = append(tracerOptions, jaeger.TracerOptions.MaxReferencesPerSpan(maxReferencesPerSpan))
+	}
+
+	maxLinksPerSpan := c.MaxLinksPerSpan
+	if opts.maxLinksPerSpan != 0 {
+		maxLinksPerSpan = opts.maxLinksPerSpan
+	}
+	if maxLinksPerSpan != 0 {
+		tracerOptions = append(tracerOptions, jaeger.TracerOptions.MaxLinksPerSpan(maxLinksPerSpan))
+	}
+
+	maxFieldsPerLog := c.MaxFieldsPerLog
+	if opts.maxFieldsPerLog != 0 {
+		maxFieldsPerLog = opts.maxFieldsPerLog
+	}
+	if maxFieldsPerLog != 0 {
+		tracerOptions = append(tracerOptions, jaeger.TracerOptions.MaxFieldsPerLog(maxFieldsPerLog))
+	}
+
+	maxSpanSize := c.MaxSpanSize
+	if opts.maxSpanSize != 0 {
+		maxSpanSize = opts.maxSpanSize
+	}
+	if maxSpanSize != 0 {
+		tracerOptions = append(tracerOptions, jaeger.TracerOptions.MaxSpanSize(maxSpanSize))
 	}
 
 	if c.Gen128Bit || opts.gen128Bit {
@@ -281,11 +495,7 @@ func (c Configuration) NewTracer(options ...Option) (opentracing.Tracer, io.Clos
 		tracerOptions = append(tracerOptions, jaeger.TracerOptions.RandomNumber(opts.randomNumber))
 	}
 
-	for _, tag := range opts.tags {
-		tracerOptions = append(tracerOptions, jaeger.TracerOptions.Tag(tag.Key, tag.Value))
-	}
-
-	for _, tag := range c.Tags {
+	for _, tag := range c.tracerTags(opts) {
 		tracerOptions = append(tracerOptions, jaeger.TracerOptions.Tag(tag.Key, tag.Value))
 	}
 
@@ -297,6 +507,8 @@ func (c Configuration) NewTracer(options ...Option) (opentracing.Tracer, io.Clos
 		tracerOptions = append(tracerOptions, jaeger.TracerOptions.ContribObserver(cobs))
 	}
 
+	tracerOptions = append(tracerOptions, c.propagatorOptions(tracerMetrics, opts.logger)...)
+
 	for format, injector := range opts.injectors {
 		tracerOptions = append(tracerOptions, jaeger.TracerOptions.Injector(format, injector))
 	}
@@ -344,6 +556,63 @@ func (c Configuration) NewTracer(options ...Option) (opentracing.Tracer, io.Clos
 	return tracer, closer, nil
 }
 
+// newSamplerAndReporter creates the sampler and the reporter of the tracer, unless
+// they are provided via options.
+func (c Configuration) newSamplerAndReporter(
+	opts Options,
+	tracerMetrics *jaeger.Metrics,
+) (jaeger.Sampler, jaeger.Reporter, error) {
+	if c.Sampler == nil {
+		c.Sampler = &SamplerConfig{
+			Type:  jaeger.SamplerTypeRemote,
+			Param: defaultSamplingProbability,
+		}
+	}
+	if c.Reporter == nil {
+		c.Reporter = &ReporterConfig{}
+	}
+
+	sampler := opts.sampler
+	if sampler == nil {
+		s, err := c.Sampler.NewSampler(c.ServiceName, tracerMetrics)
+		if err != nil {
+			return nil, nil, err
+		}
+		sampler = s
+	}
+
+	reporter := opts.reporter
+	if reporter == nil {
+		r, err := c.Reporter.newReporter(tracerMetrics, opts.metrics, opts.logger)
+		if err != nil {
+			if opts.sampler == nil {
+				sampler.Close()
+			}
+			return nil, nil, err
+		}
+		reporter = r
+	}
+	return sampler, reporter, nil
+}
+
+// tracerTags returns the tracer-level tags provided via options followed by c.Tags,
+// and by the detected resource tags with a key that is not already used.
+func (c Configuration) tracerTags(opts Options) []opentracing.Tag {
+	tags := make([]opentracing.Tag, 0, len(opts.tags)+len(c.Tags))
+	tags = append(tags, opts.tags...)
+	tags = append(tags, c.Tags...)
+	keys := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		keys[tag.Key] = true
+	}
+	for _, tag := range c.detectResourceTags(opts) {
+		if !keys[tag.Key] {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
 // InitGlobalTracer creates a new Jaeger Tracer, and sets it as global OpenTracing Tracer.
 // It returns a closer func that can be used to flush buffers before shutdown.
 func (c Configuration) InitGlobalTracer(
@@ -365,8 +634,33 @@ func (c Configuration) InitGlobalTracer(
 func (sc *SamplerConfig) NewSampler(
 	serviceName string,
 	metrics *jaeger.Metrics,
+) (jaeger.Sampler, error) {
+	return sc.newSampler("", serviceName, metrics)
+}
+
+// newSampler creates the sampler described by sc. The path identifies sc within
+// the sampler tree and is used to prefix errors of nested samplers.
+func (sc *SamplerConfig) newSampler(
+	path string,
+	serviceName string,
+	metrics *jaeger.Metrics,
+) (jaeger.Sampler, error) {
+	sampler, err := sc.newSamplerOfType(path, serviceName, metrics)
+	if err != nil && path != "" {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return sampler, err
+}
+
+func (sc *SamplerConfig) newSamplerOfType(
+	path string,
+	serviceName string,
+	metrics *jaeger.Metrics,
 ) (jaeger.Sampler, error) {
 	samplerType := strings.ToLower(sc.Type)
+	if err := sc.checkCompositeFields(samplerType); err != nil {
+		return nil, err
+	}
 	if samplerType == jaeger.SamplerTypeConst {
 		return jaeger.NewConstSampler(sc.Param != 0), nil
 	}
@@ -382,6 +676,15 @@ func (sc *SamplerConfig) NewSampler(
 	if samplerType == jaeger.SamplerTypeRateLimiting {
 		return jaeger.NewRateLimitingSampler(sc.Param), nil
 	}
+	if samplerType == samplerTypePriority {
+		return sc.newPrioritySampler(path, serviceName, metrics)
+	}
+	if samplerType == samplerTypeTagMatching {
+		return sc.newTagMatchingSampler()
+	}
+	if samplerType == samplerTypeRules {
+		return x.NewRulesSampler(sc.Rules)
+	}
 	if samplerType == jaeger.SamplerTypeRemote || sc.Type == "" {
 		sc2 := *sc
 		sc2.Type = jaeger.SamplerTypeProbabilistic
@@ -397,19 +700,120 @@ func (sc *SamplerConfig) NewSampler(
 			jaeger.SamplerOptions.OperationNameLateBinding(sc.OperationNameLateBinding),
 			jaeger.SamplerOptions.SamplingRefreshInterval(sc.SamplingRefreshInterval),
 		}
+		if sc.TLS != nil {
+			tlsConfig, err := sc.TLS.newTLSConfig(urlHostname(sc.SamplingServerURL))
+			if err != nil {
+				return nil, fmt.Errorf("cannot create TLS configuration for the sampling server: %v", err)
+			}
+			options = append(options, jaeger.SamplerOptions.SamplingTLSConfig(tlsConfig))
+		}
+		credentials, err := newHTTPCredentials(sc.Credentials, sc.AuthToken, sc.AuthTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create credentials for the sampling server: %v", err)
+		}
+		if credentials != nil {
+			options = append(options, jaeger.SamplerOptions.SamplingCredentials(credentials))
+		}
 		options = append(options, sc.Options...)
 		return jaeger.NewRemotelyControlledSampler(serviceName, options...), nil
 	}
 	return nil, fmt.Errorf("unknown sampler type (%s)", sc.Type)
 }
 
+// checkCompositeFields rejects fields that only apply to a sampler type other than
+// the configured one, which usually indicates a mistake in the sampler tree.
+func (sc *SamplerConfig) checkCompositeFields(samplerType string) error {
+	if len(sc.Delegates) > 0 && samplerType != samplerTypePriority {
+		return fmt.Errorf("delegates can only be used with the %q sampler", samplerTypePriority)
+	}
+	if (sc.TagKey != "" || len(sc.TagMatchers) > 0) && samplerType != samplerTypeTagMatching {
+		return errors.New("tagKey and tagMatchers can only be used with the \"tagMatching\" sampler")
+	}
+	if len(sc.Rules) > 0 && samplerType != samplerTypeRules {
+		return fmt.Errorf("rules can only be used with the %q sampler", samplerTypeRules)
+	}
+	return nil
+}
+
+func (sc *SamplerConfig) newPrioritySampler(
+	path string,
+	serviceName string,
+	metrics *jaeger.Metrics,
+) (jaeger.Sampler, error) {
+	if len(sc.Delegates) == 0 {
+		return nil, errors.New("priority sampler requires at least one delegate")
+	}
+	delegates := make([]jaeger.SamplerV2, 0, len(sc.Delegates))
+	closeDelegates := func() {
+		for _, d := range delegates {
+			d.Close()
+		}
+	}
+	for i := range sc.Delegates {
+		delegatePath := fmt.Sprintf("delegates[%d]", i)
+		if path != "" {
+			delegatePath = path + "." + delegatePath
+		}
+		sampler, err := sc.Delegates[i].newSampler(delegatePath, serviceName, metrics)
+		if err != nil {
+			closeDelegates()
+			return nil, err
+		}
+		samplerV2, ok := sampler.(jaeger.SamplerV2)
+		if !ok {
+			sampler.Close()
+			closeDelegates()
+			return nil, fmt.Errorf("%s: sampler %T does not implement jaeger.SamplerV2", delegatePath, sampler)
+		}
+		delegates = append(delegates, samplerV2)
+	}
+	return x.NewPrioritySampler(delegates...), nil
+}
+
+func (sc *SamplerConfig) newTagMatchingSampler() (jaeger.Sampler, error) {
+	if err := sc.checkTagMatchingFields(); err != nil {
+		return nil, err
+	}
+	return x.NewTagMatchingSampler(sc.TagKey, sc.TagMatchers), nil
+}
+
+func (sc *SamplerConfig) checkTagMatchingFields() error {
+	if sc.TagKey == "" {
+		return errors.New("tagMatching sampler requires tagKey")
+	}
+	if len(sc.TagMatchers) == 0 {
+		return errors.New("tagMatching sampler requires at least one tag matcher")
+	}
+	for i, m := range sc.TagMatchers {
+		switch m.TagValue.(type) {
+		case string, bool, int, int64, uint64, float64:
+		default:
+			return fmt.Errorf(
+				"tagMatchers[%d]: value must be a string, number or boolean, received %v",
+				i, m.TagValue,
+			)
+		}
+	}
+	return nil
+}
+
 // NewReporter instantiates a new reporter that submits spans to the collector
 func (rc *ReporterConfig) NewReporter(
 	serviceName string,
 	metrics *jaeger.Metrics,
 	logger jaeger.Logger,
 ) (jaeger.Reporter, error) {
-	sender, err := rc.newTransport(logger)
+	return rc.newReporter(metrics, nil, logger)
+}
+
+// newReporter creates the reporter, passing the metrics factory to the transport
+// for its own metrics, e.g. the per-endpoint metrics of the HTTPTransport.
+func (rc *ReporterConfig) newReporter(
+	tracerMetrics *jaeger.Metrics,
+	metricsFactory metrics.Factory,
+	logger jaeger.Logger,
+) (jaeger.Reporter, error) {
+	sender, err := rc.newTransport(logger, metricsFactory)
 	if err != nil {
 		return nil, err
 	}
@@ -418,7 +822,7 @@ func (rc *ReporterConfig) NewReporter(
 		jaeger.ReporterOptions.QueueSize(rc.QueueSize),
 		jaeger.ReporterOptions.BufferFlushInterval(rc.BufferFlushInterval),
 		jaeger.ReporterOptions.Logger(logger),
-		jaeger.ReporterOptions.Metrics(metrics))
+		jaeger.ReporterOptions.Metrics(tracerMetrics))
 	if rc.LogSpans && logger != nil {
 		logger.Infof("Initializing logging reporter\n")
 		reporter = jaeger.NewCompositeReporter(jaeger.NewLoggingReporter(logger), reporter)
@@ -426,14 +830,32 @@ func (rc *ReporterConfig) NewReporter(
 	return reporter, err
 }
 
-func (rc *ReporterConfig) newTransport(logger jaeger.Logger) (jaeger.Transport, error) {
+func (rc *ReporterConfig) newTransport(logger jaeger.Logger, metricsFactory metrics.Factory) (jaeger.Transport, error) {
 	switch {
 	case rc.CollectorEndpoint != "":
-		httpOptions := []transport.HTTPOption{transport.HTTPHeaders(rc.HTTPHeaders)}
-		if rc.User != "" && rc.Password != "" {
-			httpOptions = append(httpOptions, transport.HTTPBasicAuth(rc.User, rc.Password))
+		endpoints := make([]*url.URL, 0, 1+len(rc.CollectorEndpoints))
+		for _, rawURL := range append([]string{rc.CollectorEndpoint}, rc.CollectorEndpoints...) {
+			endpoint, err := url.Parse(rawURL)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse collector endpoint %q: %v", rawURL, err)
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+		factory, ok := transportFactory(endpoints[0].Scheme)
+		if !ok {
+			return nil, fmt.Errorf("no transport registered for the scheme of collector endpoint %q", rc.CollectorEndpoint)
 		}
-		return transport.NewHTTPTransport(rc.CollectorEndpoint, httpOptions...), nil
+		if metricsFactory == nil {
+			metricsFactory = metrics.NullFactory
+		}
+		return factory(TransportParams{
+			Endpoint:  endpoints[0],
+			Endpoints: endpoints,
+			Options:   rc.TransportOptions,
+			Reporter:  rc,
+			Logger:    logger,
+			Metrics:   metricsFactory,
+		})
 	default:
 		return jaeger.NewUDPTransportWithParams(jaeger.UDPTransportParams{
 			AgentClientUDPParams: utils.AgentClientUDPParams{
@@ -445,3 +867,22 @@ func (rc *ReporterConfig) newTransport(logger jaeger.Logger) (jaeger.Transport,
 		})
 	}
 }
+
+// newHTTPCredentials returns the credentials configured either programmatically or by
+// a bearer token or a token file, or nil if none is configured.
+func newHTTPCredentials(
+	credentials jaeger.HTTPCredentials,
+	authToken string,
+	authTokenFile string,
+) (jaeger.HTTPCredentials, error) {
+	switch {
+	case credentials != nil:
+		return credentials, nil
+	case authTokenFile != "":
+		return jaeger.NewTokenFileCredentials(authTokenFile)
+	case authToken != "":
+		return jaeger.NewStaticTokenCredentials(authToken), nil
+	default:
+		return nil, nil
+	}
+}
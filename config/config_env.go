@@ -25,6 +25,7 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"github.com/uber/jaeger-client-go"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -33,35 +34,114 @@ const (
 	envDisabled                            = "JAEGER_DISABLED"
 	envRPCMetrics                          = "JAEGER_RPC_METRICS"
 	envTags                                = "JAEGER_TAGS"
+	envResourceDetectors                   = "JAEGER_RESOURCE_DETECTORS"
 	envSamplerType                         = "JAEGER_SAMPLER_TYPE"
 	envSamplerParam                        = "JAEGER_SAMPLER_PARAM"
 	envSamplerManagerHostPort              = "JAEGER_SAMPLER_MANAGER_HOST_PORT" // Deprecated by envSamplingEndpoint
 	envSamplingEndpoint                    = "JAEGER_SAMPLING_ENDPOINT"
 	envSamplerMaxOperations                = "JAEGER_SAMPLER_MAX_OPERATIONS"
 	envSamplerRefreshInterval              = "JAEGER_SAMPLER_REFRESH_INTERVAL"
+	envSamplerDelegates                    = "JAEGER_SAMPLER_DELEGATES"
+	envSamplerAuthToken                    = "JAEGER_SAMPLER_AUTH_TOKEN"
+	envSamplerAuthTokenFile                = "JAEGER_SAMPLER_AUTH_TOKEN_FILE"
 	envReporterMaxQueueSize                = "JAEGER_REPORTER_MAX_QUEUE_SIZE"
 	envReporterFlushInterval               = "JAEGER_REPORTER_FLUSH_INTERVAL"
 	envReporterLogSpans                    = "JAEGER_REPORTER_LOG_SPANS"
 	envReporterAttemptReconnectingDisabled = "JAEGER_REPORTER_ATTEMPT_RECONNECTING_DISABLED"
 	envReporterAttemptReconnectInterval    = "JAEGER_REPORTER_ATTEMPT_RECONNECT_INTERVAL"
 	envEndpoint                            = "JAEGER_ENDPOINT"
+	envEndpoints                           = "JAEGER_ENDPOINTS"
+	envEndpointSRV                         = "JAEGER_ENDPOINT_SRV"
 	envUser                                = "JAEGER_USER"
 	envPassword                            = "JAEGER_PASSWORD"
+	envAuthToken                           = "JAEGER_AUTH_TOKEN"
+	envAuthTokenFile                       = "JAEGER_AUTH_TOKEN_FILE"
 	envAgentHost                           = "JAEGER_AGENT_HOST"
 	envAgentPort                           = "JAEGER_AGENT_PORT"
 	env128bit                              = "JAEGER_TRACEID_128BIT"
+	envReporterHTTPHeaders                 = "JAEGER_REPORTER_HTTP_HEADERS"
+	envReporterTransportOptions            = "JAEGER_REPORTER_TRANSPORT_OPTIONS"
+	envMaxTagValueLength                   = "JAEGER_MAX_TAG_VALUE_LENGTH"
+	envMaxLogsPerSpan                      = "JAEGER_MAX_LOGS_PER_SPAN"
+	envMaxTagsPerSpan                      = "JAEGER_MAX_TAGS_PER_SPAN"
+	envMaxReferencesPerSpan                = "JAEGER_MAX_REFERENCES_PER_SPAN"
+	envMaxLinksPerSpan                     = "JAEGER_MAX_LINKS_PER_SPAN"
+	envMaxFieldsPerLog                     = "JAEGER_MAX_FIELDS_PER_LOG"
+	envMaxSpanSize                         = "JAEGER_MAX_SPAN_SIZE"
+	envPoolSpans                           = "JAEGER_POOL_SPANS"
+	envPprofLabels                         = "JAEGER_PPROF_LABELS"
+	envRecordPanics                        = "JAEGER_RECORD_PANICS"
+	envZipkinSharedRPCSpan                 = "JAEGER_ZIPKIN_SHARED_RPC_SPAN"
+	envNoDebugFlagOnForcedSampling         = "JAEGER_NO_DEBUG_FLAG_ON_FORCED_SAMPLING"
+	envHeadersDebug                        = "JAEGER_HEADERS_DEBUG"
+	envHeadersBaggage                      = "JAEGER_HEADERS_BAGGAGE"
+	envHeadersTraceContext                 = "JAEGER_HEADERS_TRACE_CONTEXT"
+	envHeadersBaggagePrefix                = "JAEGER_HEADERS_BAGGAGE_PREFIX"
+	envBaggageRestrictionsDenyOnInitFail   = "JAEGER_BAGGAGE_RESTRICTIONS_DENY_ON_INIT_FAILURE"
+	envBaggageRestrictionsHostPort         = "JAEGER_BAGGAGE_RESTRICTIONS_HOST_PORT"
+	envBaggageRestrictionsRefreshInterval  = "JAEGER_BAGGAGE_RESTRICTIONS_REFRESH_INTERVAL"
+	envThrottlerHostPort                   = "JAEGER_THROTTLER_HOST_PORT"
+	envThrottlerRefreshInterval            = "JAEGER_THROTTLER_REFRESH_INTERVAL"
+	envThrottlerSynchronousInitialization  = "JAEGER_THROTTLER_SYNCHRONOUS_INITIALIZATION"
+	envReporterTLSCA                       = "JAEGER_REPORTER_TLS_CA"
+	envReporterTLSCert                     = "JAEGER_REPORTER_TLS_CERT"
+	envReporterTLSKey                      = "JAEGER_REPORTER_TLS_KEY"
+	envReporterTLSServerName               = "JAEGER_REPORTER_TLS_SERVER_NAME"
+	envReporterTLSMinVersion               = "JAEGER_REPORTER_TLS_MIN_VERSION"
+	envReporterTLSSkipHostVerify           = "JAEGER_REPORTER_TLS_SKIP_HOST_VERIFY"
+	envSamplerTLSCA                        = "JAEGER_SAMPLER_TLS_CA"
+	envSamplerTLSCert                      = "JAEGER_SAMPLER_TLS_CERT"
+	envSamplerTLSKey                       = "JAEGER_SAMPLER_TLS_KEY"
+	envSamplerTLSServerName                = "JAEGER_SAMPLER_TLS_SERVER_NAME"
+	envSamplerTLSMinVersion                = "JAEGER_SAMPLER_TLS_MIN_VERSION"
+	envSamplerTLSSkipHostVerify            = "JAEGER_SAMPLER_TLS_SKIP_HOST_VERIFY"
+
+	// OpenTelemetry environment variables, see
+	// https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/sdk-environment-variables.md
+	envOTELServiceName                = "OTEL_SERVICE_NAME"
+	envOTELResourceAttributes         = "OTEL_RESOURCE_ATTRIBUTES"
+	envOTELTracesSampler              = "OTEL_TRACES_SAMPLER"
+	envOTELTracesSamplerArg           = "OTEL_TRACES_SAMPLER_ARG"
+	envOTELPropagators                = "OTEL_PROPAGATORS"
+	envOTELExporterOTLPEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	envOTELExporterOTLPTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
 )
 
+// otlpSchemePrefix marks collector endpoints that expect the OTLP protocol,
+// e.g. "otlp+http://otel-collector:4318/v1/traces".
+const otlpSchemePrefix = "otlp+"
+
+// resourceAttributeServiceName is the OpenTelemetry resource attribute holding the service name.
+const resourceAttributeServiceName = "service.name"
+
 // FromEnv uses environment variables to set the tracer's Configuration
 func FromEnv() (*Configuration, error) {
 	c := &Configuration{}
 	return c.FromEnv()
 }
 
-// FromEnv uses environment variables and overrides existing tracer's Configuration
+// FromEnv uses environment variables and overrides existing tracer's Configuration.
+//
+// Besides the JAEGER_* variables, the following OpenTelemetry variables are supported:
+// OTEL_SERVICE_NAME, OTEL_RESOURCE_ATTRIBUTES, OTEL_TRACES_SAMPLER, OTEL_TRACES_SAMPLER_ARG,
+// OTEL_PROPAGATORS, OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_TRACES_ENDPOINT.
+// When a setting is provided by both, the JAEGER_* variable takes precedence.
 func (c *Configuration) FromEnv() (*Configuration, error) {
+	var resourceTags []opentracing.Tag
+	if e := os.Getenv(envOTELResourceAttributes); e != "" {
+		if value, err := parseResourceAttributes(e); err == nil {
+			resourceTags = value
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envOTELResourceAttributes, e)
+		}
+	}
+
 	if e := os.Getenv(envServiceName); e != "" {
 		c.ServiceName = e
+	} else if e := os.Getenv(envOTELServiceName); e != "" {
+		c.ServiceName = e
+	} else if name, ok := resourceServiceName(resourceTags); ok {
+		c.ServiceName = name
 	}
 
 	if e := os.Getenv(envRPCMetrics); e != "" {
@@ -81,7 +161,27 @@ func (c *Configuration) FromEnv() (*Configuration, error) {
 	}
 
 	if e := os.Getenv(envTags); e != "" {
-		c.Tags = parseTags(e)
+		c.Tags = mergeTags(withoutServiceName(resourceTags), parseTags(e))
+	} else if tags := withoutServiceName(resourceTags); len(tags) > 0 {
+		c.Tags = tags
+	}
+
+	if e := os.Getenv(envResourceDetectors); e != "" {
+		c.ResourceDetectors = nil
+		for _, name := range strings.Split(e, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				c.ResourceDetectors = append(c.ResourceDetectors, name)
+			}
+		}
+	}
+
+	if e := os.Getenv(envOTELPropagators); e != "" {
+		c.Propagators = nil
+		for _, name := range strings.Split(e, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				c.Propagators = append(c.Propagators, name)
+			}
+		}
 	}
 
 	if e := os.Getenv(env128bit); e != "" {
@@ -92,6 +192,127 @@ func (c *Configuration) FromEnv() (*Configuration, error) {
 		}
 	}
 
+	if e := os.Getenv(envMaxTagValueLength); e != "" {
+		if value, err := strconv.ParseInt(e, 10, 0); err == nil {
+			c.MaxTagValueLength = int(value)
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envMaxTagValueLength, e)
+		}
+	}
+
+	if e := os.Getenv(envMaxLogsPerSpan); e != "" {
+		if value, err := strconv.ParseInt(e, 10, 0); err == nil {
+			c.MaxLogsPerSpan = int(value)
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envMaxLogsPerSpan, e)
+		}
+	}
+
+	if e := os.Getenv(envMaxTagsPerSpan); e != "" {
+		if value, err := strconv.ParseInt(e, 10, 0); err == nil {
+			c.MaxTagsPerSpan = int(value)
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envMaxTagsPerSpan, e)
+		}
+	}
+
+	if e := os.Getenv(envMaxReferencesPerSpan); e != "" {
+		if value, err := strconv.ParseInt(e, 10, 0); err == nil {
+			c.MaxReferencesPerSpan = int(value)
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envMaxReferencesPerSpan, e)
+		}
+	}
+
+	if e := os.Getenv(envMaxLinksPerSpan); e != "" {
+		if value, err := strconv.ParseInt(e, 10, 0); err == nil {
+			c.MaxLinksPerSpan = int(value)
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envMaxLinksPerSpan, e)
+		}
+	}
+
+	if e := os.Getenv(envMaxFieldsPerLog); e != "" {
+		if value, err := strconv.ParseInt(e, 10, 0); err == nil {
+			c.MaxFieldsPerLog = int(value)
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envMaxFieldsPerLog, e)
+		}
+	}
+
+	if e := os.Getenv(envMaxSpanSize); e != "" {
+		if value, err := strconv.ParseInt(e, 10, 0); err == nil {
+			c.MaxSpanSize = int(value)
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envMaxSpanSize, e)
+		}
+	}
+
+	if e := os.Getenv(envPoolSpans); e != "" {
+		if value, err := strconv.ParseBool(e); err == nil {
+			c.PoolSpans = value
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envPoolSpans, e)
+		}
+	}
+
+	if e := os.Getenv(envPprofLabels); e != "" {
+		if value, err := strconv.ParseBool(e); err == nil {
+			c.PprofLabels = value
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envPprofLabels, e)
+		}
+	}
+
+	if e := os.Getenv(envRecordPanics); e != "" {
+		if value, err := strconv.ParseBool(e); err == nil {
+			c.RecordPanics = value
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envRecordPanics, e)
+		}
+	}
+
+	if e := os.Getenv(envZipkinSharedRPCSpan); e != "" {
+		if value, err := strconv.ParseBool(e); err == nil {
+			c.ZipkinSharedRPCSpan = value
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envZipkinSharedRPCSpan, e)
+		}
+	}
+
+	if e := os.Getenv(envNoDebugFlagOnForcedSampling); e != "" {
+		if value, err := strconv.ParseBool(e); err == nil {
+			c.NoDebugFlagOnForcedSampling = value
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envNoDebugFlagOnForcedSampling, e)
+		}
+	}
+
+	if anyEnvSet(envHeadersDebug, envHeadersBaggage, envHeadersTraceContext, envHeadersBaggagePrefix) {
+		if c.Headers == nil {
+			c.Headers = &jaeger.HeadersConfig{}
+		}
+		headersConfigFromEnv(c.Headers)
+	}
+
+	if anyEnvSet(envBaggageRestrictionsDenyOnInitFail, envBaggageRestrictionsHostPort, envBaggageRestrictionsRefreshInterval) {
+		if c.BaggageRestrictions == nil {
+			c.BaggageRestrictions = &BaggageRestrictionsConfig{}
+		}
+		if err := c.BaggageRestrictions.baggageRestrictionsConfigFromEnv(); err != nil {
+			return nil, errors.Wrap(err, "cannot obtain baggage restrictions config from env")
+		}
+	}
+
+	if anyEnvSet(envThrottlerHostPort, envThrottlerRefreshInterval, envThrottlerSynchronousInitialization) {
+		if c.Throttler == nil {
+			c.Throttler = &ThrottlerConfig{}
+		}
+		if err := c.Throttler.throttlerConfigFromEnv(); err != nil {
+			return nil, errors.Wrap(err, "cannot obtain throttler config from env")
+		}
+	}
+
 	if c.Sampler == nil {
 		c.Sampler = &SamplerConfig{}
 	}
@@ -115,8 +336,216 @@ func (c *Configuration) FromEnv() (*Configuration, error) {
 	return c, nil
 }
 
+// anyEnvSet returns true if any of the given environment variables is not empty.
+func anyEnvSet(names ...string) bool {
+	for _, name := range names {
+		if os.Getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// headersConfigFromEnv overrides the header names in hc based on the environment variables
+func headersConfigFromEnv(hc *jaeger.HeadersConfig) {
+	if e := os.Getenv(envHeadersDebug); e != "" {
+		hc.JaegerDebugHeader = e
+	}
+	if e := os.Getenv(envHeadersBaggage); e != "" {
+		hc.JaegerBaggageHeader = e
+	}
+	if e := os.Getenv(envHeadersTraceContext); e != "" {
+		hc.TraceContextHeaderName = e
+	}
+	if e := os.Getenv(envHeadersBaggagePrefix); e != "" {
+		hc.TraceBaggageHeaderPrefix = e
+	}
+}
+
+// baggageRestrictionsConfigFromEnv overrides bc based on the environment variables
+func (bc *BaggageRestrictionsConfig) baggageRestrictionsConfigFromEnv() error {
+	if e := os.Getenv(envBaggageRestrictionsDenyOnInitFail); e != "" {
+		if value, err := strconv.ParseBool(e); err == nil {
+			bc.DenyBaggageOnInitializationFailure = value
+		} else {
+			return errors.Wrapf(err, "cannot parse env var %s=%s", envBaggageRestrictionsDenyOnInitFail, e)
+		}
+	}
+
+	if e := os.Getenv(envBaggageRestrictionsHostPort); e != "" {
+		bc.HostPort = e
+	}
+
+	if e := os.Getenv(envBaggageRestrictionsRefreshInterval); e != "" {
+		if value, err := time.ParseDuration(e); err == nil {
+			bc.RefreshInterval = value
+		} else {
+			return errors.Wrapf(err, "cannot parse env var %s=%s", envBaggageRestrictionsRefreshInterval, e)
+		}
+	}
+	return nil
+}
+
+// throttlerConfigFromEnv overrides tc based on the environment variables
+func (tc *ThrottlerConfig) throttlerConfigFromEnv() error {
+	if e := os.Getenv(envThrottlerHostPort); e != "" {
+		tc.HostPort = e
+	}
+
+	if e := os.Getenv(envThrottlerRefreshInterval); e != "" {
+		if value, err := time.ParseDuration(e); err == nil {
+			tc.RefreshInterval = value
+		} else {
+			return errors.Wrapf(err, "cannot parse env var %s=%s", envThrottlerRefreshInterval, e)
+		}
+	}
+
+	if e := os.Getenv(envThrottlerSynchronousInitialization); e != "" {
+		if value, err := strconv.ParseBool(e); err == nil {
+			tc.SynchronousInitialization = value
+		} else {
+			return errors.Wrapf(err, "cannot parse env var %s=%s", envThrottlerSynchronousInitialization, e)
+		}
+	}
+	return nil
+}
+
+// tlsEnvVars lists the environment variables that configure the fields of a TLSConfig.
+type tlsEnvVars struct {
+	ca, cert, key, serverName, minVersion, skipHostVerify string
+}
+
+var (
+	reporterTLSEnvVars = tlsEnvVars{
+		ca:             envReporterTLSCA,
+		cert:           envReporterTLSCert,
+		key:            envReporterTLSKey,
+		serverName:     envReporterTLSServerName,
+		minVersion:     envReporterTLSMinVersion,
+		skipHostVerify: envReporterTLSSkipHostVerify,
+	}
+	samplerTLSEnvVars = tlsEnvVars{
+		ca:             envSamplerTLSCA,
+		cert:           envSamplerTLSCert,
+		key:            envSamplerTLSKey,
+		serverName:     envSamplerTLSServerName,
+		minVersion:     envSamplerTLSMinVersion,
+		skipHostVerify: envSamplerTLSSkipHostVerify,
+	}
+)
+
+// tlsConfigFromEnv overrides the TLS configuration based on the environment variables,
+// creating it if any of the variables is set.
+func tlsConfigFromEnv(tc *TLSConfig, vars tlsEnvVars) (*TLSConfig, error) {
+	if !anyEnvSet(vars.ca, vars.cert, vars.key, vars.serverName, vars.minVersion, vars.skipHostVerify) {
+		return tc, nil
+	}
+	if tc == nil {
+		tc = &TLSConfig{}
+	}
+	if e := os.Getenv(vars.ca); e != "" {
+		tc.CAFile = e
+	}
+	if e := os.Getenv(vars.cert); e != "" {
+		tc.CertFile = e
+	}
+	if e := os.Getenv(vars.key); e != "" {
+		tc.KeyFile = e
+	}
+	if e := os.Getenv(vars.serverName); e != "" {
+		tc.ServerName = e
+	}
+	if e := os.Getenv(vars.minVersion); e != "" {
+		tc.MinVersion = e
+	}
+	if e := os.Getenv(vars.skipHostVerify); e != "" {
+		if value, err := strconv.ParseBool(e); err == nil {
+			tc.InsecureSkipVerify = value
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", vars.skipHostVerify, e)
+		}
+	}
+	return tc, nil
+}
+
+// samplerConfigFromOTELEnv maps OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG onto sc.
+// The parentbased_* samplers are equivalent to their base samplers, because Jaeger samplers
+// only make decisions for root spans and child spans always respect the parent's decision.
+func (sc *SamplerConfig) samplerConfigFromOTELEnv() error {
+	e := os.Getenv(envOTELTracesSampler)
+	if e == "" {
+		return nil
+	}
+	arg := os.Getenv(envOTELTracesSamplerArg)
+
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(e)), "parentbased_") {
+	case "always_on":
+		sc.Type = jaeger.SamplerTypeConst
+		sc.Param = 1
+	case "always_off":
+		sc.Type = jaeger.SamplerTypeConst
+		sc.Param = 0
+	case "traceidratio":
+		sc.Type = jaeger.SamplerTypeProbabilistic
+		sc.Param = 1
+		if arg != "" {
+			value, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return errors.Wrapf(err, "cannot parse env var %s=%s", envOTELTracesSamplerArg, arg)
+			}
+			sc.Param = value
+		}
+	case "jaeger_remote":
+		sc.Type = jaeger.SamplerTypeRemote
+		if arg != "" {
+			if err := sc.parseJaegerRemoteSamplerArg(arg); err != nil {
+				return errors.Wrapf(err, "cannot parse env var %s=%s", envOTELTracesSamplerArg, arg)
+			}
+		}
+	default:
+		return errors.Errorf("cannot parse env var %s=%s: unsupported sampler", envOTELTracesSampler, e)
+	}
+	return nil
+}
+
+// parseJaegerRemoteSamplerArg parses the argument of the jaeger_remote sampler,
+// e.g. "endpoint=http://localhost:5778/sampling,pollingIntervalMs=5000,initialSamplingRate=0.25".
+func (sc *SamplerConfig) parseJaegerRemoteSamplerArg(arg string) error {
+	for _, pair := range strings.Split(arg, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return errors.Errorf("expecting key=value, received %q", pair)
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		switch key {
+		case "endpoint":
+			sc.SamplingServerURL = value
+		case "pollingIntervalMs":
+			ms, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return err
+			}
+			sc.SamplingRefreshInterval = time.Duration(ms) * time.Millisecond
+		case "initialSamplingRate":
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return err
+			}
+			sc.Param = rate
+		default:
+			return errors.Errorf("unknown key %q", key)
+		}
+	}
+	return nil
+}
+
 // samplerConfigFromEnv creates a new SamplerConfig based on the environment variables
 func (sc *SamplerConfig) samplerConfigFromEnv() (*SamplerConfig, error) {
+	// OpenTelemetry variables are applied first so that JAEGER_* variables take precedence
+	if err := sc.samplerConfigFromOTELEnv(); err != nil {
+		return nil, err
+	}
+
 	if e := os.Getenv(envSamplerType); e != "" {
 		sc.Type = e
 	}
@@ -154,6 +583,30 @@ func (sc *SamplerConfig) samplerConfigFromEnv() (*SamplerConfig, error) {
 		}
 	}
 
+	if e := os.Getenv(envSamplerDelegates); e != "" {
+		// YAML is a superset of JSON, so both formats are accepted
+		var delegates []SamplerConfig
+		if err := yaml.Unmarshal([]byte(e), &delegates); err == nil {
+			sc.Delegates = delegates
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envSamplerDelegates, e)
+		}
+	}
+
+	if e := os.Getenv(envSamplerAuthToken); e != "" {
+		sc.AuthToken = e
+	}
+
+	if e := os.Getenv(envSamplerAuthTokenFile); e != "" {
+		sc.AuthTokenFile = e
+	}
+
+	if tc, err := tlsConfigFromEnv(sc.TLS, samplerTLSEnvVars); err == nil {
+		sc.TLS = tc
+	} else {
+		return nil, err
+	}
+
 	return sc, nil
 }
 
@@ -183,12 +636,56 @@ func (rc *ReporterConfig) reporterConfigFromEnv() (*ReporterConfig, error) {
 		}
 	}
 
-	if e := os.Getenv(envEndpoint); e != "" {
-		u, err := url.ParseRequestURI(e)
+	if e := os.Getenv(envReporterHTTPHeaders); e != "" {
+		if value, err := parseNameValuePairs(e); err == nil {
+			rc.HTTPHeaders = value
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envReporterHTTPHeaders, e)
+		}
+	}
+
+	if e := os.Getenv(envReporterTransportOptions); e != "" {
+		if value, err := parseNameValuePairs(e); err == nil {
+			rc.TransportOptions = make(map[string]interface{}, len(value))
+			for k, v := range value {
+				rc.TransportOptions[k] = v
+			}
+		} else {
+			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envReporterTransportOptions, e)
+		}
+	}
+
+	// the OTLP endpoint is only considered when no Jaeger reporter address is provided
+	var otlpEndpoint string
+	if !anyEnvSet(envEndpoint, envEndpoints, envAgentHost, envAgentPort) {
+		endpoint, err := rc.otlpEndpointFromEnv()
 		if err != nil {
-			return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envEndpoint, e)
+			return nil, err
 		}
-		rc.CollectorEndpoint = u.String()
+		otlpEndpoint = endpoint
+	}
+
+	if anyEnvSet(envEndpoint, envEndpoints) {
+		var endpoints []string
+		if e := os.Getenv(envEndpoint); e != "" {
+			u, err := url.ParseRequestURI(e)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envEndpoint, e)
+			}
+			endpoints = append(endpoints, u.String())
+		}
+		if e := os.Getenv(envEndpoints); e != "" {
+			for _, endpoint := range strings.Split(e, ",") {
+				u, err := url.ParseRequestURI(strings.TrimSpace(endpoint))
+				if err != nil {
+					return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envEndpoints, e)
+				}
+				endpoints = append(endpoints, u.String())
+			}
+		}
+		rc.CollectorEndpoint = endpoints[0]
+		rc.CollectorEndpoints = endpoints[1:]
+		rc.LocalAgentHostPort = ""
 		user := os.Getenv(envUser)
 		pswd := os.Getenv(envPassword)
 		if user != "" && pswd == "" || user == "" && pswd != "" {
@@ -196,6 +693,10 @@ func (rc *ReporterConfig) reporterConfigFromEnv() (*ReporterConfig, error) {
 		}
 		rc.User = user
 		rc.Password = pswd
+	} else if otlpEndpoint != "" {
+		rc.CollectorEndpoint = otlpEndpoint
+		rc.CollectorEndpoints = nil
+		rc.LocalAgentHostPort = ""
 	} else {
 		useEnv := false
 		host := jaeger.DefaultUDPSpanServerHost
@@ -213,7 +714,12 @@ func (rc *ReporterConfig) reporterConfigFromEnv() (*ReporterConfig, error) {
 				return nil, errors.Wrapf(err, "cannot parse env var %s=%s", envAgentPort, e)
 			}
 		}
-		if useEnv || rc.LocalAgentHostPort == "" {
+		if useEnv {
+			rc.LocalAgentHostPort = fmt.Sprintf("%s:%d", host, port)
+			rc.CollectorEndpoint = ""
+			rc.CollectorEndpoints = nil
+		} else if rc.LocalAgentHostPort == "" && rc.CollectorEndpoint == "" {
+			// the default agent address is not needed when the collector endpoint is configured
 			rc.LocalAgentHostPort = fmt.Sprintf("%s:%d", host, port)
 		}
 
@@ -236,9 +742,127 @@ func (rc *ReporterConfig) reporterConfigFromEnv() (*ReporterConfig, error) {
 		}
 	}
 
+	if e := os.Getenv(envEndpointSRV); e != "" {
+		rc.CollectorSRV = e
+	}
+
+	if e := os.Getenv(envAuthToken); e != "" {
+		rc.AuthToken = e
+	}
+
+	if e := os.Getenv(envAuthTokenFile); e != "" {
+		rc.AuthTokenFile = e
+	}
+
+	if tc, err := tlsConfigFromEnv(rc.TLS, reporterTLSEnvVars); err == nil {
+		rc.TLS = tc
+	} else {
+		return nil, err
+	}
+
 	return rc, nil
 }
 
+// otlpEndpointFromEnv returns the OTLP traces endpoint provided via the OpenTelemetry
+// environment variables, prefixed with otlpSchemePrefix, or an empty string. The endpoint
+// is ignored if no transport is registered for its scheme, rather than failing NewTracer,
+// so that the spans keep being sent to the agent. The ignored endpoint is recorded in
+// envWarnings, and logged by NewTracer.
+func (rc *ReporterConfig) otlpEndpointFromEnv() (string, error) {
+	envVar, e := envOTELExporterOTLPTracesEndpoint, os.Getenv(envOTELExporterOTLPTracesEndpoint)
+	endpoint := e
+	if e == "" {
+		envVar, e = envOTELExporterOTLPEndpoint, os.Getenv(envOTELExporterOTLPEndpoint)
+		if e == "" {
+			return "", nil
+		}
+		// the generic endpoint is the base URL for all signals
+		endpoint = strings.TrimSuffix(e, "/") + "/v1/traces"
+	}
+	u, err := url.ParseRequestURI(endpoint)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot parse env var %s=%s", envVar, e)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", errors.Errorf("cannot parse env var %s=%s: expecting an http or https URL", envVar, e)
+	}
+	scheme := otlpSchemePrefix + u.Scheme
+	if _, ok := transportFactory(scheme); !ok {
+		rc.envWarnings = append(rc.envWarnings, fmt.Sprintf(
+			"ignoring env var %s=%s: no transport registered for scheme %q", envVar, e, scheme))
+		return "", nil
+	}
+	return otlpSchemePrefix + u.String(), nil
+}
+
+// parseNameValuePairs parses a comma separated list of name=value pairs.
+func parseNameValuePairs(pairs string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(pairs, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, errors.Errorf("expecting name=value, received %q", pair)
+		}
+		result[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return result, nil
+}
+
+// parseResourceAttributes parses OTEL_RESOURCE_ATTRIBUTES, a comma separated list
+// of key=value pairs with percent-encoded values.
+func parseResourceAttributes(attributes string) ([]opentracing.Tag, error) {
+	var tags []opentracing.Tag
+	for _, pair := range strings.Split(attributes, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, errors.Errorf("expecting key=value, received %q", pair)
+		}
+		value, err := url.PathUnescape(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, opentracing.Tag{Key: strings.TrimSpace(kv[0]), Value: value})
+	}
+	return tags, nil
+}
+
+func resourceServiceName(tags []opentracing.Tag) (string, bool) {
+	for _, tag := range tags {
+		if tag.Key == resourceAttributeServiceName {
+			return tag.Value.(string), true
+		}
+	}
+	return "", false
+}
+
+func withoutServiceName(tags []opentracing.Tag) []opentracing.Tag {
+	var result []opentracing.Tag
+	for _, tag := range tags {
+		if tag.Key != resourceAttributeServiceName {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// mergeTags appends overrides to tags, replacing the tags with the same keys.
+func mergeTags(tags []opentracing.Tag, overrides []opentracing.Tag) []opentracing.Tag {
+	overridden := make(map[string]struct{}, len(overrides))
+	for _, tag := range overrides {
+		overridden[tag.Key] = struct{}{}
+	}
+	result := make([]opentracing.Tag, 0, len(tags)+len(overrides))
+	for _, tag := range tags {
+		if _, ok := overridden[tag.Key]; !ok {
+			result = append(result, tag)
+		}
+	}
+	return append(result, overrides...)
+}
+
 // parseTags parses the given string into a collection of Tags.
 // Spec for this value:
 // - comma separated list of key=value
@@ -0,0 +1,83 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadOption is a function that sets some option on FromFile and FromReader.
+type LoadOption func(o *loadOptions)
+
+type loadOptions struct {
+	strict bool
+}
+
+// Strict creates a LoadOption that makes loading fail if the document contains
+// keys that do not correspond to any field of the Configuration, which helps to
+// catch misspelled keys that would otherwise be silently ignored.
+func Strict() LoadOption {
+	return func(o *loadOptions) {
+		o.strict = true
+	}
+}
+
+// FromFile loads the Configuration from a YAML or JSON file and then overrides it
+// with the environment variables, as described in FromEnv().
+//
+// The resulting precedence is defaults < file < environment < explicit Options
+// passed to NewTracer(). Field names are the ones declared in the yaml struct tags
+// of Configuration, and durations are written as strings with units, e.g.
+//
+//     serviceName: my-service
+//     sampler:
+//       type: remote
+//       samplingRefreshInterval: 30s
+//     reporter:
+//       bufferFlushInterval: 1s
+func FromFile(path string, opts ...LoadOption) (*Configuration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open config file %s", path)
+	}
+	defer f.Close()
+
+	c, err := FromReader(f, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load config file %s", path)
+	}
+	return c, nil
+}
+
+// FromReader is like FromFile but reads the YAML or JSON document from r.
+func FromReader(r io.Reader, opts ...LoadOption) (*Configuration, error) {
+	options := &loadOptions{}
+	for _, option := range opts {
+		option(options)
+	}
+
+	c := &Configuration{}
+	// YAML is a superset of JSON, so both formats are handled by the YAML decoder
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(options.strict)
+	if err := decoder.Decode(c); err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "cannot parse configuration")
+	}
+	return c.FromEnv()
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const yamlConfig = `
+serviceName: from-file
+traceid_128bit: true
+tags:
+  - key: region
+    value: us-east-1
+sampler:
+  type: probabilistic
+  param: 0.5
+  samplingRefreshInterval: 30s
+reporter:
+  queueSize: 50
+  bufferFlushInterval: 2s
+  attemptReconnectInterval: 1m
+  localAgentHostPort: agent:6831
+baggage_restrictions:
+  refreshInterval: 5m
+throttler:
+  refreshInterval: 10s
+`
+
+const jsonConfig = `{
+  "serviceName": "from-file",
+  "sampler": {"type": "const", "param": 1, "samplingRefreshInterval": "30s"},
+  "reporter": {"bufferFlushInterval": "2s"}
+}`
+
+func TestFromReaderYAML(t *testing.T) {
+	cfg, err := FromReader(strings.NewReader(yamlConfig))
+	require.NoError(t, err)
+
+	assert.Equal(t, "from-file", cfg.ServiceName)
+	assert.True(t, cfg.Gen128Bit)
+	assert.Equal(t, []opentracing.Tag{{Key: "region", Value: "us-east-1"}}, cfg.Tags)
+	assert.Equal(t, "probabilistic", cfg.Sampler.Type)
+	assert.Equal(t, 0.5, cfg.Sampler.Param)
+	assert.Equal(t, 30*time.Second, cfg.Sampler.SamplingRefreshInterval)
+	assert.Equal(t, 50, cfg.Reporter.QueueSize)
+	assert.Equal(t, 2*time.Second, cfg.Reporter.BufferFlushInterval)
+	assert.Equal(t, time.Minute, cfg.Reporter.AttemptReconnectInterval)
+	assert.Equal(t, "agent:6831", cfg.Reporter.LocalAgentHostPort)
+	assert.Equal(t, 5*time.Minute, cfg.BaggageRestrictions.RefreshInterval)
+	assert.Equal(t, 10*time.Second, cfg.Throttler.RefreshInterval)
+}
+
+func TestFromReaderJSON(t *testing.T) {
+	cfg, err := FromReader(strings.NewReader(jsonConfig), Strict())
+	require.NoError(t, err)
+
+	assert.Equal(t, "from-file", cfg.ServiceName)
+	assert.Equal(t, "const", cfg.Sampler.Type)
+	assert.Equal(t, float64(1), cfg.Sampler.Param)
+	assert.Equal(t, 30*time.Second, cfg.Sampler.SamplingRefreshInterval)
+	assert.Equal(t, 2*time.Second, cfg.Reporter.BufferFlushInterval)
+}
+
+func TestFromReaderEmpty(t *testing.T) {
+	cfg, err := FromReader(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.NotNil(t, cfg.Sampler)
+	assert.NotNil(t, cfg.Reporter)
+}
+
+func TestFromReaderEnvOverridesFile(t *testing.T) {
+	setEnv(t, envServiceName, "from-env")
+	setEnv(t, envSamplerParam, "0.1")
+	defer unsetEnv(t, envServiceName)
+	defer unsetEnv(t, envSamplerParam)
+
+	cfg, err := FromReader(strings.NewReader(yamlConfig))
+	require.NoError(t, err)
+
+	assert.Equal(t, "from-env", cfg.ServiceName)
+	assert.Equal(t, 0.1, cfg.Sampler.Param)
+	// values not provided via env are kept from the file
+	assert.Equal(t, "probabilistic", cfg.Sampler.Type)
+	assert.Equal(t, 2*time.Second, cfg.Reporter.BufferFlushInterval)
+}
+
+func TestFromReaderEnvReporterAddressOverridesFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		document      string
+		env           map[string]string
+		agentHostPort string
+		endpoint      string
+	}{
+		{
+			name:     "collector endpoint replaces file agent",
+			document: "serviceName: svc\nreporter:\n  localAgentHostPort: agent:6831\n",
+			env:      map[string]string{envEndpoint: "http://collector:14268/api/traces"},
+			endpoint: "http://collector:14268/api/traces",
+		},
+		{
+			name:          "agent replaces file collector endpoint",
+			document:      "serviceName: svc\nreporter:\n  collectorEndpoint: http://collector:14268/api/traces\n",
+			env:           map[string]string{envAgentHost: "agent"},
+			agentHostPort: "agent:6831",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for k, v := range test.env {
+				setEnv(t, k, v)
+				defer unsetEnv(t, k)
+			}
+
+			cfg, err := FromReader(strings.NewReader(test.document))
+			require.NoError(t, err)
+			assert.Equal(t, test.agentHostPort, cfg.Reporter.LocalAgentHostPort)
+			assert.Equal(t, test.endpoint, cfg.Reporter.CollectorEndpoint)
+			require.NoError(t, cfg.Validate())
+
+			_, closer, err := cfg.NewTracer()
+			require.NoError(t, err)
+			closer.Close()
+		})
+	}
+}
+
+func TestFromReaderErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		options  []LoadOption
+		err      string
+	}{
+		{
+			name:     "malformed document",
+			document: "serviceName: [",
+			err:      "cannot parse configuration",
+		},
+		{
+			name:     "invalid duration",
+			document: "reporter:\n  bufferFlushInterval: soon\n",
+			err:      "cannot parse configuration",
+		},
+		{
+			name:     "unknown key in strict mode",
+			document: "serviceName: svc\nsampler:\n  tpye: const\n",
+			options:  []LoadOption{Strict()},
+			err:      "field tpye not found",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := FromReader(strings.NewReader(test.document), test.options...)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+
+	// unknown keys are ignored by default
+	cfg, err := FromReader(strings.NewReader("serviceName: svc\nsampler:\n  tpye: const\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "svc", cfg.ServiceName)
+}
+
+func TestFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jaeger-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "jaeger.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(yamlConfig), 0600))
+
+	cfg, err := FromFile(path, Strict())
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", cfg.ServiceName)
+
+	_, err = FromFile(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot open config file")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("serviceName: ["), 0600))
+	_, err = FromFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot load config file")
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/uber/jaeger-client-go"
+)
+
+const defaultWatchInterval = 10 * time.Second
+
+// Reconfigure applies the sampler, the reporter and the tracer-level tags of the
+// configuration to a running tracer created by NewTracer, see jaeger.Tracer.Reconfigure.
+// The options are interpreted as in NewTracer, e.g. to provide the metrics factory
+// and the logger used by the new sampler and reporter.
+//
+// Other settings, such as the service name, the propagation formats or the baggage
+// restrictions, are fixed when the tracer is created and are not changed.
+func (c Configuration) Reconfigure(tracer opentracing.Tracer, options ...Option) error {
+	jaegerTracer, ok := tracer.(*jaeger.Tracer)
+	if !ok {
+		return errors.Errorf("cannot reconfigure tracer of type %T", tracer)
+	}
+	if c.Disabled {
+		return errors.New("cannot disable a running tracer")
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	opts := applyOptions(options...)
+	tracerMetrics := jaeger.NewMetrics(opts.metrics, nil)
+	sampler, reporter, err := c.newSamplerAndReporter(opts, tracerMetrics)
+	if err != nil {
+		return err
+	}
+	jaegerTracer.Reconfigure(
+		jaeger.ReconfigureOptions.Sampler(sampler),
+		jaeger.ReconfigureOptions.Reporter(reporter),
+		jaeger.ReconfigureOptions.Tags(c.tracerTags(opts)),
+	)
+	return nil
+}
+
+// WatchOption is a function that sets some option on the file watcher created by WatchFile.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	interval    time.Duration
+	loadOptions []LoadOption
+	options     []Option
+}
+
+// WatchInterval sets how often the configuration file is checked for changes (default 10s).
+func WatchInterval(interval time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.interval = interval
+	}
+}
+
+// WatchLoadOptions sets the options used to load the configuration file, e.g. Strict().
+func WatchLoadOptions(loadOptions ...LoadOption) WatchOption {
+	return func(o *watchOptions) {
+		o.loadOptions = loadOptions
+	}
+}
+
+// WatchTracerOptions sets the options passed to Configuration.Reconfigure. The logger
+// provided via these options also receives the errors of the watcher.
+func WatchTracerOptions(options ...Option) WatchOption {
+	return func(o *watchOptions) {
+		o.options = options
+	}
+}
+
+// FileWatcher reconfigures a tracer when its configuration file changes.
+type FileWatcher struct {
+	path   string
+	tracer opentracing.Tracer
+	opts   watchOptions
+	logger jaeger.Logger
+
+	content  []byte
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     sync.WaitGroup
+}
+
+// WatchFile polls the configuration file at the given path, as loaded by FromFile(),
+// and applies it to the tracer via Configuration.Reconfigure whenever its content
+// changes. Invalid configurations are logged and ignored, keeping the tracer running
+// with the last valid one. The file is expected to hold the configuration the tracer
+// was created with, it is not applied until it changes. Close stops the watcher.
+func WatchFile(path string, tracer opentracing.Tracer, options ...WatchOption) (*FileWatcher, error) {
+	opts := watchOptions{interval: defaultWatchInterval}
+	for _, option := range options {
+		option(&opts)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read config file %s", path)
+	}
+	w := &FileWatcher{
+		path:    path,
+		tracer:  tracer,
+		opts:    opts,
+		logger:  applyOptions(opts.options...).logger,
+		content: content,
+		stop:    make(chan struct{}),
+	}
+	w.done.Add(1)
+	go w.watch()
+	return w, nil
+}
+
+func (w *FileWatcher) watch() {
+	defer w.done.Done()
+	ticker := time.NewTicker(w.opts.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.reload(); err != nil {
+				w.logger.Error(err.Error())
+			}
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// reload applies the configuration file to the tracer if its content changed.
+func (w *FileWatcher) reload() error {
+	content, err := ioutil.ReadFile(w.path)
+	if err != nil {
+		return errors.Wrapf(err, "cannot read config file %s", w.path)
+	}
+	if bytes.Equal(content, w.content) {
+		return nil
+	}
+	w.content = content
+	cfg, err := FromReader(bytes.NewReader(content), w.opts.loadOptions...)
+	if err != nil {
+		return errors.Wrapf(err, "cannot load config file %s", w.path)
+	}
+	if err := cfg.Reconfigure(w.tracer, w.opts.options...); err != nil {
+		return errors.Wrapf(err, "cannot apply config file %s", w.path)
+	}
+	w.logger.Infof("Tracer reconfigured from %s\n", w.path)
+	return nil
+}
+
+// Close stops watching the configuration file.
+func (w *FileWatcher) Close() error {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	w.done.Wait()
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/log"
+)
+
+func tracerTagValue(tracer opentracing.Tracer, key string) interface{} {
+	for _, tag := range tracer.(*jaeger.Tracer).Tags() {
+		if tag.Key == key {
+			return tag.Value
+		}
+	}
+	return nil
+}
+
+func TestReconfigure(t *testing.T) {
+	cfg := Configuration{
+		ServiceName: "svc",
+		Sampler:     &SamplerConfig{Type: "const", Param: 0},
+		Tags:        []opentracing.Tag{{Key: "version", Value: "1"}},
+	}
+	tracer, closer, err := cfg.NewTracer(Reporter(jaeger.NewNullReporter()))
+	require.NoError(t, err)
+	defer closeCloser(t, closer)
+
+	cfg.Sampler.Param = 1
+	cfg.Tags = []opentracing.Tag{{Key: "version", Value: "2"}}
+	reporter := jaeger.NewInMemoryReporter()
+	require.NoError(t, cfg.Reconfigure(tracer, Reporter(reporter), Tag("zone", "a")))
+
+	tracer.StartSpan("op").Finish()
+	assert.Equal(t, 1, reporter.SpansSubmitted())
+	assert.Equal(t, "2", tracerTagValue(tracer, "version"))
+	assert.Equal(t, "a", tracerTagValue(tracer, "zone"))
+}
+
+func TestReconfigureErrors(t *testing.T) {
+	tracer, closer, err := Configuration{ServiceName: "svc"}.NewTracer(Reporter(jaeger.NewNullReporter()))
+	require.NoError(t, err)
+	defer closeCloser(t, closer)
+
+	err = Configuration{ServiceName: "svc"}.Reconfigure(opentracing.NoopTracer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot reconfigure tracer of type opentracing.NoopTracer")
+
+	err = Configuration{Disabled: true}.Reconfigure(tracer)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot disable a running tracer")
+
+	err = Configuration{ServiceName: "svc", Sampler: &SamplerConfig{Type: "bogus"}}.Reconfigure(tracer)
+	require.Error(t, err)
+	assert.IsType(t, &ValidationError{}, err)
+}
+
+func TestWatchFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jaeger-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "jaeger.yaml")
+	writeConfig := func(content string) {
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	}
+	writeConfig("serviceName: svc\nsampler:\n  type: const\n  param: 0\n")
+
+	cfg, err := FromFile(path)
+	require.NoError(t, err)
+	tracer, closer, err := cfg.NewTracer(Reporter(jaeger.NewNullReporter()))
+	require.NoError(t, err)
+	defer closeCloser(t, closer)
+
+	logger := &log.BytesBufferLogger{}
+	reporter := jaeger.NewInMemoryReporter()
+	watcher, err := WatchFile(path, tracer,
+		WatchInterval(time.Millisecond),
+		WatchTracerOptions(Reporter(reporter), Logger(logger)),
+	)
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	writeConfig("serviceName: svc\nsampler:\n  type: const\n  param: 1\ntags:\n  - key: version\n    value: \"2\"\n")
+	require.Eventually(t, func() bool {
+		return tracerTagValue(tracer, "version") == "2"
+	}, time.Second, time.Millisecond)
+	tracer.StartSpan("op").Finish()
+	assert.Equal(t, 1, reporter.SpansSubmitted())
+
+	// invalid configurations are logged and the tracer keeps running
+	writeConfig("serviceName: svc\nsampler:\n  type: bogus\n")
+	require.Eventually(t, func() bool {
+		return strings.Contains(logger.String(), "cannot apply config file")
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "2", tracerTagValue(tracer, "version"))
+
+	require.NoError(t, watcher.Close())
+	require.NoError(t, watcher.Close())
+
+	_, err = WatchFile(filepath.Join(dir, "missing.yaml"), tracer)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot read config file")
+}
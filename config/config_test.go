@@ -17,7 +17,11 @@ package config
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -31,6 +35,7 @@ import (
 	"github.com/uber/jaeger-client-go"
 	"github.com/uber/jaeger-client-go/log"
 	"github.com/uber/jaeger-client-go/transport"
+	"github.com/uber/jaeger-client-go/x"
 )
 
 func TestNewSamplerConst(t *testing.T) {
@@ -228,6 +233,7 @@ func TestReporter(t *testing.T) {
 	assert.Equal(t, 61000000000, int(cfg.BufferFlushInterval))
 	assert.Equal(t, true, cfg.LogSpans)
 	assert.Equal(t, "nonlocalhost:6832", cfg.LocalAgentHostPort)
+	assert.Empty(t, cfg.CollectorEndpoint, "the agent from env replaces the collector endpoint")
 	assert.Equal(t, "user01", cfg.User)
 	assert.Equal(t, "password01", cfg.Password)
 	assert.Equal(t, false, cfg.DisableAttemptReconnecting)
@@ -255,7 +261,7 @@ func TestReporter(t *testing.T) {
 
 	// verify
 	assert.Equal(t, "http://1.2.3.4:5678/api/traces", cfg.CollectorEndpoint)
-	assert.Equal(t, "localhost", cfg.LocalAgentHostPort)
+	assert.Empty(t, cfg.LocalAgentHostPort, "the collector endpoint from env replaces the agent")
 	assert.Equal(t, "user", cfg.User)
 	assert.Equal(t, "password", cfg.Password)
 
@@ -333,6 +339,177 @@ func TestSamplerConfigFromEnv(t *testing.T) {
 	unsetEnv(t, envSamplerRefreshInterval)
 }
 
+func TestSamplerDelegatesFromEnv(t *testing.T) {
+	setEnv(t, envSamplerType, "priority")
+	setEnv(t, envSamplerDelegates, `[
+		{"type": "tagMatching", "tagKey": "debug", "tagMatchers": [{"value": true}]},
+		{"type": "remote", "param": 0.5, "samplingRefreshInterval": "1m"}
+	]`)
+	defer unsetEnv(t, envSamplerType)
+	defer unsetEnv(t, envSamplerDelegates)
+
+	cfg, err := FromEnv()
+	require.NoError(t, err)
+
+	assert.Equal(t, "priority", cfg.Sampler.Type)
+	assert.Equal(t, []SamplerConfig{
+		{
+			Type:        "tagMatching",
+			TagKey:      "debug",
+			TagMatchers: []x.TagMatcher{{TagValue: true}},
+		},
+		{
+			Type:                    "remote",
+			Param:                   0.5,
+			SamplingRefreshInterval: time.Minute,
+		},
+	}, cfg.Sampler.Delegates)
+}
+
+func TestOTELConfigFromEnv(t *testing.T) {
+	setEnv(t, envOTELServiceName, "otel-svc")
+	setEnv(t, envOTELResourceAttributes, "service.name=ignored,deployment.environment=prod,team=a%2Cb")
+	setEnv(t, envOTELTracesSampler, "parentbased_traceidratio")
+	setEnv(t, envOTELTracesSamplerArg, "0.25")
+	setEnv(t, envOTELPropagators, "tracecontext, b3multi")
+	setEnv(t, envOTELExporterOTLPEndpoint, "http://otel-collector:4318/")
+	RegisterTransport("otlp+http", func(TransportParams) (jaeger.Transport, error) { return nil, nil })
+	defer unregisterTransport("otlp+http")
+	defer unsetEnv(t, envOTELServiceName)
+	defer unsetEnv(t, envOTELResourceAttributes)
+	defer unsetEnv(t, envOTELTracesSampler)
+	defer unsetEnv(t, envOTELTracesSamplerArg)
+	defer unsetEnv(t, envOTELPropagators)
+	defer unsetEnv(t, envOTELExporterOTLPEndpoint)
+
+	cfg, err := FromEnv()
+	require.NoError(t, err)
+
+	assert.Equal(t, "otel-svc", cfg.ServiceName)
+	assert.Equal(t, []opentracing.Tag{
+		{Key: "deployment.environment", Value: "prod"},
+		{Key: "team", Value: "a,b"},
+	}, cfg.Tags)
+	assert.Equal(t, jaeger.SamplerTypeProbabilistic, cfg.Sampler.Type)
+	assert.Equal(t, 0.25, cfg.Sampler.Param)
+	assert.Equal(t, []string{"tracecontext", "b3multi"}, cfg.Propagators)
+	assert.Equal(t, "otlp+http://otel-collector:4318/v1/traces", cfg.Reporter.CollectorEndpoint)
+	assert.Equal(t, "", cfg.Reporter.LocalAgentHostPort)
+
+	// the service name falls back to the resource attributes
+	unsetEnv(t, envOTELServiceName)
+	cfg, err = FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "ignored", cfg.ServiceName)
+}
+
+func TestOTELEndpointWithoutTransport(t *testing.T) {
+	setEnv(t, envServiceName, "svc")
+	setEnv(t, envOTELExporterOTLPEndpoint, "http://otel-collector:4318")
+	defer unsetEnv(t, envServiceName)
+	defer unsetEnv(t, envOTELExporterOTLPEndpoint)
+
+	cfg, err := FromEnv()
+	require.NoError(t, err)
+
+	// the spans keep being sent to the agent
+	assert.Equal(t, "", cfg.Reporter.CollectorEndpoint)
+	assert.Equal(t, "localhost:6831", cfg.Reporter.LocalAgentHostPort)
+
+	logger := &log.BytesBufferLogger{}
+	_, closer, err := cfg.NewTracer(Logger(logger))
+	require.NoError(t, err)
+	closer.Close()
+	assert.Contains(t, logger.String(),
+		"ERROR: ignoring env var OTEL_EXPORTER_OTLP_ENDPOINT=http://otel-collector:4318: no transport registered for scheme \"otlp+http\"\n")
+}
+
+func TestJaegerEnvOverridesOTELEnv(t *testing.T) {
+	setEnv(t, envOTELServiceName, "otel-svc")
+	setEnv(t, envOTELResourceAttributes, "region=us-east-1,team=otel")
+	setEnv(t, envOTELTracesSampler, "always_on")
+	setEnv(t, envOTELExporterOTLPTracesEndpoint, "https://otel-collector:4318/custom")
+	setEnv(t, envServiceName, "jaeger-svc")
+	setEnv(t, envTags, "team=jaeger")
+	setEnv(t, envSamplerParam, "0")
+	setEnv(t, envAgentHost, "agent")
+	RegisterTransport("otlp+https", func(TransportParams) (jaeger.Transport, error) { return nil, nil })
+	defer unregisterTransport("otlp+https")
+	defer unsetEnv(t, envOTELServiceName)
+	defer unsetEnv(t, envOTELResourceAttributes)
+	defer unsetEnv(t, envOTELTracesSampler)
+	defer unsetEnv(t, envOTELExporterOTLPTracesEndpoint)
+	defer unsetEnv(t, envServiceName)
+	defer unsetEnv(t, envTags)
+	defer unsetEnv(t, envSamplerParam)
+	defer unsetEnv(t, envAgentHost)
+
+	cfg, err := FromEnv()
+	require.NoError(t, err)
+
+	assert.Equal(t, "jaeger-svc", cfg.ServiceName)
+	assert.Equal(t, []opentracing.Tag{
+		{Key: "region", Value: "us-east-1"},
+		{Key: "team", Value: "jaeger"},
+	}, cfg.Tags)
+	assert.Equal(t, jaeger.SamplerTypeConst, cfg.Sampler.Type)
+	assert.Equal(t, float64(0), cfg.Sampler.Param)
+	assert.Equal(t, "", cfg.Reporter.CollectorEndpoint)
+	assert.Equal(t, "agent:6831", cfg.Reporter.LocalAgentHostPort)
+
+	unsetEnv(t, envAgentHost)
+	cfg, err = FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "otlp+https://otel-collector:4318/custom", cfg.Reporter.CollectorEndpoint)
+}
+
+func TestOTELSamplerFromEnv(t *testing.T) {
+	tests := []struct {
+		sampler string
+		arg     string
+		expect  SamplerConfig
+		err     string
+	}{
+		{sampler: "always_on", expect: SamplerConfig{Type: "const", Param: 1}},
+		{sampler: "parentbased_always_off", expect: SamplerConfig{Type: "const", Param: 0}},
+		{sampler: "traceidratio", expect: SamplerConfig{Type: "probabilistic", Param: 1}},
+		{sampler: "traceidratio", arg: "0.5", expect: SamplerConfig{Type: "probabilistic", Param: 0.5}},
+		{sampler: "traceidratio", arg: "half", err: "cannot parse env var OTEL_TRACES_SAMPLER_ARG=half"},
+		{sampler: "jaeger_remote", expect: SamplerConfig{Type: "remote"}},
+		{
+			sampler: "parentbased_jaeger_remote",
+			arg:     "endpoint=http://agent:5778/sampling,pollingIntervalMs=5000,initialSamplingRate=0.25",
+			expect: SamplerConfig{
+				Type:                    "remote",
+				Param:                   0.25,
+				SamplingServerURL:       "http://agent:5778/sampling",
+				SamplingRefreshInterval: 5 * time.Second,
+			},
+		},
+		{sampler: "jaeger_remote", arg: "endpoint", err: `expecting key=value, received "endpoint"`},
+		{sampler: "jaeger_remote", arg: "pollingIntervalMs=soon", err: "invalid syntax"},
+		{sampler: "jaeger_remote", arg: "initialSamplingRate=most", err: "invalid syntax"},
+		{sampler: "jaeger_remote", arg: "bogus=1", err: `unknown key "bogus"`},
+	}
+	for _, test := range tests {
+		t.Run(test.sampler+"/"+test.arg, func(t *testing.T) {
+			setEnv(t, envOTELTracesSampler, test.sampler)
+			setEnv(t, envOTELTracesSamplerArg, test.arg)
+			defer unsetEnv(t, envOTELTracesSampler)
+			defer unsetEnv(t, envOTELTracesSamplerArg)
+
+			sc, err := (&SamplerConfig{}).samplerConfigFromEnv()
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expect, *sc)
+		})
+	}
+}
+
 func TestDeprecatedSamplerConfigFromEnv(t *testing.T) {
 	// prepare
 	setEnv(t, envSamplerManagerHostPort, "http://themaster")
@@ -405,6 +582,186 @@ func TestReporterConfigFromEnv(t *testing.T) {
 	unsetEnv(t, envPassword)
 }
 
+func TestTracerOptionsFromEnv(t *testing.T) {
+	setEnv(t, envMaxTagValueLength, "128")
+	setEnv(t, envMaxLogsPerSpan, "50")
+	setEnv(t, envMaxTagsPerSpan, "60")
+	setEnv(t, envMaxReferencesPerSpan, "70")
+	setEnv(t, envMaxLinksPerSpan, "75")
+	setEnv(t, envMaxFieldsPerLog, "80")
+	setEnv(t, envMaxSpanSize, "65000")
+	setEnv(t, envPoolSpans, "true")
+	setEnv(t, envPprofLabels, "true")
+	setEnv(t, envRecordPanics, "true")
+	setEnv(t, envZipkinSharedRPCSpan, "true")
+	setEnv(t, envNoDebugFlagOnForcedSampling, "true")
+	setEnv(t, envReporterHTTPHeaders, "X-Tenant=acme, X-Team = tracing")
+	defer unsetEnv(t, envMaxTagValueLength)
+	defer unsetEnv(t, envMaxLogsPerSpan)
+	defer unsetEnv(t, envMaxTagsPerSpan)
+	defer unsetEnv(t, envMaxReferencesPerSpan)
+	defer unsetEnv(t, envMaxLinksPerSpan)
+	defer unsetEnv(t, envMaxFieldsPerLog)
+	defer unsetEnv(t, envMaxSpanSize)
+	defer unsetEnv(t, envPoolSpans)
+	defer unsetEnv(t, envPprofLabels)
+	defer unsetEnv(t, envRecordPanics)
+	defer unsetEnv(t, envZipkinSharedRPCSpan)
+	defer unsetEnv(t, envNoDebugFlagOnForcedSampling)
+	defer unsetEnv(t, envReporterHTTPHeaders)
+
+	cfg, err := FromEnv()
+	require.NoError(t, err)
+
+	assert.Equal(t, 128, cfg.MaxTagValueLength)
+	assert.Equal(t, 50, cfg.MaxLogsPerSpan)
+	assert.Equal(t, 60, cfg.MaxTagsPerSpan)
+	assert.Equal(t, 70, cfg.MaxReferencesPerSpan)
+	assert.Equal(t, 75, cfg.MaxLinksPerSpan)
+	assert.Equal(t, 80, cfg.MaxFieldsPerLog)
+	assert.Equal(t, 65000, cfg.MaxSpanSize)
+	assert.True(t, cfg.PoolSpans)
+	assert.True(t, cfg.PprofLabels)
+	assert.True(t, cfg.RecordPanics)
+	assert.True(t, cfg.ZipkinSharedRPCSpan)
+	assert.True(t, cfg.NoDebugFlagOnForcedSampling)
+	assert.Equal(t, map[string]string{"X-Tenant": "acme", "X-Team": "tracing"}, cfg.Reporter.HTTPHeaders)
+	assert.Nil(t, cfg.Headers)
+	assert.Nil(t, cfg.BaggageRestrictions)
+	assert.Nil(t, cfg.Throttler)
+}
+
+func TestHeadersBaggageRestrictionsAndThrottlerFromEnv(t *testing.T) {
+	setEnv(t, envHeadersDebug, "x-debug-id")
+	setEnv(t, envHeadersBaggage, "x-baggage")
+	setEnv(t, envHeadersTraceContext, "x-trace-id")
+	setEnv(t, envHeadersBaggagePrefix, "x-ctx-")
+	setEnv(t, envBaggageRestrictionsDenyOnInitFail, "true")
+	setEnv(t, envBaggageRestrictionsHostPort, "agent:5778")
+	setEnv(t, envBaggageRestrictionsRefreshInterval, "1m")
+	setEnv(t, envThrottlerHostPort, "agent:5778")
+	setEnv(t, envThrottlerRefreshInterval, "10s")
+	setEnv(t, envThrottlerSynchronousInitialization, "true")
+	defer unsetEnv(t, envHeadersDebug)
+	defer unsetEnv(t, envHeadersBaggage)
+	defer unsetEnv(t, envHeadersTraceContext)
+	defer unsetEnv(t, envHeadersBaggagePrefix)
+	defer unsetEnv(t, envBaggageRestrictionsDenyOnInitFail)
+	defer unsetEnv(t, envBaggageRestrictionsHostPort)
+	defer unsetEnv(t, envBaggageRestrictionsRefreshInterval)
+	defer unsetEnv(t, envThrottlerHostPort)
+	defer unsetEnv(t, envThrottlerRefreshInterval)
+	defer unsetEnv(t, envThrottlerSynchronousInitialization)
+
+	cfg, err := FromEnv()
+	require.NoError(t, err)
+
+	assert.Equal(t, &jaeger.HeadersConfig{
+		JaegerDebugHeader:        "x-debug-id",
+		JaegerBaggageHeader:      "x-baggage",
+		TraceContextHeaderName:   "x-trace-id",
+		TraceBaggageHeaderPrefix: "x-ctx-",
+	}, cfg.Headers)
+	assert.Equal(t, &BaggageRestrictionsConfig{
+		DenyBaggageOnInitializationFailure: true,
+		HostPort:                           "agent:5778",
+		RefreshInterval:                    time.Minute,
+	}, cfg.BaggageRestrictions)
+	assert.Equal(t, &ThrottlerConfig{
+		HostPort:                  "agent:5778",
+		RefreshInterval:           10 * time.Second,
+		SynchronousInitialization: true,
+	}, cfg.Throttler)
+
+	// values not provided via env are kept
+	cfg = &Configuration{Headers: &jaeger.HeadersConfig{JaegerBaggageHeader: "keep"}}
+	unsetEnv(t, envHeadersBaggage)
+	_, err = cfg.FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "keep", cfg.Headers.JaegerBaggageHeader)
+	assert.Equal(t, "x-debug-id", cfg.Headers.JaegerDebugHeader)
+}
+
+func TestNewTracerWithLimits(t *testing.T) {
+	cfg := &Configuration{
+		ServiceName:       "my-service",
+		Sampler:           &SamplerConfig{Type: "const", Param: 1},
+		MaxTagValueLength: 5,
+		MaxLogsPerSpan:    2,
+	}
+	tracer, closer, err := cfg.NewTracer(Reporter(jaeger.NewNullReporter()))
+	require.NoError(t, err)
+	defer closeCloser(t, closer)
+
+	span := tracer.StartSpan("op").(*jaeger.Span)
+	span.SetTag("key", "a long value")
+	for i := 0; i < 5; i++ {
+		span.LogKV("i", i)
+	}
+	assert.Equal(t, "a lon", thriftTagValue(span, "key"))
+	assert.Len(t, span.Logs(), 2)
+	span.Finish()
+
+	// explicit options take precedence over the configuration
+	tracer, closer2, err := cfg.NewTracer(Reporter(jaeger.NewNullReporter()), MaxTagValueLength(7))
+	require.NoError(t, err)
+	defer closeCloser(t, closer2)
+	span = tracer.StartSpan("op").(*jaeger.Span)
+	span.SetTag("key", "a long value")
+	assert.Equal(t, "a long ", thriftTagValue(span, "key"))
+	span.Finish()
+}
+
+func TestNewTracerWithSpanLimits(t *testing.T) {
+	cfg := &Configuration{
+		ServiceName:          "my-service",
+		Sampler:              &SamplerConfig{Type: "const", Param: 1},
+		MaxTagsPerSpan:       3,
+		MaxReferencesPerSpan: 1,
+		MaxLinksPerSpan:      1,
+		MaxFieldsPerLog:      1,
+	}
+	tracer, closer, err := cfg.NewTracer(Reporter(jaeger.NewNullReporter()), MaxTagsPerSpan(4))
+	require.NoError(t, err)
+	defer closeCloser(t, closer)
+
+	parent1 := tracer.StartSpan("parent1")
+	parent2 := tracer.StartSpan("parent2")
+	span := tracer.StartSpan("op",
+		opentracing.ChildOf(parent1.Context()),
+		opentracing.FollowsFrom(parent2.Context()),
+	).(*jaeger.Span)
+	for i := 0; i < 5; i++ {
+		span.SetTag(fmt.Sprintf("tag%d", i), i)
+	}
+	span.LogKV("a", 1, "b", 2)
+
+	tags := span.Tags()
+	assert.Contains(t, tags, "tag3")
+	assert.NotContains(t, tags, "tag4")
+	assert.Equal(t, 1, tags[jaeger.DroppedTagsTagKey])
+	assert.Equal(t, 1, tags[jaeger.DroppedReferencesTagKey])
+	assert.Equal(t, 1, tags[jaeger.DroppedLogFieldsTagKey])
+	assert.Len(t, span.References(), 1)
+	assert.Len(t, span.Logs()[0].Fields, 1)
+	span.AddLink(parent1.Context())
+	span.AddLink(parent2.Context())
+	assert.Len(t, span.Links(), 1)
+	assert.Equal(t, 1, span.Tags()[jaeger.DroppedLinksTagKey])
+	span.Finish()
+	parent2.Finish()
+	parent1.Finish()
+}
+
+func thriftTagValue(span *jaeger.Span, key string) string {
+	for _, tag := range jaeger.BuildJaegerThrift(span).Tags {
+		if tag.Key == key {
+			return tag.GetVStr()
+		}
+	}
+	return ""
+}
+
 func TestReporterAgentConfigFromEnv(t *testing.T) {
 	// prepare
 	unsetEnv(t, envEndpoint)
@@ -498,6 +855,86 @@ func TestParsingErrorsFromEnv(t *testing.T) {
 			envVar: envSamplerRefreshInterval,
 			value:  "NOT_A_DURATION",
 		},
+		{
+			envVar: envSamplerDelegates,
+			value:  "NOT_A_LIST",
+		},
+		{
+			envVar: envOTELResourceAttributes,
+			value:  "NOT_A_PAIR",
+		},
+		{
+			envVar: envOTELTracesSampler,
+			value:  "NOT_A_SAMPLER",
+		},
+		{
+			envVar: envOTELExporterOTLPEndpoint,
+			value:  "NOT_A_URL",
+		},
+		{
+			envVar: envMaxTagValueLength,
+			value:  "NOT_AN_INT",
+		},
+		{
+			envVar: envMaxLogsPerSpan,
+			value:  "NOT_AN_INT",
+		},
+		{
+			envVar: envMaxTagsPerSpan,
+			value:  "NOT_AN_INT",
+		},
+		{
+			envVar: envMaxReferencesPerSpan,
+			value:  "NOT_AN_INT",
+		},
+		{
+			envVar: envMaxLinksPerSpan,
+			value:  "NOT_AN_INT",
+		},
+		{
+			envVar: envMaxFieldsPerLog,
+			value:  "NOT_AN_INT",
+		},
+		{
+			envVar: envMaxSpanSize,
+			value:  "NOT_AN_INT",
+		},
+		{
+			envVar: envPoolSpans,
+			value:  "NOT_A_BOOLEAN",
+		},
+		{
+			envVar: envPprofLabels,
+			value:  "NOT_A_BOOLEAN",
+		},
+		{
+			envVar: envRecordPanics,
+			value:  "NOT_A_BOOLEAN",
+		},
+		{
+			envVar: envBaggageRestrictionsDenyOnInitFail,
+			value:  "NOT_A_BOOLEAN",
+		},
+		{
+			envVar: envBaggageRestrictionsRefreshInterval,
+			value:  "NOT_A_DURATION",
+		},
+		{
+			envVar: envThrottlerRefreshInterval,
+			value:  "NOT_A_DURATION",
+		},
+		{
+			envVar: envThrottlerSynchronousInitialization,
+			value:  "NOT_A_BOOLEAN",
+		},
+		{
+			envVar: envReporterHTTPHeaders,
+			value:  "NOT_A_PAIR",
+		},
+		{
+			envVar: envReporterTransportOptions,
+			value:  "NOT_A_PAIR",
+		},
 		{
 			envVar: envReporterMaxQueueSize,
 			value:  "NOT_AN_INT",
@@ -526,10 +963,17 @@ func TestParsingErrorsFromEnv(t *testing.T) {
 			unsetEnv(t, envAgentHost)
 			unsetEnv(t, envAgentPort)
 		}
+		if test.envVar == envOTELExporterOTLPEndpoint {
+			// the OTLP endpoint is only parsed when no agent address is provided
+			unsetEnv(t, envAgentHost)
+		}
 		_, err := FromEnv()
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), fmt.Sprintf("cannot parse env var %s=%s", test.envVar, test.value))
 		unsetEnv(t, test.envVar)
+		if test.envVar == envOTELExporterOTLPEndpoint {
+			setEnv(t, envAgentHost, "localhost")
+		}
 	}
 
 }
@@ -569,10 +1013,126 @@ func TestInvalidSamplerType(t *testing.T) {
 	rcs.Close()
 }
 
+func TestCompositeSampler(t *testing.T) {
+	cfg := &SamplerConfig{
+		Type: "priority",
+		Delegates: []SamplerConfig{
+			{
+				Type: "rules",
+				Rules: []x.SamplingRule{{
+					Conditions: []x.RuleCondition{{Source: x.RuleSourceOperation, Prefix: "health"}},
+					Action:     x.RuleActionDrop,
+				}},
+			},
+			{
+				Type:        "tagMatching",
+				TagKey:      "theWho",
+				TagMatchers: []x.TagMatcher{{TagValue: "Bender"}},
+			},
+			{
+				Type:  "remote",
+				Param: 0.5,
+			},
+		},
+	}
+	s, err := cfg.NewSampler("x", jaeger.NewNullMetrics())
+	require.NoError(t, err)
+	defer s.Close()
+
+	state := jaeger.NewSamplerState(s.(jaeger.SamplerV2))
+	assert.Equal(t, "priority", state.Type)
+	require.Len(t, state.Delegates, 3)
+	assert.Equal(t, "rules", state.Delegates[0].Type)
+	assert.Equal(t, "tagmatching", state.Delegates[1].Type)
+	assert.Equal(t, "probabilistic", state.Delegates[2].Type)
+}
+
+func TestCompositeSamplerErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SamplerConfig
+		err    string
+	}{
+		{
+			name:   "priority without delegates",
+			config: SamplerConfig{Type: "priority"},
+			err:    "priority sampler requires at least one delegate",
+		},
+		{
+			name: "invalid nested delegate",
+			config: SamplerConfig{
+				Type: "priority",
+				Delegates: []SamplerConfig{
+					{Type: "const", Param: 1},
+					{
+						Type:      "priority",
+						Delegates: []SamplerConfig{{Type: "probabilistic", Param: 5}},
+					},
+				},
+			},
+			err: "delegates[1].delegates[0]: invalid Param for probabilistic sampler",
+		},
+		{
+			name: "unknown delegate type",
+			config: SamplerConfig{
+				Type:      "priority",
+				Delegates: []SamplerConfig{{Type: "bogus"}},
+			},
+			err: "delegates[0]: unknown sampler type (bogus)",
+		},
+		{
+			name:   "tagMatching without tagKey",
+			config: SamplerConfig{Type: "tagMatching", TagMatchers: []x.TagMatcher{{TagValue: "v"}}},
+			err:    "tagMatching sampler requires tagKey",
+		},
+		{
+			name:   "tagMatching without matchers",
+			config: SamplerConfig{Type: "tagMatching", TagKey: "k"},
+			err:    "tagMatching sampler requires at least one tag matcher",
+		},
+		{
+			name: "tagMatching with non-scalar value",
+			config: SamplerConfig{
+				Type:        "tagMatching",
+				TagKey:      "k",
+				TagMatchers: []x.TagMatcher{{TagValue: []interface{}{1}}},
+			},
+			err: "tagMatchers[0]: value must be a string, number or boolean",
+		},
+		{
+			name:   "invalid rules",
+			config: SamplerConfig{Type: "rules", Rules: []x.SamplingRule{{Action: x.RuleActionSample}}},
+			err:    "rules[0]: no conditions",
+		},
+		{
+			name:   "delegates on non-priority sampler",
+			config: SamplerConfig{Type: "const", Delegates: []SamplerConfig{{Type: "const"}}},
+			err:    `delegates can only be used with the "priority" sampler`,
+		},
+		{
+			name:   "tagKey on non-tagMatching sampler",
+			config: SamplerConfig{Type: "const", TagKey: "k"},
+			err:    `tagKey and tagMatchers can only be used with the "tagMatching" sampler`,
+		},
+		{
+			name:   "rules on non-rules sampler",
+			config: SamplerConfig{Type: "const", Rules: []x.SamplingRule{{}}},
+			err:    `rules can only be used with the "rules" sampler`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.config.NewSampler("x", jaeger.NewNullMetrics())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
 func TestUDPTransportType(t *testing.T) {
 	rc := &ReporterConfig{LocalAgentHostPort: "localhost:1234"}
 	expect, _ := jaeger.NewUDPTransport(rc.LocalAgentHostPort, 0)
-	sender, err := rc.newTransport(log.NullLogger)
+	sender, err := rc.newTransport(log.NullLogger, nil)
 	require.NoError(t, err)
 	require.IsType(t, expect, sender)
 }
@@ -580,7 +1140,7 @@ func TestUDPTransportType(t *testing.T) {
 func TestHTTPTransportType(t *testing.T) {
 	rc := &ReporterConfig{CollectorEndpoint: "http://1.2.3.4:5678/api/traces"}
 	expect := transport.NewHTTPTransport(rc.CollectorEndpoint)
-	sender, err := rc.newTransport(log.NullLogger)
+	sender, err := rc.newTransport(log.NullLogger, nil)
 	require.NoError(t, err)
 	require.IsType(t, expect, sender)
 }
@@ -592,7 +1152,7 @@ func TestHTTPTransportTypeWithAuth(t *testing.T) {
 		Password:          "auth_pass",
 	}
 	expect := transport.NewHTTPTransport(rc.CollectorEndpoint)
-	sender, err := rc.newTransport(log.NullLogger)
+	sender, err := rc.newTransport(log.NullLogger, nil)
 	require.NoError(t, err)
 	require.IsType(t, expect, sender)
 }
@@ -915,3 +1475,82 @@ func TestWithRandomNumber(t *testing.T) {
 	assert.Equal(t, traceID, spanCtx.TraceID().Low)
 	defer closeCloser(t, closer)
 }
+
+func TestBearerTokenCredentials(t *testing.T) {
+	authorizations := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations <- r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "jaeger-token")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("from-file\n"), 0600))
+
+	tests := []struct {
+		reporter *ReporterConfig
+		expected string
+	}{
+		{reporter: &ReporterConfig{AuthToken: "static"}, expected: "Bearer static"},
+		{reporter: &ReporterConfig{AuthTokenFile: tokenFile}, expected: "Bearer from-file"},
+		{
+			reporter: &ReporterConfig{AuthToken: "static", Credentials: jaeger.NewStaticTokenCredentials("custom")},
+			expected: "Bearer custom",
+		},
+	}
+	for _, test := range tests {
+		test.reporter.CollectorEndpoint = server.URL
+		cfg := Configuration{
+			ServiceName: "svc",
+			Sampler:     &SamplerConfig{Type: "const", Param: 1},
+			Reporter:    test.reporter,
+		}
+		tracer, closer, err := cfg.NewTracer()
+		require.NoError(t, err)
+		tracer.StartSpan("op").Finish()
+		closeCloser(t, closer)
+		assert.Equal(t, test.expected, <-authorizations)
+	}
+
+	missing := filepath.Join(dir, "missing")
+	_, err = (&ReporterConfig{CollectorEndpoint: server.URL, AuthTokenFile: missing}).newTransport(nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot create credentials for the collector: cannot read token file")
+
+	_, err = (&SamplerConfig{Type: "remote", AuthTokenFile: missing}).NewSampler("svc", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot create credentials for the sampling server: cannot read token file")
+}
+
+func TestBearerTokenValidation(t *testing.T) {
+	err := Configuration{
+		ServiceName: "svc",
+		Sampler:     &SamplerConfig{Type: "remote", AuthToken: "token", AuthTokenFile: "/token"},
+		Reporter:    &ReporterConfig{User: "user", Password: "password", AuthToken: "token"},
+	}.Validate()
+	require.Error(t, err)
+	assert.Equal(t, []FieldProblem{
+		{Field: "sampler", Message: "only one of authToken and authTokenFile can be specified"},
+		{Field: "reporter", Message: "user and password cannot be combined with a bearer token"},
+	}, err.(*ValidationError).Problems)
+}
+
+func TestBearerTokenFromEnv(t *testing.T) {
+	setEnv(t, envAuthToken, "reporter-token")
+	setEnv(t, envAuthTokenFile, "/var/run/secrets/token")
+	setEnv(t, envSamplerAuthToken, "sampler-token")
+	setEnv(t, envSamplerAuthTokenFile, "/var/run/secrets/sampler-token")
+	defer unsetEnv(t, envAuthToken)
+	defer unsetEnv(t, envAuthTokenFile)
+	defer unsetEnv(t, envSamplerAuthToken)
+	defer unsetEnv(t, envSamplerAuthTokenFile)
+
+	cfg, err := FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "reporter-token", cfg.Reporter.AuthToken)
+	assert.Equal(t, "/var/run/secrets/token", cfg.Reporter.AuthTokenFile)
+	assert.Equal(t, "sampler-token", cfg.Sampler.AuthToken)
+	assert.Equal(t, "/var/run/secrets/sampler-token", cfg.Sampler.AuthTokenFile)
+}
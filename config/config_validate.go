@@ -0,0 +1,285 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/x"
+)
+
+// FieldProblem describes a problem with a single configuration field. The field is
+// identified by its path in the YAML representation of the Configuration,
+// e.g. "sampler.param" or "sampler.delegates[1].type".
+type FieldProblem struct {
+	Field   string
+	Message string
+}
+
+func (p FieldProblem) String() string {
+	return p.Field + ": " + p.Message
+}
+
+// ValidationError is returned by Configuration.Validate and lists all the problems
+// found in the configuration.
+type ValidationError struct {
+	Problems []FieldProblem
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		problems[i] = p.String()
+	}
+	return "invalid configuration: " + strings.Join(problems, "; ")
+}
+
+// validator accumulates the problems found while validating the configuration.
+type validator struct {
+	problems []FieldProblem
+}
+
+func (v *validator) add(field string, format string, args ...interface{}) {
+	v.problems = append(v.problems, FieldProblem{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+func (v *validator) checkNotNegative(field string, d time.Duration) {
+	if d < 0 {
+		v.add(field, "must not be negative, received %v", d)
+	}
+}
+
+func (v *validator) checkHostPort(field string, hostPort string) {
+	if hostPort == "" {
+		return
+	}
+	if _, _, err := net.SplitHostPort(hostPort); err != nil {
+		v.add(field, "expecting host:port, received %q", hostPort)
+	}
+}
+
+func (v *validator) checkHTTPURL(field string, rawURL string) {
+	if rawURL == "" {
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		v.add(field, "cannot parse URL %q: %v", rawURL, err)
+		return
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		v.add(field, "expecting an http or https URL, received %q", rawURL)
+		return
+	}
+	if u.Host == "" {
+		v.add(field, "missing host in URL %q", rawURL)
+	}
+}
+
+func (v *validator) checkTLS(field string, tc *TLSConfig) {
+	if tc == nil {
+		return
+	}
+	if err := tc.validate(); err != nil {
+		v.add(field, "%v", err)
+	}
+}
+
+func (v *validator) checkAuthToken(field string, authToken, authTokenFile string) {
+	if authToken != "" && authTokenFile != "" {
+		v.add(field, "only one of authToken and authTokenFile can be specified")
+	}
+}
+
+// urlScheme returns the scheme of the URL, or an empty string if it cannot be parsed.
+func urlScheme(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return u.Scheme
+	}
+	return ""
+}
+
+// checkCollectorEndpoint checks that a transport is registered for the scheme of the URL.
+func (v *validator) checkCollectorEndpoint(field string, rawURL string) {
+	if rawURL == "" {
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		v.add(field, "cannot parse URL %q: %v", rawURL, err)
+		return
+	}
+	if _, ok := transportFactory(u.Scheme); !ok {
+		v.add(field, "no transport registered for scheme %q, received %q (registered: %s)",
+			u.Scheme, rawURL, strings.Join(RegisteredTransports(), ", "))
+		return
+	}
+	if (u.Scheme == "http" || u.Scheme == "https") && u.Host == "" {
+		v.add(field, "missing host in URL %q", rawURL)
+	}
+}
+
+// Validate checks the configuration for errors, such as out of range values,
+// malformed addresses or conflicting settings, without creating the tracer.
+// All problems are reported at once via *ValidationError. NewTracer calls
+// Validate before creating the tracer, but it can also be called ahead of
+// time, e.g. to lint configuration files in a deployment pipeline.
+func (c Configuration) Validate() error {
+	v := &validator{}
+	if c.ServiceName == "" && !c.Disabled {
+		v.add("serviceName", "no service name provided")
+	}
+	for i, name := range c.Propagators {
+		if _, ok := knownPropagators[strings.ToLower(strings.TrimSpace(name))]; !ok {
+			v.add(fmt.Sprintf("propagators[%d]", i), "unknown propagator %q", name)
+		}
+	}
+	for i, name := range c.ResourceDetectors {
+		if _, ok := knownResourceDetectors[strings.ToLower(strings.TrimSpace(name))]; !ok {
+			v.add(fmt.Sprintf("resourceDetectors[%d]", i), "unknown resource detector %q", name)
+		}
+	}
+	if c.MaxTagValueLength < 0 {
+		v.add("maxTagValueLength", "must not be negative, received %d", c.MaxTagValueLength)
+	}
+	if c.MaxLogsPerSpan < 0 {
+		v.add("maxLogsPerSpan", "must not be negative, received %d", c.MaxLogsPerSpan)
+	}
+	if c.MaxTagsPerSpan < 0 {
+		v.add("maxTagsPerSpan", "must not be negative, received %d", c.MaxTagsPerSpan)
+	}
+	if c.MaxReferencesPerSpan < 0 {
+		v.add("maxReferencesPerSpan", "must not be negative, received %d", c.MaxReferencesPerSpan)
+	}
+	if c.MaxLinksPerSpan < 0 {
+		v.add("maxLinksPerSpan", "must not be negative, received %d", c.MaxLinksPerSpan)
+	}
+	if c.MaxFieldsPerLog < 0 {
+		v.add("maxFieldsPerLog", "must not be negative, received %d", c.MaxFieldsPerLog)
+	}
+	if c.MaxSpanSize < 0 {
+		v.add("maxSpanSize", "must not be negative, received %d", c.MaxSpanSize)
+	}
+	if c.Sampler != nil {
+		c.Sampler.validate("sampler", v)
+	}
+	if c.Reporter != nil {
+		c.Reporter.validate("reporter", v)
+	}
+	if c.BaggageRestrictions != nil {
+		v.checkHostPort("baggage_restrictions.hostPort", c.BaggageRestrictions.HostPort)
+		v.checkNotNegative("baggage_restrictions.refreshInterval", c.BaggageRestrictions.RefreshInterval)
+	}
+	if c.Throttler != nil {
+		v.checkHostPort("throttler.hostPort", c.Throttler.HostPort)
+		v.checkNotNegative("throttler.refreshInterval", c.Throttler.RefreshInterval)
+	}
+	return v.err()
+}
+
+func (sc *SamplerConfig) validate(path string, v *validator) {
+	samplerType := strings.ToLower(sc.Type)
+	switch samplerType {
+	case jaeger.SamplerTypeConst:
+	case jaeger.SamplerTypeProbabilistic, jaeger.SamplerTypeRemote, "":
+		if sc.Param < 0 || sc.Param > 1 {
+			v.add(path+".param", "expecting a probability between 0 and 1, received %v", sc.Param)
+		}
+	case jaeger.SamplerTypeRateLimiting:
+		if sc.Param < 0 {
+			v.add(path+".param", "expecting a non-negative number of traces per second, received %v", sc.Param)
+		}
+	case samplerTypePriority:
+		if len(sc.Delegates) == 0 {
+			v.add(path+".delegates", "priority sampler requires at least one delegate")
+		}
+	case samplerTypeTagMatching:
+		if err := sc.checkTagMatchingFields(); err != nil {
+			v.add(path, "%v", err)
+		}
+	case samplerTypeRules:
+		if _, err := x.NewRulesSampler(sc.Rules); err != nil {
+			v.add(path, "%v", err)
+		}
+	default:
+		v.add(path+".type", "unknown sampler type (%s)", sc.Type)
+	}
+	if err := sc.checkCompositeFields(samplerType); err != nil {
+		v.add(path, "%v", err)
+	}
+
+	v.checkHTTPURL(path+".samplingServerURL", sc.SamplingServerURL)
+	v.checkTLS(path+".tls", sc.TLS)
+	v.checkAuthToken(path, sc.AuthToken, sc.AuthTokenFile)
+	v.checkNotNegative(path+".samplingRefreshInterval", sc.SamplingRefreshInterval)
+	if sc.MaxOperations < 0 {
+		v.add(path+".maxOperations", "must not be negative, received %d", sc.MaxOperations)
+	}
+
+	for i := range sc.Delegates {
+		sc.Delegates[i].validate(fmt.Sprintf("%s.delegates[%d]", path, i), v)
+	}
+}
+
+func (rc *ReporterConfig) validate(path string, v *validator) {
+	if rc.CollectorEndpoint != "" && rc.LocalAgentHostPort != "" {
+		v.add(
+			path,
+			"only one of collectorEndpoint (%q) and localAgentHostPort (%q) can be specified",
+			rc.CollectorEndpoint, rc.LocalAgentHostPort,
+		)
+	}
+	v.checkCollectorEndpoint(path+".collectorEndpoint", rc.CollectorEndpoint)
+	for i, endpoint := range rc.CollectorEndpoints {
+		field := fmt.Sprintf("%s.collectorEndpoints[%d]", path, i)
+		if rc.CollectorEndpoint == "" {
+			v.add(field, "requires collectorEndpoint")
+			continue
+		}
+		v.checkCollectorEndpoint(field, endpoint)
+		if u, err := url.Parse(endpoint); err == nil && !strings.EqualFold(u.Scheme, urlScheme(rc.CollectorEndpoint)) {
+			v.add(field, "must have the same scheme as collectorEndpoint %q, received %q", rc.CollectorEndpoint, endpoint)
+		}
+	}
+	if rc.CollectorSRV != "" && rc.CollectorEndpoint == "" {
+		v.add(path+".collectorSRV", "requires collectorEndpoint")
+	}
+	v.checkHostPort(path+".localAgentHostPort", rc.LocalAgentHostPort)
+	v.checkTLS(path+".tls", rc.TLS)
+	if (rc.User == "") != (rc.Password == "") {
+		v.add(path, "user and password must be specified together")
+	}
+	v.checkAuthToken(path, rc.AuthToken, rc.AuthTokenFile)
+	if rc.User != "" && (rc.AuthToken != "" || rc.AuthTokenFile != "") {
+		v.add(path, "user and password cannot be combined with a bearer token")
+	}
+	if rc.QueueSize < 0 {
+		v.add(path+".queueSize", "must not be negative, received %d", rc.QueueSize)
+	}
+	v.checkNotNegative(path+".bufferFlushInterval", rc.BufferFlushInterval)
+	v.checkNotNegative(path+".attemptReconnectInterval", rc.AttemptReconnectInterval)
+}
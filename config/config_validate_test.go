@@ -0,0 +1,208 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/jaeger-client-go/x"
+)
+
+func TestValidateValidConfiguration(t *testing.T) {
+	cfg := Configuration{
+		ServiceName: "svc",
+		Sampler: &SamplerConfig{
+			Type:              "remote",
+			Param:             0.1,
+			SamplingServerURL: "http://localhost:5778/sampling",
+		},
+		Reporter: &ReporterConfig{
+			CollectorEndpoint: "https://collector:14268/api/traces",
+			User:              "user",
+			Password:          "password",
+		},
+		BaggageRestrictions: &BaggageRestrictionsConfig{HostPort: "localhost:5778"},
+		Throttler:           &ThrottlerConfig{HostPort: "localhost:5778"},
+	}
+	assert.NoError(t, cfg.Validate())
+	assert.NoError(t, Configuration{Disabled: true}.Validate())
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Configuration
+		problems []FieldProblem
+	}{
+		{
+			name:   "no service name",
+			config: Configuration{},
+			problems: []FieldProblem{
+				{Field: "serviceName", Message: "no service name provided"},
+			},
+		},
+		{
+			name:   "limits",
+			config: Configuration{ServiceName: "svc", MaxTagValueLength: -1, MaxLogsPerSpan: -2},
+			problems: []FieldProblem{
+				{Field: "maxTagValueLength", Message: "must not be negative, received -1"},
+				{Field: "maxLogsPerSpan", Message: "must not be negative, received -2"},
+			},
+		},
+		{
+			name: "span limits",
+			config: Configuration{
+				ServiceName:          "svc",
+				MaxTagsPerSpan:       -1,
+				MaxReferencesPerSpan: -2,
+				MaxLinksPerSpan:      -5,
+				MaxFieldsPerLog:      -3,
+				MaxSpanSize:          -4,
+			},
+			problems: []FieldProblem{
+				{Field: "maxTagsPerSpan", Message: "must not be negative, received -1"},
+				{Field: "maxReferencesPerSpan", Message: "must not be negative, received -2"},
+				{Field: "maxLinksPerSpan", Message: "must not be negative, received -5"},
+				{Field: "maxFieldsPerLog", Message: "must not be negative, received -3"},
+				{Field: "maxSpanSize", Message: "must not be negative, received -4"},
+			},
+		},
+		{
+			name: "sampler",
+			config: Configuration{
+				ServiceName: "svc",
+				Sampler: &SamplerConfig{
+					Type:                    "probabilistic",
+					Param:                   5,
+					SamplingServerURL:       "localhost:5778",
+					SamplingRefreshInterval: -time.Second,
+					MaxOperations:           -1,
+				},
+			},
+			problems: []FieldProblem{
+				{Field: "sampler.param", Message: "expecting a probability between 0 and 1, received 5"},
+				{Field: "sampler.samplingServerURL", Message: `expecting an http or https URL, received "localhost:5778"`},
+				{Field: "sampler.samplingRefreshInterval", Message: "must not be negative, received -1s"},
+				{Field: "sampler.maxOperations", Message: "must not be negative, received -1"},
+			},
+		},
+		{
+			name: "sampler tree",
+			config: Configuration{
+				ServiceName: "svc",
+				Sampler: &SamplerConfig{
+					Type: "priority",
+					Delegates: []SamplerConfig{
+						{Type: "tagMatching", TagKey: "k"},
+						{Type: "rules", Rules: []x.SamplingRule{{Action: x.RuleActionSample}}},
+						{Type: "rateLimiting", Param: -1, TagKey: "k"},
+						{Type: "bogus"},
+						{Type: "priority"},
+					},
+				},
+			},
+			problems: []FieldProblem{
+				{Field: "sampler.delegates[0]", Message: "tagMatching sampler requires at least one tag matcher"},
+				{Field: "sampler.delegates[1]", Message: "rules[0]: no conditions"},
+				{
+					Field:   "sampler.delegates[2].param",
+					Message: "expecting a non-negative number of traces per second, received -1",
+				},
+				{
+					Field:   "sampler.delegates[2]",
+					Message: `tagKey and tagMatchers can only be used with the "tagMatching" sampler`,
+				},
+				{Field: "sampler.delegates[3].type", Message: "unknown sampler type (bogus)"},
+				{Field: "sampler.delegates[4].delegates", Message: "priority sampler requires at least one delegate"},
+			},
+		},
+		{
+			name: "reporter",
+			config: Configuration{
+				ServiceName: "svc",
+				Reporter: &ReporterConfig{
+					CollectorEndpoint:        "http://",
+					LocalAgentHostPort:       "localhost",
+					User:                     "user",
+					QueueSize:                -1,
+					BufferFlushInterval:      -time.Second,
+					AttemptReconnectInterval: -time.Second,
+				},
+			},
+			problems: []FieldProblem{
+				{
+					Field:   "reporter",
+					Message: `only one of collectorEndpoint ("http://") and localAgentHostPort ("localhost") can be specified`,
+				},
+				{Field: "reporter.collectorEndpoint", Message: `missing host in URL "http://"`},
+				{Field: "reporter.localAgentHostPort", Message: `expecting host:port, received "localhost"`},
+				{Field: "reporter", Message: "user and password must be specified together"},
+				{Field: "reporter.queueSize", Message: "must not be negative, received -1"},
+				{Field: "reporter.bufferFlushInterval", Message: "must not be negative, received -1s"},
+				{Field: "reporter.attemptReconnectInterval", Message: "must not be negative, received -1s"},
+			},
+		},
+		{
+			name: "baggage restrictions and throttler",
+			config: Configuration{
+				ServiceName:         "svc",
+				BaggageRestrictions: &BaggageRestrictionsConfig{HostPort: "agent", RefreshInterval: -time.Second},
+				Throttler:           &ThrottlerConfig{HostPort: "agent", RefreshInterval: -time.Second},
+			},
+			problems: []FieldProblem{
+				{Field: "baggage_restrictions.hostPort", Message: `expecting host:port, received "agent"`},
+				{Field: "baggage_restrictions.refreshInterval", Message: "must not be negative, received -1s"},
+				{Field: "throttler.hostPort", Message: `expecting host:port, received "agent"`},
+				{Field: "throttler.refreshInterval", Message: "must not be negative, received -1s"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.config.Validate()
+			require.Error(t, err)
+			validationErr, ok := err.(*ValidationError)
+			require.True(t, ok, "expecting *ValidationError, got %T", err)
+			assert.Equal(t, test.problems, validationErr.Problems)
+		})
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := &ValidationError{Problems: []FieldProblem{
+		{Field: "sampler.param", Message: "bad"},
+		{Field: "reporter.queueSize", Message: "worse"},
+	}}
+	assert.Equal(t, "invalid configuration: sampler.param: bad; reporter.queueSize: worse", err.Error())
+}
+
+func TestNewTracerValidatesConfiguration(t *testing.T) {
+	cfg := Configuration{
+		ServiceName: "svc",
+		Sampler:     &SamplerConfig{Type: "const", Param: 1},
+		Reporter: &ReporterConfig{
+			CollectorEndpoint:  "http://collector:14268/api/traces",
+			LocalAgentHostPort: "localhost:6831",
+		},
+	}
+	_, _, err := cfg.NewTracer()
+	require.Error(t, err)
+	assert.IsType(t, &ValidationError{}, err)
+	assert.Contains(t, err.Error(), "reporter: only one of collectorEndpoint")
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+)
+
+// envVarDoc describes an environment variable understood by FromEnv().
+type envVarDoc struct {
+	name        string
+	description string
+}
+
+// envVarDocs is the reference of all environment variables understood by FromEnv(),
+// from which the tables in README.md are generated with `make generate-env-docs`.
+var envVarDocs = []envVarDoc{
+	{envServiceName, "The service name."},
+	{envAgentHost, "The hostname for communicating with agent via UDP (default `localhost`)."},
+	{envAgentPort, "The port for communicating with agent via UDP (default `6831`)."},
+	{envEndpoint, "The HTTP endpoint for sending spans directly to a collector, i.e. http://jaeger-collector:14268/api/traces. If specified, the agent host/port are ignored. Other URL schemes are supported by the transports registered via `config.RegisterTransport`."},
+	{envEndpoints, "A comma separated list of collector endpoints with the same scheme, in addition to `JAEGER_ENDPOINT`, across which the spans are balanced, with failover."},
+	{envEndpointSRV, "The name of a DNS SRV record resolved into the collector endpoints, e.g. `_jaeger-collector._tcp.example.com`. The URLs of the endpoints are built from `JAEGER_ENDPOINT`, replacing its host with the target and port of each record."},
+	{envUser, "Username to send as part of \"Basic\" authentication to the collector endpoint."},
+	{envPassword, "Password to send as part of \"Basic\" authentication to the collector endpoint."},
+	{envAuthToken, "A bearer token to send in the `Authorization` header to the collector endpoint."},
+	{envAuthTokenFile, "The path of a file containing a bearer token to send to the collector endpoint, read again when the file changes, e.g. a projected service account token."},
+	{envReporterHTTPHeaders, "A comma separated list of `name=value` HTTP headers sent with every request to the collector endpoint."},
+	{envReporterTransportOptions, "A comma separated list of `name=value` options passed to the transport registered for the scheme of `JAEGER_ENDPOINT`, see `config.RegisterTransport`."},
+	{envReporterTLSCA, "The path of a PEM bundle of the CAs used to verify the certificate of the collector, instead of the system CAs."},
+	{envReporterTLSCert, "The path of the PEM encoded client certificate presented to the collector for mutual TLS, with `JAEGER_REPORTER_TLS_KEY`."},
+	{envReporterTLSKey, "The path of the PEM encoded key of the client certificate."},
+	{envReporterTLSServerName, "Overrides the host name used to verify the certificate of the collector."},
+	{envReporterTLSMinVersion, "The minimum TLS version accepted by the client: `1.0`, `1.1`, `1.2` or `1.3`."},
+	{envReporterTLSSkipHostVerify, "Whether to skip the verification of the certificate of the collector, `true` or `false` (default `false`)."},
+	{envReporterLogSpans, "Whether the reporter should also log the spans, `true` or `false` (default `false`)."},
+	{envReporterMaxQueueSize, "The reporter's maximum queue size (default `100`)."},
+	{envReporterFlushInterval, "The reporter's flush interval, with units, e.g. `500ms` or `2s` ([valid units][timeunits]; default `1s`)."},
+	{envReporterAttemptReconnectingDisabled, "When true, disables udp connection helper that periodically re-resolves the agent's hostname and reconnects if there was a change (default `false`)."},
+	{envReporterAttemptReconnectInterval, "Controls how often the agent client re-resolves the provided hostname in order to detect address changes ([valid units][timeunits]; default `30s`)."},
+	{envSamplerType, "The sampler type: `remote`, `const`, `probabilistic`, `ratelimiting`, `priority`, `tagMatching`, `rules` (default `remote`). See also https://www.jaegertracing.io/docs/latest/sampling/."},
+	{envSamplerParam, "The sampler parameter (number)."},
+	{envSamplerManagerHostPort, "(deprecated) The HTTP endpoint when using the `remote` sampler."},
+	{envSamplingEndpoint, "The URL for the sampling configuration server when using sampler type `remote` (default `http://127.0.0.1:5778/sampling`)."},
+	{envSamplerMaxOperations, "The maximum number of operations that the sampler will keep track of (default `2000`)."},
+	{envSamplerRefreshInterval, "How often the `remote` sampler should poll the configuration server for the appropriate sampling strategy, e.g. \"1m\" or \"30s\" ([valid units][timeunits]; default `1m`)."},
+	{envSamplerDelegates, "A JSON or YAML list of sampler configurations consulted in order by the `priority` sampler, e.g. `[{\"type\":\"tagMatching\",\"tagKey\":\"debug\",\"tagMatchers\":[{\"value\":true}]},{\"type\":\"remote\"}]`."},
+	{envSamplerAuthToken, "A bearer token to send in the `Authorization` header to the sampling server."},
+	{envSamplerAuthTokenFile, "The path of a file containing a bearer token to send to the sampling server, read again when the file changes."},
+	{envSamplerTLSCA, "The path of a PEM bundle of the CAs used to verify the certificate of the sampling server, instead of the system CAs."},
+	{envSamplerTLSCert, "The path of the PEM encoded client certificate presented to the sampling server for mutual TLS, with `JAEGER_SAMPLER_TLS_KEY`."},
+	{envSamplerTLSKey, "The path of the PEM encoded key of the client certificate."},
+	{envSamplerTLSServerName, "Overrides the host name used to verify the certificate of the sampling server."},
+	{envSamplerTLSMinVersion, "The minimum TLS version accepted by the client: `1.0`, `1.1`, `1.2` or `1.3`."},
+	{envSamplerTLSSkipHostVerify, "Whether to skip the verification of the certificate of the sampling server, `true` or `false` (default `false`)."},
+	{envTags, "A comma separated list of `name=value` tracer-level tags, which get added to all reported spans. The value can also refer to an environment variable using the format `${envVarName:defaultValue}`."},
+	{envResourceDetectors, "A comma separated list of resource detectors whose tags are added to the tracer-level tags: `container`, `kubernetes`, `go`, `process`, `build`."},
+	{env128bit, "Whether to enable 128bit trace-id generation, `true` or `false`. If not enabled, the SDK defaults to 64bit trace-ids."},
+	{envDisabled, "Whether the tracer is disabled or not. If `true`, the `opentracing.NoopTracer` is used (default `false`)."},
+	{envRPCMetrics, "Whether to store RPC metrics, `true` or `false` (default `false`)."},
+	{envMaxTagValueLength, "The maximum length of string tag values, longer values are truncated (default `256`)."},
+	{envMaxLogsPerSpan, "The maximum number of logs kept per span, the oldest and newest logs are kept when the limit is exceeded (default unlimited)."},
+	{envMaxTagsPerSpan, "The maximum number of tags per span, new tag keys are dropped when the limit is reached (default unlimited)."},
+	{envMaxReferencesPerSpan, "The maximum number of references per span, the extra references are dropped (default unlimited)."},
+	{envMaxLinksPerSpan, "The maximum number of links added to a span after it started, the extra links are dropped (default unlimited)."},
+	{envMaxFieldsPerLog, "The maximum number of fields per span log, the extra fields are dropped (default unlimited)."},
+	{envMaxSpanSize, "The maximum serialized size of a span in bytes, the newest logs and then tags are dropped to fit (default unlimited)."},
+	{envPoolSpans, "Whether span objects are pooled and reused, `true` or `false` (default `false`)."},
+	{envPprofLabels, "Whether the goroutines of sampled local root spans get the `trace_id`, `span_id` and `operation` pprof labels, `true` or `false` (default `false`)."},
+	{envRecordPanics, "Whether a deferred `Span.Finish` records a panic on the span and reports it synchronously before propagating the panic, `true` or `false` (default `false`)."},
+	{envZipkinSharedRPCSpan, "Whether client and server spans of an RPC share the same span ID, as in Zipkin, `true` or `false` (default `false`)."},
+	{envNoDebugFlagOnForcedSampling, "Whether the debug flag is left unset on traces sampled via the `sampling.priority` tag, `true` or `false` (default `false`)."},
+	{envHeadersDebug, "The HTTP header used to force sampling of a trace with a correlation ID (default `jaeger-debug-id`)."},
+	{envHeadersBaggage, "The HTTP header used to submit baggage items without a trace (default `jaeger-baggage`)."},
+	{envHeadersTraceContext, "The HTTP header used to propagate the span context (default `uber-trace-id`)."},
+	{envHeadersBaggagePrefix, "The prefix of the HTTP headers used to propagate baggage items (default `uberctx-`)."},
+	{envBaggageRestrictionsDenyOnInitFail, "Whether all baggage is denied until the baggage restrictions are retrieved from the agent, `true` or `false` (default `false`)."},
+	{envBaggageRestrictionsHostPort, "The `host:port` of the agent serving baggage restrictions (default `localhost:5778`)."},
+	{envBaggageRestrictionsRefreshInterval, "How often the baggage restrictions are refreshed ([valid units][timeunits]; default `1m`)."},
+	{envThrottlerHostPort, "The `host:port` of the agent serving debug span throttling credits (default `localhost:5778`)."},
+	{envThrottlerRefreshInterval, "How often the throttler fetches credits from the agent ([valid units][timeunits]; default `5s`)."},
+	{envThrottlerSynchronousInitialization, "Whether the throttler fetches credits synchronously when the first span is started, `true` or `false` (default `false`)."},
+	{envOTELServiceName, "The service name, used if `JAEGER_SERVICE_NAME` is not set."},
+	{envOTELResourceAttributes, "A comma separated list of `name=value` attributes (percent-encoded values) added as tracer-level tags. `service.name` is used as the service name if neither service name variable is set. Tags from `JAEGER_TAGS` replace attributes with the same name."},
+	{envOTELTracesSampler, "The sampler: `always_on` and `always_off` (`const` sampler), `traceidratio` (`probabilistic` sampler), `jaeger_remote` (`remote` sampler), or their `parentbased_` variants, which behave the same since Jaeger samplers always respect the parent's decision. `JAEGER_SAMPLER_*` variables override the resulting settings."},
+	{envOTELTracesSamplerArg, "The sampling ratio for `traceidratio` (default `1.0`), or `endpoint=...,pollingIntervalMs=...,initialSamplingRate=...` for `jaeger_remote`."},
+	{envOTELPropagators, "A comma separated list of propagators: `jaeger`, `b3multi` or `none`. Other propagators, such as `tracecontext`, are not supported and are ignored."},
+	{envOTELExporterOTLPEndpoint, "The OTLP endpoint, see `OTEL_EXPORTER_OTLP_TRACES_ENDPOINT`."},
+	{envOTELExporterOTLPTracesEndpoint, "The OTLP traces endpoint, used as is, while `OTEL_EXPORTER_OTLP_ENDPOINT` gets `/v1/traces` appended. It is used as the collector endpoint with the `otlp+` scheme prefix if none of `JAEGER_ENDPOINT`, `JAEGER_ENDPOINTS`, `JAEGER_AGENT_HOST` and `JAEGER_AGENT_PORT` are set. The built-in transports do not support OTLP, a transport must be registered for the `otlp+http` or `otlp+https` scheme via `config.RegisterTransport`, otherwise the endpoint is ignored, with an error logged by `NewTracer`, and the spans are sent to the agent."},
+}
+
+// envVarsTable renders the reference of the environment variables whose names
+// start with the given prefix as a markdown table.
+func envVarsTable(prefix string) string {
+	var sb strings.Builder
+	sb.WriteString("Property| Description\n--- | ---\n")
+	for _, doc := range envVarDocs {
+		if strings.HasPrefix(doc.name, prefix) {
+			sb.WriteString(doc.name + " | " + doc.description + "\n")
+		}
+	}
+	return sb.String()
+}
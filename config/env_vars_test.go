@@ -0,0 +1,103 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateEnvDocs = flag.Bool("update-env-docs", false, "regenerate the environment variables tables in README.md")
+
+const readmePath = "../README.md"
+
+// envVarSections maps the markers delimiting the generated tables in README.md
+// to the prefix of the environment variables listed in them.
+var envVarSections = []struct {
+	marker string
+	prefix string
+}{
+	{marker: "jaeger-env-vars", prefix: "JAEGER_"},
+	{marker: "otel-env-vars", prefix: "OTEL_"},
+}
+
+func TestEnvVarDocsInREADME(t *testing.T) {
+	data, err := ioutil.ReadFile(readmePath)
+	require.NoError(t, err)
+	readme := string(data)
+
+	for _, section := range envVarSections {
+		start := "<!-- " + section.marker + ":start -->\n"
+		end := "<!-- " + section.marker + ":end -->"
+		i := strings.Index(readme, start)
+		j := strings.Index(readme, end)
+		require.True(t, i >= 0 && j > i, "README.md must contain the %s markers", section.marker)
+
+		table := envVarsTable(section.prefix)
+		if *updateEnvDocs {
+			readme = readme[:i+len(start)] + table + readme[j:]
+			continue
+		}
+		assert.Equal(t, table, readme[i+len(start):j],
+			"README.md is out of date, run `make generate-env-docs`")
+	}
+
+	if *updateEnvDocs {
+		require.NoError(t, ioutil.WriteFile(readmePath, []byte(readme), 0644))
+	}
+}
+
+func TestEnvVarDocsComplete(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "config_env.go", nil, 0)
+	require.NoError(t, err)
+
+	declared := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		lit, ok := n.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		if value, err := strconv.Unquote(lit.Value); err == nil && isEnvVarName(value) {
+			declared[value] = true
+		}
+		return true
+	})
+
+	documented := make(map[string]bool)
+	for _, doc := range envVarDocs {
+		assert.False(t, documented[doc.name], "%s is documented more than once", doc.name)
+		documented[doc.name] = true
+		assert.True(t, declared[doc.name], "%s is documented but not used by FromEnv()", doc.name)
+	}
+	for name := range declared {
+		assert.True(t, documented[name], "%s is not documented in envVarDocs", name)
+	}
+}
+
+func isEnvVarName(s string) bool {
+	if !strings.HasPrefix(s, "JAEGER_") && !strings.HasPrefix(s, "OTEL_") {
+		return false
+	}
+	return strings.Trim(s, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_") == ""
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/uber/jaeger-lib/metrics"
 
 	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/resource"
 )
 
 // Option is a function that sets some option on the client.
@@ -34,10 +35,19 @@ type Options struct {
 	observers                   []jaeger.Observer
 	gen128Bit                   bool
 	poolSpans                   bool
+	pprofLabels                 bool
+	recordPanics                bool
 	zipkinSharedRPCSpan         bool
 	maxTagValueLength           int
+	maxLogsPerSpan              int
+	maxTagsPerSpan              int
+	maxReferencesPerSpan        int
+	maxLinksPerSpan             int
+	maxFieldsPerLog             int
+	maxSpanSize                 int
 	noDebugFlagOnForcedSampling bool
 	tags                        []opentracing.Tag
+	resourceDetectors           []resource.Detector
 	injectors                   map[interface{}]jaeger.Injector
 	extractors                  map[interface{}]jaeger.Extractor
 	randomNumber                func() uint64
@@ -103,6 +113,22 @@ func PoolSpans(poolSpans bool) Option {
 	}
 }
 
+// PprofLabels specifies whether to set pprof labels identifying the sampled local
+// root spans on their goroutines.
+func PprofLabels(pprofLabels bool) Option {
+	return func(c *Options) {
+		c.pprofLabels = pprofLabels
+	}
+}
+
+// RecordPanics specifies whether a deferred Span.Finish records a panic on the span
+// and reports it synchronously before propagating the panic.
+func RecordPanics(recordPanics bool) Option {
+	return func(c *Options) {
+		c.recordPanics = recordPanics
+	}
+}
+
 // ZipkinSharedRPCSpan creates an option that enables sharing span ID between client
 // and server spans a la zipkin. If false, client and server spans will be assigned
 // different IDs.
@@ -119,6 +145,54 @@ func MaxTagValueLength(maxTagValueLength int) Option {
 	}
 }
 
+// MaxLogsPerSpan can be provided to override the maximum number of logs per span.
+// It takes precedence over Configuration.MaxLogsPerSpan.
+func MaxLogsPerSpan(maxLogsPerSpan int) Option {
+	return func(c *Options) {
+		c.maxLogsPerSpan = maxLogsPerSpan
+	}
+}
+
+// MaxTagsPerSpan can be provided to override the maximum number of tags per span.
+// It takes precedence over Configuration.MaxTagsPerSpan.
+func MaxTagsPerSpan(maxTagsPerSpan int) Option {
+	return func(c *Options) {
+		c.maxTagsPerSpan = maxTagsPerSpan
+	}
+}
+
+// MaxReferencesPerSpan can be provided to override the maximum number of references per span.
+// It takes precedence over Configuration.MaxReferencesPerSpan.
+func MaxReferencesPerSpan(maxReferencesPerSpan int) Option {
+	return func(c *Options) {
+		c.maxReferencesPerSpan = maxReferencesPerSpan
+	}
+}
+
+// MaxLinksPerSpan can be provided to override the maximum number of links per span.
+// It takes precedence over Configuration.MaxLinksPerSpan.
+func MaxLinksPerSpan(maxLinksPerSpan int) Option {
+	return func(c *Options) {
+		c.maxLinksPerSpan = maxLinksPerSpan
+	}
+}
+
+// MaxFieldsPerLog can be provided to override the maximum number of fields per span log.
+// It takes precedence over Configuration.MaxFieldsPerLog.
+func MaxFieldsPerLog(maxFieldsPerLog int) Option {
+	return func(c *Options) {
+		c.maxFieldsPerLog = maxFieldsPerLog
+	}
+}
+
+// MaxSpanSize can be provided to override the maximum serialized size of spans in bytes.
+// It takes precedence over Configuration.MaxSpanSize.
+func MaxSpanSize(maxSpanSize int) Option {
+	return func(c *Options) {
+		c.maxSpanSize = maxSpanSize
+	}
+}
+
 // NoDebugFlagOnForcedSampling can be used to decide whether debug flag will be set or not
 // when calling span.setSamplingPriority to force sample a span.
 func NoDebugFlagOnForcedSampling(noDebugFlagOnForcedSampling bool) Option {
@@ -134,6 +208,14 @@ func Tag(key string, value interface{}) Option {
 	}
 }
 
+// ResourceDetectors creates an option that adds the tags detected by the given detectors
+// to the tracer-level tags, after the ones of Configuration.ResourceDetectors.
+func ResourceDetectors(detectors ...resource.Detector) Option {
+	return func(c *Options) {
+		c.resourceDetectors = append(c.resourceDetectors, detectors...)
+	}
+}
+
 // Injector registers an Injector with the given format.
 func Injector(format interface{}, injector jaeger.Injector) Option {
 	return func(c *Options) {
@@ -0,0 +1,129 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/zipkin"
+)
+
+// Propagator names accepted in Configuration.Propagators, as defined for OTEL_PROPAGATORS.
+const (
+	propagatorJaeger  = "jaeger"
+	propagatorB3Multi = "b3multi"
+	propagatorNone    = "none"
+)
+
+// knownPropagators lists the propagators defined by the OpenTelemetry specification,
+// mapped to whether they are supported by this client.
+var knownPropagators = map[string]bool{
+	propagatorJaeger:  true,
+	propagatorB3Multi: true,
+	propagatorNone:    true,
+	"tracecontext":    false,
+	"baggage":         false,
+	"b3":              false,
+	"xray":            false,
+	"ottrace":         false,
+}
+
+// propagatorOptions returns the tracer options that register the injectors and extractors
+// for the TextMap and HTTPHeaders formats according to c.Propagators. Unsupported
+// propagators are ignored with a log message. If none of the listed propagators are
+// supported, the tracer keeps its default Jaeger propagation.
+func (c Configuration) propagatorOptions(metrics *jaeger.Metrics, logger jaeger.Logger) []jaeger.TracerOption {
+	if len(c.Propagators) == 0 {
+		return nil
+	}
+	headers := &jaeger.HeadersConfig{}
+	if c.Headers != nil {
+		h := *c.Headers
+		headers = &h
+	}
+	headers.ApplyDefaults()
+
+	var textMap, httpHeaders []propagator
+	supported := false
+	for _, name := range c.Propagators {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case propagatorJaeger:
+			textMap = append(textMap, jaeger.NewTextMapPropagator(headers, *metrics))
+			httpHeaders = append(httpHeaders, jaeger.NewHTTPHeaderPropagator(headers, *metrics))
+		case propagatorB3Multi:
+			b3 := zipkin.NewZipkinB3HTTPHeaderPropagator()
+			textMap = append(textMap, b3)
+			httpHeaders = append(httpHeaders, b3)
+		case propagatorNone:
+		default:
+			logger.Infof("Ignoring unsupported propagator %q\n", name)
+			continue
+		}
+		supported = true
+	}
+	if !supported {
+		return nil
+	}
+
+	textMapPropagator := newCompositePropagator(textMap)
+	httpHeadersPropagator := newCompositePropagator(httpHeaders)
+	return []jaeger.TracerOption{
+		jaeger.TracerOptions.Injector(opentracing.TextMap, textMapPropagator),
+		jaeger.TracerOptions.Extractor(opentracing.TextMap, textMapPropagator),
+		jaeger.TracerOptions.Injector(opentracing.HTTPHeaders, httpHeadersPropagator),
+		jaeger.TracerOptions.Extractor(opentracing.HTTPHeaders, httpHeadersPropagator),
+	}
+}
+
+type propagator interface {
+	jaeger.Injector
+	jaeger.Extractor
+}
+
+// compositePropagator injects the span context with all of its propagators, and
+// extracts it with the first propagator that finds one in the carrier.
+type compositePropagator struct {
+	propagators []propagator
+}
+
+func newCompositePropagator(propagators []propagator) propagator {
+	if len(propagators) == 1 {
+		return propagators[0]
+	}
+	return &compositePropagator{propagators: propagators}
+}
+
+func (p *compositePropagator) Inject(ctx jaeger.SpanContext, carrier interface{}) error {
+	for _, propagator := range p.propagators {
+		if err := propagator.Inject(ctx, carrier); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *compositePropagator) Extract(carrier interface{}) (jaeger.SpanContext, error) {
+	for _, propagator := range p.propagators {
+		ctx, err := propagator.Extract(carrier)
+		if err == opentracing.ErrSpanContextNotFound {
+			continue
+		}
+		return ctx, err
+	}
+	return jaeger.SpanContext{}, opentracing.ErrSpanContextNotFound
+}
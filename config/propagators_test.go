@@ -0,0 +1,101 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/jaeger-client-go"
+)
+
+func newTracerWithPropagators(t *testing.T, propagators ...string) (opentracing.Tracer, func()) {
+	cfg := Configuration{
+		ServiceName: "svc",
+		Sampler:     &SamplerConfig{Type: "const", Param: 1},
+		Propagators: propagators,
+	}
+	tracer, closer, err := cfg.NewTracer(Reporter(jaeger.NewNullReporter()))
+	require.NoError(t, err)
+	return tracer, func() { closeCloser(t, closer) }
+}
+
+func TestPropagators(t *testing.T) {
+	tracer, closeTracer := newTracerWithPropagators(t, "b3multi", "jaeger")
+	defer closeTracer()
+
+	span := tracer.StartSpan("op")
+	defer span.Finish()
+
+	headers := http.Header{}
+	carrier := opentracing.HTTPHeadersCarrier(headers)
+	require.NoError(t, tracer.Inject(span.Context(), opentracing.HTTPHeaders, carrier))
+	assert.NotEmpty(t, headers.Get("x-b3-traceid"))
+	assert.NotEmpty(t, headers.Get(jaeger.TraceContextHeaderName))
+
+	// extracted with the first propagator that finds the context
+	headers.Del(jaeger.TraceContextHeaderName)
+	ctx, err := tracer.Extract(opentracing.HTTPHeaders, carrier)
+	require.NoError(t, err)
+	assert.Equal(t, span.Context().(jaeger.SpanContext).TraceID(), ctx.(jaeger.SpanContext).TraceID())
+
+	textMap := opentracing.TextMapCarrier{}
+	require.NoError(t, tracer.Inject(span.Context(), opentracing.TextMap, textMap))
+	assert.Contains(t, textMap, "x-b3-traceid")
+	assert.Contains(t, textMap, jaeger.TraceContextHeaderName)
+
+	_, err = tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(http.Header{}))
+	assert.Equal(t, opentracing.ErrSpanContextNotFound, err)
+}
+
+func TestPropagatorsNone(t *testing.T) {
+	tracer, closeTracer := newTracerWithPropagators(t, "none")
+	defer closeTracer()
+
+	span := tracer.StartSpan("op")
+	defer span.Finish()
+
+	headers := http.Header{}
+	carrier := opentracing.HTTPHeadersCarrier(headers)
+	require.NoError(t, tracer.Inject(span.Context(), opentracing.HTTPHeaders, carrier))
+	assert.Empty(t, headers)
+
+	headers.Set(jaeger.TraceContextHeaderName, "1:2:0:1")
+	_, err := tracer.Extract(opentracing.HTTPHeaders, carrier)
+	assert.Equal(t, opentracing.ErrSpanContextNotFound, err)
+}
+
+func TestPropagatorsUnsupported(t *testing.T) {
+	// unsupported propagators are ignored and the default Jaeger propagation is kept
+	tracer, closeTracer := newTracerWithPropagators(t, "tracecontext", "baggage")
+	defer closeTracer()
+
+	span := tracer.StartSpan("op")
+	defer span.Finish()
+
+	headers := http.Header{}
+	require.NoError(t, tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(headers)))
+	assert.NotEmpty(t, headers.Get(jaeger.TraceContextHeaderName))
+}
+
+func TestPropagatorsValidation(t *testing.T) {
+	err := Configuration{ServiceName: "svc", Propagators: []string{"jaeger", "carrier-pigeon"}}.Validate()
+	require.Error(t, err)
+	assert.Equal(t, `invalid configuration: propagators[1]: unknown propagator "carrier-pigeon"`, err.Error())
+}
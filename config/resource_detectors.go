@@ -0,0 +1,54 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/uber/jaeger-client-go/resource"
+)
+
+// knownResourceDetectors maps the names accepted in Configuration.ResourceDetectors
+// to the constructors of the built-in detectors.
+var knownResourceDetectors = map[string]func() resource.Detector{
+	"container":  resource.Container,
+	"kubernetes": resource.Kubernetes,
+	"go":         resource.GoRuntime,
+	"process":    resource.Process,
+	"build":      resource.BuildInfo,
+}
+
+// detectResourceTags runs the detectors named in c.ResourceDetectors followed by the ones
+// passed via the ResourceDetectors option. Detection errors are logged, since the tags
+// detected so far are still worth reporting.
+func (c Configuration) detectResourceTags(opts Options) []opentracing.Tag {
+	var detectors []resource.Detector
+	for _, name := range c.ResourceDetectors {
+		if newDetector, ok := knownResourceDetectors[strings.ToLower(strings.TrimSpace(name))]; ok {
+			detectors = append(detectors, newDetector())
+		}
+	}
+	detectors = append(detectors, opts.resourceDetectors...)
+	if len(detectors) == 0 {
+		return nil
+	}
+	tags, err := resource.Detect(detectors...)
+	if err != nil {
+		opts.logger.Error("Cannot detect all resource tags: " + err.Error())
+	}
+	return tags
+}
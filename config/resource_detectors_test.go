@@ -0,0 +1,72 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/log"
+	"github.com/uber/jaeger-client-go/resource"
+)
+
+func TestResourceDetectors(t *testing.T) {
+	cfg := Configuration{
+		ServiceName:       "svc",
+		ResourceDetectors: []string{"go", " Process "},
+		Tags:              []opentracing.Tag{{Key: resource.ProcessPIDTagKey, Value: "explicit"}},
+	}
+	logger := &log.BytesBufferLogger{}
+	custom := resource.DetectorFunc(func() ([]opentracing.Tag, error) {
+		return []opentracing.Tag{{Key: "custom", Value: "value"}}, errors.New("custom detection failed")
+	})
+	tracer, closer, err := cfg.NewTracer(
+		Reporter(jaeger.NewNullReporter()),
+		ResourceDetectors(custom),
+		Logger(logger),
+	)
+	require.NoError(t, err)
+	defer closeCloser(t, closer)
+
+	assert.Equal(t, "go", tracerTagValue(tracer, resource.RuntimeNameTagKey))
+	assert.Equal(t, runtime.Version(), tracerTagValue(tracer, resource.RuntimeVersionTagKey))
+	assert.NotNil(t, tracerTagValue(tracer, resource.ProcessExecutableNameTagKey))
+	assert.Equal(t, "explicit", tracerTagValue(tracer, resource.ProcessPIDTagKey))
+	assert.Equal(t, "value", tracerTagValue(tracer, "custom"))
+	assert.Contains(t, logger.String(), "Cannot detect all resource tags: custom detection failed")
+}
+
+func TestResourceDetectorsValidation(t *testing.T) {
+	err := Configuration{ServiceName: "svc", ResourceDetectors: []string{"container", "aws"}}.Validate()
+	require.Error(t, err)
+	assert.Equal(t, []FieldProblem{
+		{Field: "resourceDetectors[1]", Message: `unknown resource detector "aws"`},
+	}, err.(*ValidationError).Problems)
+}
+
+func TestResourceDetectorsFromEnv(t *testing.T) {
+	setEnv(t, envResourceDetectors, "container, kubernetes,,build")
+	defer unsetEnv(t, envResourceDetectors)
+
+	cfg, err := FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"container", "kubernetes", "build"}, cfg.ResourceDetectors)
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// tlsVersions maps the accepted values of TLSConfig.MinVersion to the tls package constants.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSConfig configures the TLS connections to a collector or a sampling server.
+// The certificate files are checked for changes at every TLS handshake and reloaded
+// when they are modified, so that rotated certificates are used without a restart.
+// If reloading fails, e.g. while the files are being rewritten, the previously loaded
+// certificates are kept.
+type TLSConfig struct {
+	// CAFile is the path of a PEM bundle of the CAs used to verify the server certificate,
+	// instead of the system CAs.
+	CAFile string `yaml:"caFile"`
+
+	// CertFile and KeyFile are the paths of the PEM encoded client certificate and key
+	// presented to the server for mutual TLS.
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+
+	// ServerName overrides the host name used to verify the server certificate.
+	ServerName string `yaml:"serverName"`
+
+	// MinVersion is the minimum TLS version accepted, one of "1.0", "1.1", "1.2" or "1.3".
+	MinVersion string `yaml:"minVersion"`
+
+	// InsecureSkipVerify disables the verification of the server certificate.
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify"`
+}
+
+// NewTLSConfig creates the *tls.Config described by the configuration.
+//
+// When CAFile is set, the server certificate is verified against the host name sent
+// in the TLS handshake, which is empty for IP address endpoints, so ServerName must
+// then be set for the connections to succeed.
+func (tc *TLSConfig) NewTLSConfig() (*tls.Config, error) {
+	return tc.newTLSConfig("")
+}
+
+// newTLSConfig creates the *tls.Config described by the configuration, for connections
+// to the given host, used to verify the server certificate when the handshake carries
+// no host name, as for IP addresses. The host is empty if several hosts are dialed.
+func (tc *TLSConfig) newTLSConfig(host string) (*tls.Config, error) {
+	if err := tc.validate(); err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         tc.ServerName,
+		MinVersion:         tlsVersions[tc.MinVersion],
+		InsecureSkipVerify: tc.InsecureSkipVerify,
+	}
+	if tc.CAFile != "" && !tc.InsecureSkipVerify {
+		ca := &reloadingFiles{paths: []string{tc.CAFile}, load: loadCertPool}
+		if err := ca.reload(); err != nil {
+			return nil, err
+		}
+		// The server certificate is verified by VerifyConnection against the latest CA bundle,
+		// since RootCAs cannot be replaced once the configuration is in use.
+		tlsConfig.InsecureSkipVerify = true
+		if tc.ServerName != "" {
+			host = tc.ServerName
+		}
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyServerCertificate(state, host, ca.get().(*x509.CertPool))
+		}
+	}
+	if tc.CertFile != "" {
+		cert := &reloadingFiles{paths: []string{tc.CertFile, tc.KeyFile}, load: loadKeyPair}
+		if err := cert.reload(); err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return cert.get().(*tls.Certificate), nil
+		}
+	}
+	return tlsConfig, nil
+}
+
+func (tc *TLSConfig) validate() error {
+	if (tc.CertFile == "") != (tc.KeyFile == "") {
+		return errors.New("certFile and keyFile must be specified together")
+	}
+	if _, ok := tlsVersions[tc.MinVersion]; !ok && tc.MinVersion != "" {
+		return errors.Errorf("unknown TLS version %q, expecting one of 1.0, 1.1, 1.2 or 1.3", tc.MinVersion)
+	}
+	return nil
+}
+
+// verifyServerCertificate verifies the server certificate against the host name sent in
+// the handshake, or the given host if none was sent. It fails if both are empty, since
+// x509 would then accept a certificate issued for any host.
+func verifyServerCertificate(state tls.ConnectionState, host string, roots *x509.CertPool) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("no server certificate received")
+	}
+	serverName := state.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+	if serverName == "" {
+		return errors.New("cannot verify the server certificate without a server name, serverName must be set")
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range state.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+func loadCertPool(paths []string) (interface{}, error) {
+	pem, err := ioutil.ReadFile(paths[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read CA file")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates found in CA file %s", paths[0])
+	}
+	return pool, nil
+}
+
+func loadKeyPair(paths []string) (interface{}, error) {
+	cert, err := tls.LoadX509KeyPair(paths[0], paths[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load client certificate")
+	}
+	return &cert, nil
+}
+
+// reloadingFiles holds the value loaded from a set of files, and loads it again
+// when the modification time or the size of any of the files changes.
+type reloadingFiles struct {
+	paths []string
+	load  func(paths []string) (interface{}, error)
+
+	sync.Mutex
+	states []fileState
+	value  interface{}
+}
+
+// get returns the latest value, reloading the files if they changed.
+func (r *reloadingFiles) get() interface{} {
+	r.Lock()
+	defer r.Unlock()
+	if r.changed() {
+		// errors are ignored to keep the previous value while the files are updated
+		_ = r.reloadLocked()
+	}
+	return r.value
+}
+
+func (r *reloadingFiles) reload() error {
+	r.Lock()
+	defer r.Unlock()
+	return r.reloadLocked()
+}
+
+func (r *reloadingFiles) reloadLocked() error {
+	states := r.currentStates()
+	value, err := r.load(r.paths)
+	if err != nil {
+		return err
+	}
+	r.value = value
+	r.states = states
+	return nil
+}
+
+func (r *reloadingFiles) changed() bool {
+	states := r.currentStates()
+	for i := range states {
+		if !states[i].modTime.Equal(r.states[i].modTime) || states[i].size != r.states[i].size {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *reloadingFiles) currentStates() []fileState {
+	states := make([]fileState, len(r.paths))
+	for i, path := range r.paths {
+		if info, err := os.Stat(path); err == nil {
+			states[i] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return states
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// urlHostname returns the host name of the URL, or an empty string if it cannot be parsed.
+func urlHostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
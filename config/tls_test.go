@@ -0,0 +1,319 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/jaeger-client-go/transport"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (c *testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	require.NoError(t, err)
+	return cert
+}
+
+// newTestCert creates a certificate for 127.0.0.1 signed by parent, or a self-signed CA if parent is nil.
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	return newTestCertForIP(t, name, parent, net.ParseIP("127.0.0.1"))
+}
+
+func newTestCertForIP(t *testing.T, name string, parent *testCert, ip net.IP) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{ip},
+	}
+	signerCert, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// writeFile writes the file and moves its modification time forward, so that
+// the change is detected even on file systems with a coarse time resolution.
+func writeFile(t *testing.T, path string, content []byte) {
+	require.NoError(t, ioutil.WriteFile(path, content, 0600))
+	modTime := time.Now().Add(time.Duration(len(content)) * time.Second)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+// newMTLSServer starts a server that requires client certificates signed by ca.
+func newMTLSServer(t *testing.T, ca *testCert) *httptest.Server {
+	serverCert := newTestCert(t, "server", ca)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.tlsCertificate(t)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	return server
+}
+
+func get(client *http.Client, url string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	return string(body), err
+}
+
+func TestTLSConfigMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jaeger-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "ca", nil)
+	server := newMTLSServer(t, ca)
+	defer server.Close()
+
+	otherCA := newTestCert(t, "other-ca", nil)
+	client1 := newTestCert(t, "client-1", ca)
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	writeFile(t, caFile, otherCA.certPEM)
+	writeFile(t, certFile, client1.certPEM)
+	writeFile(t, keyFile, client1.keyPEM)
+
+	tc := &TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.2"}
+	tlsConfig, err := tc.newTLSConfig("127.0.0.1")
+	require.NoError(t, err)
+	assert.EqualValues(t, tls.VersionTLS12, tlsConfig.MinVersion)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: true}}
+
+	// the server certificate is not signed by the configured CA
+	_, err = get(client, server.URL)
+	require.Error(t, err)
+
+	// the rotated CA bundle is picked up by the next handshake
+	writeFile(t, caFile, append(otherCA.certPEM, ca.certPEM...))
+	name, err := get(client, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "client-1", name)
+
+	// so is the rotated client certificate
+	client2 := newTestCert(t, "client-2", ca)
+	writeFile(t, certFile, client2.certPEM)
+	writeFile(t, keyFile, client2.keyPEM)
+	name, err = get(client, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "client-2", name)
+
+	// the previous certificate is kept while the files are invalid
+	writeFile(t, keyFile, []byte("rotating"))
+	name, err = get(client, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "client-2", name)
+}
+
+func TestTLSConfigVerifiesIPAddress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jaeger-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "ca", nil)
+	caFile := filepath.Join(dir, "ca.pem")
+	writeFile(t, caFile, ca.certPEM)
+	// the certificate is signed by the CA, but for another IP address
+	serverCert := newTestCertForIP(t, "server", ca, net.ParseIP("10.0.0.1"))
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{serverCert.tlsCertificate(t)}}
+	server.StartTLS()
+	defer server.Close()
+
+	tc := &TLSConfig{CAFile: caFile}
+	tlsConfig, err := tc.newTLSConfig("127.0.0.1")
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	_, err = get(client, server.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "127.0.0.1")
+
+	// without the host, the certificate cannot be verified
+	tlsConfig, err = tc.NewTLSConfig()
+	require.NoError(t, err)
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	_, err = get(client, server.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "without a server name")
+
+	// the configured server name takes precedence
+	tc.ServerName = "10.0.0.1"
+	tlsConfig, err = tc.newTLSConfig("127.0.0.1")
+	require.NoError(t, err)
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	_, err = get(client, server.URL)
+	require.NoError(t, err)
+}
+
+func TestTLSConfigHTTPTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jaeger-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "ca", nil)
+	server := newMTLSServer(t, ca)
+	defer server.Close()
+
+	client := newTestCert(t, "client", ca)
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	writeFile(t, certFile, client.certPEM)
+	writeFile(t, keyFile, client.keyPEM)
+
+	rc := &ReporterConfig{
+		CollectorEndpoint: server.URL,
+		TLS:               &TLSConfig{CertFile: certFile, KeyFile: keyFile, InsecureSkipVerify: true},
+	}
+	sender, err := rc.newTransport(nil, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &transport.HTTPTransport{}, sender)
+
+	rc.TLS.CAFile = filepath.Join(dir, "missing.pem")
+	rc.TLS.InsecureSkipVerify = false
+	_, err = rc.newTransport(nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot create TLS configuration for the collector: cannot read CA file")
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jaeger-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	emptyFile := filepath.Join(dir, "empty.pem")
+	writeFile(t, emptyFile, nil)
+
+	tests := []struct {
+		config TLSConfig
+		err    string
+	}{
+		{config: TLSConfig{CertFile: "cert.pem"}, err: "certFile and keyFile must be specified together"},
+		{config: TLSConfig{MinVersion: "1.4"}, err: `unknown TLS version "1.4"`},
+		{config: TLSConfig{CAFile: emptyFile}, err: "no certificates found in CA file"},
+		{config: TLSConfig{CertFile: emptyFile, KeyFile: emptyFile}, err: "cannot load client certificate"},
+	}
+	for _, test := range tests {
+		_, err := test.config.NewTLSConfig()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), test.err)
+	}
+
+	err = Configuration{
+		ServiceName: "svc",
+		Sampler:     &SamplerConfig{Type: "remote", TLS: &TLSConfig{MinVersion: "2"}},
+		Reporter:    &ReporterConfig{TLS: &TLSConfig{KeyFile: "key.pem"}},
+	}.Validate()
+	require.Error(t, err)
+	assert.Equal(t, []FieldProblem{
+		{Field: "sampler.tls", Message: `unknown TLS version "2", expecting one of 1.0, 1.1, 1.2 or 1.3`},
+		{Field: "reporter.tls", Message: "certFile and keyFile must be specified together"},
+	}, err.(*ValidationError).Problems)
+
+	_, err = (&SamplerConfig{Type: "remote", TLS: &TLSConfig{CAFile: emptyFile}}).NewSampler("svc", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot create TLS configuration for the sampling server")
+}
+
+func TestTLSConfigFromEnv(t *testing.T) {
+	setEnv(t, envReporterTLSCA, "/etc/ca.pem")
+	setEnv(t, envReporterTLSCert, "/etc/cert.pem")
+	setEnv(t, envReporterTLSKey, "/etc/key.pem")
+	setEnv(t, envReporterTLSServerName, "collector")
+	setEnv(t, envReporterTLSMinVersion, "1.3")
+	setEnv(t, envReporterTLSSkipHostVerify, "true")
+	setEnv(t, envSamplerTLSCA, "/etc/sampling-ca.pem")
+	defer unsetEnv(t, envReporterTLSCA)
+	defer unsetEnv(t, envReporterTLSCert)
+	defer unsetEnv(t, envReporterTLSKey)
+	defer unsetEnv(t, envReporterTLSServerName)
+	defer unsetEnv(t, envReporterTLSMinVersion)
+	defer unsetEnv(t, envReporterTLSSkipHostVerify)
+	defer unsetEnv(t, envSamplerTLSCA)
+
+	cfg, err := FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, &TLSConfig{
+		CAFile:             "/etc/ca.pem",
+		CertFile:           "/etc/cert.pem",
+		KeyFile:            "/etc/key.pem",
+		ServerName:         "collector",
+		MinVersion:         "1.3",
+		InsecureSkipVerify: true,
+	}, cfg.Reporter.TLS)
+	assert.Equal(t, &TLSConfig{CAFile: "/etc/sampling-ca.pem"}, cfg.Sampler.TLS)
+
+	setEnv(t, envSamplerTLSSkipHostVerify, "NOT_A_BOOLEAN")
+	defer unsetEnv(t, envSamplerTLSSkipHostVerify)
+	_, err = FromEnv()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot parse env var JAEGER_SAMPLER_TLS_SKIP_HOST_VERIFY=NOT_A_BOOLEAN")
+}
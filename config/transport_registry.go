@@ -0,0 +1,147 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/uber/jaeger-lib/metrics"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/transport"
+)
+
+// TransportParams holds the parameters passed to a TransportFactory.
+type TransportParams struct {
+	// Endpoint is the parsed ReporterConfig.CollectorEndpoint.
+	Endpoint *url.URL
+
+	// Endpoints are the parsed CollectorEndpoint followed by the ReporterConfig.CollectorEndpoints,
+	// for transports that can balance the spans across several collectors.
+	Endpoints []*url.URL
+
+	// Options are the transport specific options from ReporterConfig.TransportOptions.
+	Options map[string]interface{}
+
+	// Reporter is the configuration of the reporter the transport is created for,
+	// e.g. to access the HTTP headers or the credentials.
+	Reporter *ReporterConfig
+
+	Logger jaeger.Logger
+
+	// Metrics is the factory for the metrics of the transport, never nil.
+	Metrics metrics.Factory
+}
+
+// TransportFactory creates the transport used by the reporter to send spans to
+// a collector endpoint with the URL scheme the factory is registered for.
+type TransportFactory func(params TransportParams) (jaeger.Transport, error)
+
+var transportFactories = struct {
+	sync.RWMutex
+	factories map[string]TransportFactory
+}{
+	factories: map[string]TransportFactory{
+		"http":  newHTTPTransport,
+		"https": newHTTPTransport,
+	},
+}
+
+// RegisterTransport makes a transport available to ReporterConfig for the collector
+// endpoints with the given URL scheme, e.g. "kafka", "file" or "otlp+http". Schemes
+// are case insensitive. The "http" and "https" schemes are registered for the built-in
+// transport.HTTPTransport.
+//
+// RegisterTransport is meant to be called from the init() function of the package
+// that implements the transport. It panics if the factory is nil or if a factory
+// is already registered for the scheme.
+func RegisterTransport(scheme string, factory TransportFactory) {
+	if factory == nil {
+		panic("config: RegisterTransport factory is nil")
+	}
+	scheme = strings.ToLower(scheme)
+	transportFactories.Lock()
+	defer transportFactories.Unlock()
+	if _, dup := transportFactories.factories[scheme]; dup {
+		panic(fmt.Sprintf("config: RegisterTransport called twice for scheme %q", scheme))
+	}
+	transportFactories.factories[scheme] = factory
+}
+
+// RegisteredTransports returns the sorted list of URL schemes with a registered transport.
+func RegisteredTransports() []string {
+	transportFactories.RLock()
+	defer transportFactories.RUnlock()
+	schemes := make([]string, 0, len(transportFactories.factories))
+	for scheme := range transportFactories.factories {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+func transportFactory(scheme string) (TransportFactory, bool) {
+	transportFactories.RLock()
+	defer transportFactories.RUnlock()
+	factory, ok := transportFactories.factories[strings.ToLower(scheme)]
+	return factory, ok
+}
+
+func unregisterTransport(scheme string) {
+	transportFactories.Lock()
+	defer transportFactories.Unlock()
+	delete(transportFactories.factories, strings.ToLower(scheme))
+}
+
+func newHTTPTransport(params TransportParams) (jaeger.Transport, error) {
+	rc := params.Reporter
+	httpOptions := []transport.HTTPOption{
+		transport.HTTPHeaders(rc.HTTPHeaders),
+		transport.HTTPMetrics(params.Metrics),
+	}
+	for _, endpoint := range params.Endpoints[1:] {
+		httpOptions = append(httpOptions, transport.HTTPEndpoints(endpoint.String()))
+	}
+	if rc.CollectorSRV != "" {
+		httpOptions = append(httpOptions, transport.HTTPSRVLookup(rc.CollectorSRV, 0))
+	}
+	if rc.User != "" && rc.Password != "" {
+		httpOptions = append(httpOptions, transport.HTTPBasicAuth(rc.User, rc.Password))
+	}
+	if rc.TLS != nil {
+		// the host is only known when a single collector is dialed
+		var host string
+		if len(params.Endpoints) == 1 && rc.CollectorSRV == "" {
+			host = params.Endpoint.Hostname()
+		}
+		tlsConfig, err := rc.TLS.newTLSConfig(host)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create TLS configuration for the collector: %v", err)
+		}
+		httpOptions = append(httpOptions, transport.HTTPTLSConfig(tlsConfig))
+	}
+	credentials, err := newHTTPCredentials(rc.Credentials, rc.AuthToken, rc.AuthTokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create credentials for the collector: %v", err)
+	}
+	if credentials != nil {
+		httpOptions = append(httpOptions, transport.HTTPCredentials(credentials))
+	}
+	return transport.NewHTTPTransport(params.Endpoint.String(), httpOptions...), nil
+}
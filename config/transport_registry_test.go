@@ -0,0 +1,244 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/log"
+	"github.com/uber/jaeger-client-go/transport"
+)
+
+type recordingTransport struct {
+	params TransportParams
+	spans  int
+}
+
+func (t *recordingTransport) Append(span *jaeger.Span) (int, error) {
+	t.spans++
+	return 0, nil
+}
+
+func (t *recordingTransport) Flush() (int, error) { return 0, nil }
+func (t *recordingTransport) Close() error        { return nil }
+
+func TestRegisterTransport(t *testing.T) {
+	var created *recordingTransport
+	RegisterTransport("File", func(params TransportParams) (jaeger.Transport, error) {
+		created = &recordingTransport{params: params}
+		return created, nil
+	})
+	defer unregisterTransport("file")
+	assert.Contains(t, RegisteredTransports(), "file")
+
+	rc := &ReporterConfig{
+		CollectorEndpoint:  "file:///var/log/spans.json",
+		CollectorEndpoints: []string{"file:///var/log/spans-2.json"},
+		TransportOptions:   map[string]interface{}{"rotate": true},
+	}
+	require.NoError(t, Configuration{ServiceName: "svc", Reporter: rc}.Validate())
+
+	sender, err := rc.newTransport(log.NullLogger, nil)
+	require.NoError(t, err)
+	assert.Same(t, created, sender)
+	assert.Equal(t, "/var/log/spans.json", created.params.Endpoint.Path)
+	require.Len(t, created.params.Endpoints, 2)
+	assert.Same(t, created.params.Endpoint, created.params.Endpoints[0])
+	assert.Equal(t, "/var/log/spans-2.json", created.params.Endpoints[1].Path)
+	assert.Equal(t, metrics.NullFactory, created.params.Metrics)
+	assert.Equal(t, map[string]interface{}{"rotate": true}, created.params.Options)
+	assert.Same(t, rc, created.params.Reporter)
+	assert.Equal(t, log.NullLogger, created.params.Logger)
+}
+
+func TestRegisterTransportPanics(t *testing.T) {
+	assert.Panics(t, func() { RegisterTransport("kafka", nil) })
+	assert.Panics(t, func() {
+		RegisterTransport("HTTP", func(TransportParams) (jaeger.Transport, error) { return nil, nil })
+	})
+}
+
+func TestTransportFactoryError(t *testing.T) {
+	RegisterTransport("kafka", func(TransportParams) (jaeger.Transport, error) {
+		return nil, errors.New("no brokers")
+	})
+	defer unregisterTransport("kafka")
+
+	rc := &ReporterConfig{CollectorEndpoint: "kafka://broker:9092/spans"}
+	_, err := rc.NewReporter("svc", jaeger.NewNullMetrics(), log.NullLogger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no brokers")
+}
+
+func TestUnregisteredTransport(t *testing.T) {
+	rc := &ReporterConfig{CollectorEndpoint: "otlp+http://otel-collector:4318/v1/traces"}
+
+	err := Configuration{ServiceName: "svc", Reporter: rc}.Validate()
+	require.Error(t, err)
+	assert.Equal(t,
+		`invalid configuration: reporter.collectorEndpoint: no transport registered for scheme "otlp+http", `+
+			`received "otlp+http://otel-collector:4318/v1/traces" (registered: http, https)`,
+		err.Error())
+
+	_, err = rc.newTransport(log.NullLogger, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no transport registered")
+}
+
+func TestHTTPTransportFactory(t *testing.T) {
+	rc := &ReporterConfig{CollectorEndpoint: "HTTPS://collector:14268/api/traces"}
+	sender, err := rc.newTransport(log.NullLogger, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &transport.HTTPTransport{}, sender)
+}
+
+func TestTransportOptionsFromEnv(t *testing.T) {
+	setEnv(t, envReporterTransportOptions, "topic=spans,acks=all")
+	defer unsetEnv(t, envReporterTransportOptions)
+
+	cfg, err := FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"topic": "spans", "acks": "all"}, cfg.Reporter.TransportOptions)
+}
+
+func TestCollectorEndpointsFailover(t *testing.T) {
+	var requests [2]int32
+	newCollector := func(i int, status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests[i], 1)
+			w.WriteHeader(status)
+		}))
+	}
+	unhealthy := newCollector(0, http.StatusServiceUnavailable)
+	defer unhealthy.Close()
+	healthy := newCollector(1, http.StatusAccepted)
+	defer healthy.Close()
+
+	cfg := Configuration{
+		ServiceName: "svc",
+		Sampler:     &SamplerConfig{Type: "const", Param: 1},
+		Reporter: &ReporterConfig{
+			CollectorEndpoint:  unhealthy.URL,
+			CollectorEndpoints: []string{healthy.URL},
+		},
+	}
+	factory := metricstest.NewFactory(0)
+	tracer, closer, err := cfg.NewTracer(Metrics(factory))
+	require.NoError(t, err)
+	tracer.StartSpan("op").Finish()
+	closeCloser(t, closer)
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests[0]))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests[1]))
+	factory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{
+			Name:  "jaeger.tracer.collector_requests",
+			Tags:  map[string]string{"endpoint": unhealthy.Listener.Addr().String(), "result": "err"},
+			Value: 1,
+		},
+		metricstest.ExpectedMetric{
+			Name:  "jaeger.tracer.collector_requests",
+			Tags:  map[string]string{"endpoint": healthy.Listener.Addr().String(), "result": "ok"},
+			Value: 1,
+		},
+		metricstest.ExpectedMetric{
+			Name:  "jaeger.tracer.reporter_spans",
+			Tags:  map[string]string{"result": "ok"},
+			Value: 1,
+		},
+	)
+}
+
+func TestCollectorEndpointsValidation(t *testing.T) {
+	err := Configuration{
+		ServiceName: "svc",
+		Reporter: &ReporterConfig{
+			CollectorEndpoint:  "http://collector-1:14268/api/traces",
+			CollectorEndpoints: []string{"https://collector-2:14268/api/traces"},
+		},
+	}.Validate()
+	require.Error(t, err)
+	assert.Equal(t, []FieldProblem{{
+		Field: "reporter.collectorEndpoints[0]",
+		Message: `must have the same scheme as collectorEndpoint "http://collector-1:14268/api/traces", ` +
+			`received "https://collector-2:14268/api/traces"`,
+	}}, err.(*ValidationError).Problems)
+
+	err = Configuration{
+		ServiceName: "svc",
+		Reporter: &ReporterConfig{
+			CollectorEndpoints: []string{"http://collector-2:14268/api/traces"},
+			CollectorSRV:       "_jaeger-collector._tcp.example.com",
+		},
+	}.Validate()
+	require.Error(t, err)
+	assert.Equal(t, []FieldProblem{
+		{Field: "reporter.collectorEndpoints[0]", Message: "requires collectorEndpoint"},
+		{Field: "reporter.collectorSRV", Message: "requires collectorEndpoint"},
+	}, err.(*ValidationError).Problems)
+}
+
+func TestCollectorEndpointsFromEnv(t *testing.T) {
+	setEnv(t, envEndpoint, "http://collector-1:14268/api/traces")
+	setEnv(t, envEndpoints, "http://collector-2:14268/api/traces, http://collector-3:14268/api/traces")
+	setEnv(t, envEndpointSRV, "_jaeger-collector._tcp.example.com")
+	defer unsetEnv(t, envEndpoint)
+	defer unsetEnv(t, envEndpoints)
+	defer unsetEnv(t, envEndpointSRV)
+
+	cfg, err := FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "http://collector-1:14268/api/traces", cfg.Reporter.CollectorEndpoint)
+	assert.Equal(t, []string{
+		"http://collector-2:14268/api/traces",
+		"http://collector-3:14268/api/traces",
+	}, cfg.Reporter.CollectorEndpoints)
+	assert.Equal(t, "_jaeger-collector._tcp.example.com", cfg.Reporter.CollectorSRV)
+	cfg.ServiceName = "svc"
+	require.NoError(t, cfg.Validate())
+
+	// JAEGER_ENDPOINTS can be used alone
+	unsetEnv(t, envEndpoint)
+	cfg, err = FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "http://collector-2:14268/api/traces", cfg.Reporter.CollectorEndpoint)
+	assert.Equal(t, []string{"http://collector-3:14268/api/traces"}, cfg.Reporter.CollectorEndpoints)
+	assert.Equal(t, "", cfg.Reporter.LocalAgentHostPort)
+
+	setEnv(t, envEndpoints, "http://collector-2:14268/api/traces,collector-3")
+	_, err = FromEnv()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot parse env var JAEGER_ENDPOINTS")
+}
+
+func TestCollectorEndpointWithCommaFromEnv(t *testing.T) {
+	setEnv(t, envEndpoint, "http://collector:14268/api/traces?tenant=a,b")
+	defer unsetEnv(t, envEndpoint)
+
+	cfg, err := FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "http://collector:14268/api/traces?tenant=a,b", cfg.Reporter.CollectorEndpoint)
+	assert.Empty(t, cfg.Reporter.CollectorEndpoints)
+}
@@ -53,6 +53,33 @@ const (
 	// SamplerParamTagKey reports the parameter of the sampler, like sampling probability.
 	SamplerParamTagKey = "sampler.param"
 
+	// StatusCodeTagKey reports the status set with Span.SetStatus, "OK" or "ERROR".
+	StatusCodeTagKey = "otel.status_code"
+
+	// StatusDescriptionTagKey reports the description of the error status set with Span.SetStatus.
+	StatusDescriptionTagKey = "otel.status_description"
+
+	// DroppedTagsTagKey reports the number of tags dropped from the span because of
+	// the MaxTagsPerSpan or MaxSpanSize limits.
+	DroppedTagsTagKey = "jaeger.dropped_tags"
+
+	// DroppedReferencesTagKey reports the number of references dropped from the span
+	// because of the MaxReferencesPerSpan limit.
+	DroppedReferencesTagKey = "jaeger.dropped_references"
+
+	// DroppedLinksTagKey reports the number of links dropped from the span
+	// because of the MaxLinksPerSpan limit.
+	DroppedLinksTagKey = "jaeger.dropped_links"
+
+	// DroppedLogFieldsTagKey reports the number of log fields dropped from the span
+	// because of the MaxFieldsPerLog limit.
+	DroppedLogFieldsTagKey = "jaeger.dropped_log_fields"
+
+	// DroppedLogsTagKey reports the number of logs dropped from the span because of
+	// the MaxSpanSize limit. The logs dropped because of MaxLogsPerSpan are reported
+	// with a log instead.
+	DroppedLogsTagKey = "jaeger.dropped_logs"
+
 	// TraceContextHeaderName is the http header name used to propagate tracing context.
 	// This must be in lower-case to avoid mismatches when decoding incoming headers.
 	TraceContextHeaderName = "uber-trace-id"
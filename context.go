@@ -0,0 +1,77 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// StartSpanFromContext starts a span with this tracer, as a child of the span in ctx
+// if there is one, and returns it along with a copy of ctx holding it. Unlike
+// opentracing.StartSpanFromContext, it does not depend on the global tracer, so that
+// several tracers can coexist in the same process.
+func (t *Tracer) StartSpanFromContext(
+	ctx context.Context,
+	operationName string,
+	options ...opentracing.StartSpanOption,
+) (*Span, context.Context) {
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		options = append([]opentracing.StartSpanOption{opentracing.ChildOf(parent.Context())}, options...)
+	}
+	span := t.StartSpan(operationName, options...).(*Span)
+	// keep the pprof labels of ctx, and pass the labels of the span down
+	ctx = span.setPprofLabels(ctx)
+	return span, ContextWithSpan(ctx, span)
+}
+
+// SpanFromContext returns the span held by ctx, or nil if there is none or if it
+// was not started by a jaeger tracer.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := opentracing.SpanFromContext(ctx).(*Span)
+	return span
+}
+
+// ContextWithSpan returns a copy of ctx holding the span. The span is stored as with
+// opentracing.ContextWithSpan, so that it is also visible to the instrumentation
+// relying on opentracing.SpanFromContext.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return opentracing.ContextWithSpan(ctx, span)
+}
+
+// SpanContextFromContext returns the context of the span held by ctx, and false if
+// there is no span started by a jaeger tracer in ctx.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return SpanContext{}, false
+	}
+	return span.SpanContext(), true
+}
+
+// TraceIDFromContext returns the ID of the trace of the span held by ctx, e.g. to
+// correlate logs with traces, and false if there is no span started by a jaeger
+// tracer in ctx.
+func TraceIDFromContext(ctx context.Context) (TraceID, bool) {
+	spanCtx, ok := SpanContextFromContext(ctx)
+	if !ok {
+		return TraceID{}, false
+	}
+	return spanCtx.TraceID(), true
+}
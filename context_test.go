@@ -0,0 +1,77 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracerStartSpanFromContext(t *testing.T) {
+	tracer1, closer1 := NewTracer("svc1", NewConstSampler(true), NewNullReporter())
+	defer closer1.Close()
+	tracer2, closer2 := NewTracer("svc2", NewConstSampler(true), NewNullReporter())
+	defer closer2.Close()
+
+	root, ctx := tracer1.(*Tracer).StartSpanFromContext(context.Background(), "root")
+	defer root.Finish()
+	assert.Equal(t, SpanID(0), root.SpanContext().ParentID())
+	assert.Same(t, root, SpanFromContext(ctx))
+	assert.Same(t, root, opentracing.SpanFromContext(ctx))
+
+	child, childCtx := tracer2.(*Tracer).StartSpanFromContext(ctx, "child", opentracing.Tag{Key: "k", Value: "v"})
+	defer child.Finish()
+	assert.Same(t, tracer2, child.Tracer())
+	assert.Equal(t, root.SpanContext().TraceID(), child.SpanContext().TraceID())
+	assert.Equal(t, root.SpanContext().SpanID(), child.SpanContext().ParentID())
+	assert.Equal(t, "v", child.Tags()["k"])
+	assert.Same(t, child, SpanFromContext(childCtx))
+	assert.Same(t, root, SpanFromContext(ctx), "the parent context is unchanged")
+}
+
+func TestSpanFromContext(t *testing.T) {
+	assert.Nil(t, SpanFromContext(context.Background()))
+
+	mockSpan := mocktracer.New().StartSpan("mock")
+	assert.Nil(t, SpanFromContext(opentracing.ContextWithSpan(context.Background(), mockSpan)))
+	_, ok := SpanContextFromContext(opentracing.ContextWithSpan(context.Background(), mockSpan))
+	assert.False(t, ok)
+
+	ctx := ContextWithSpan(context.Background(), nil)
+	assert.Equal(t, context.Background(), ctx)
+	_, ok = TraceIDFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestTraceIDFromContext(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter())
+	defer closer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	defer span.Finish()
+	ctx := ContextWithSpan(context.Background(), span)
+
+	spanCtx, ok := SpanContextFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, span.SpanContext(), spanCtx)
+	traceID, ok := TraceIDFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, span.SpanContext().TraceID().String(), traceID.String())
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/uber/jaeger-client-go"
+)
+
+// SamplerHandler returns an http.Handler that renders the state of the current
+// sampler of the given tracer as JSON, e.g.
+//
+//     tracer, closer := jaeger.NewTracer(...)
+//     http.Handle("/debug/sampler", debug.SamplerHandler(tracer.(*jaeger.Tracer)))
+//
+// The sampler is read on each request, so that the handler follows the samplers
+// replaced by Tracer.Reconfigure.
+func SamplerHandler(tracer *jaeger.Tracer) http.Handler {
+	return &samplerHandler{tracer: tracer}
+}
+
+type samplerHandler struct {
+	tracer *jaeger.Tracer
+}
+
+func (h *samplerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := json.MarshalIndent(jaeger.NewSamplerState(h.tracer.Sampler()), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
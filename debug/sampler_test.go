@@ -0,0 +1,67 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestSamplerHandler(t *testing.T) {
+	sampler, err := jaeger.NewProbabilisticSampler(0.5)
+	require.NoError(t, err)
+	tracer, closer := jaeger.NewTracer("svc", sampler, jaeger.NewNullReporter())
+	defer closer.Close()
+	handler := SamplerHandler(tracer.(*jaeger.Tracer))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/sampler", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var state jaeger.SamplerState
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &state))
+	assert.Equal(t, jaeger.SamplerTypeProbabilistic, state.Type)
+	assert.Equal(t, map[string]interface{}{"samplingRate": 0.5}, state.Params)
+	assert.JSONEq(t, `{"type":"probabilistic","params":{"samplingRate":0.5}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/debug/sampler", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, HEAD", w.Header().Get("Allow"))
+}
+
+func TestSamplerHandlerAfterReconfigure(t *testing.T) {
+	tracer, closer := jaeger.NewTracer("svc", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+	handler := SamplerHandler(tracer.(*jaeger.Tracer))
+
+	sampler, err := jaeger.NewProbabilisticSampler(0.5)
+	require.NoError(t, err)
+	tracer.(*jaeger.Tracer).Reconfigure(jaeger.ReconfigureOptions.Sampler(sampler))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/sampler", nil))
+	var state jaeger.SamplerState
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &state))
+	assert.Equal(t, jaeger.SamplerTypeProbabilistic, state.Type, "the handler reads the current sampler")
+}
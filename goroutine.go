@@ -0,0 +1,160 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// PanicError is the error of a recovered panic.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack string
+}
+
+func newPanicError(value interface{}) *PanicError {
+	return &PanicError{Value: value, Stack: string(debug.Stack())}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the value passed to panic if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// recordPanic logs the panic as an event=error log with error.kind=panic, the
+// error.object, message and stack fields, and sets the error status.
+func (s *Span) recordPanic(p *PanicError) {
+	s.logError(p.Error(), []log.Field{
+		log.String("event", "error"),
+		log.String("error.kind", "panic"),
+		log.Error(p),
+		log.String("message", fmt.Sprint(p.Value)),
+		log.String("stack", p.Stack),
+	}, time.Time{})
+}
+
+// Go runs fn in a new goroutine, within a span that follows from the span in ctx, if
+// any, and that fn receives in its context. The span is started before Go returns,
+// so that it is correctly ordered even if the parent span finishes first, and it is
+// finished when fn returns, with the error returned by fn recorded with RecordError.
+//
+// If fn panics, the panic is recorded on the span, which is finished and reported
+// synchronously before the panic is propagated, as with Span.FinishWithRecover.
+func (t *Tracer) Go(ctx context.Context, operationName string, fn func(ctx context.Context) error) {
+	span, spanCtx := t.startTask(ctx, operationName)
+	go func() {
+		_ = runTask(spanCtx, span, fn, true)
+	}()
+}
+
+// goroutineTaskOption marks the spans started by startTask, which are finished on
+// another goroutine than the one starting them.
+type goroutineTaskOption struct{}
+
+func (goroutineTaskOption) Apply(*opentracing.StartSpanOptions) {}
+
+// startTask starts a span following from the span in ctx, if any.
+func (t *Tracer) startTask(ctx context.Context, operationName string) (*Span, context.Context) {
+	options := []opentracing.StartSpanOption{goroutineTaskOption{}}
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		options = append(options, opentracing.FollowsFrom(parent.Context()))
+	}
+	span := t.StartSpan(operationName, options...).(*Span)
+	return span, ContextWithSpan(ctx, span)
+}
+
+// runTask runs fn and finishes the span, even if fn panics. The panics are either
+// propagated or returned as a *PanicError.
+func runTask(ctx context.Context, span *Span, fn func(ctx context.Context) error, propagatePanic bool) (err error) {
+	// the span is started on the calling goroutine, but its labels are set here, on
+	// the goroutine that finishes it
+	ctx = span.setPprofLabels(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			if propagatePanic {
+				span.finishWithPanic(r)
+				panic(r)
+			}
+			panicErr := newPanicError(r)
+			span.recordPanic(panicErr)
+			span.Finish()
+			err = panicErr
+		}
+	}()
+	err = fn(ctx)
+	span.RecordError(err)
+	span.Finish()
+	return err
+}
+
+// Group is a collection of goroutines working on subtasks of the same task, each
+// within its own span, like golang.org/x/sync/errgroup.Group. It is created with
+// Tracer.NewGroup.
+type Group struct {
+	tracer *Tracer
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+}
+
+// NewGroup returns a new Group, and a context derived from ctx that is canceled the
+// first time a function passed to Go returns an error or panics, or the first time
+// Wait returns. The spans of the goroutines follow from the span in ctx, if any.
+func (t *Tracer) NewGroup(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{tracer: t, ctx: ctx, cancel: cancel}, ctx
+}
+
+// Go runs fn in a new goroutine, within a span that follows from the span of the group,
+// as Tracer.Go does. A panic of fn is recorded on the span and returned by Wait as a
+// *PanicError rather than propagated.
+func (g *Group) Go(operationName string, fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+	span, spanCtx := g.tracer.startTask(g.ctx, operationName)
+	go func() {
+		defer g.wg.Done()
+		if err := runTask(spanCtx, span, fn, false); err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+// Wait blocks until all the goroutines started with Go have returned, and returns
+// the first non-nil error, if any.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
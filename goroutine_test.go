@@ -0,0 +1,161 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGoroutineTracer() (*Tracer, *InMemoryReporter) {
+	reporter := NewInMemoryReporter()
+	tracer, _ := NewTracer("svc", NewConstSampler(true), reporter)
+	return tracer.(*Tracer), reporter
+}
+
+func logFields(record opentracing.LogRecord) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for _, field := range record.Fields {
+		fields[field.Key()] = field.Value()
+	}
+	return fields
+}
+
+func TestTracerGo(t *testing.T) {
+	tracer, reporter := newGoroutineTracer()
+	defer tracer.Close()
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "parent")
+	done := make(chan *Span)
+	tracer.Go(ctx, "task", func(ctx context.Context) error {
+		done <- SpanFromContext(ctx)
+		return errors.New("task failed")
+	})
+	// the parent can finish before the task
+	parent.Finish()
+	span := <-done
+
+	require.Eventually(t, func() bool { return reporter.SpansSubmitted() == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, "task", span.OperationName())
+	refs := span.References()
+	require.Len(t, refs, 1)
+	assert.Equal(t, opentracing.FollowsFromRef, refs[0].Type)
+	assert.Equal(t, parent.SpanContext(), refs[0].ReferencedContext)
+	assert.Equal(t, StatusError, span.Status())
+	assert.Equal(t, "task failed", span.Tags()[StatusDescriptionTagKey])
+}
+
+func TestTracerGoWithoutParent(t *testing.T) {
+	tracer, reporter := newGoroutineTracer()
+	defer tracer.Close()
+
+	done := make(chan *Span)
+	tracer.Go(context.Background(), "task", func(ctx context.Context) error {
+		done <- SpanFromContext(ctx)
+		return nil
+	})
+	span := <-done
+	require.Eventually(t, func() bool { return reporter.SpansSubmitted() == 1 }, time.Second, time.Millisecond)
+	assert.Empty(t, span.References())
+	assert.Equal(t, StatusUnset, span.Status())
+}
+
+func TestRunTaskPropagatesPanic(t *testing.T) {
+	tracer, reporter := newGoroutineTracer()
+	defer tracer.Close()
+
+	span, ctx := tracer.startTask(context.Background(), "task")
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = runTask(ctx, span, func(ctx context.Context) error {
+			panic("boom")
+		}, true)
+	})
+	assert.Equal(t, 1, reporter.SpansSubmitted(), "the span is finished before the panic propagates")
+	assert.Equal(t, true, span.Tags()["error"])
+	logs := span.Logs()
+	require.Len(t, logs, 1)
+	fields := logFields(logs[0])
+	assert.Equal(t, "error", fields["event"])
+	assert.Equal(t, "panic", fields["error.kind"])
+	assert.Equal(t, "boom", fields["message"])
+	assert.Contains(t, fields["stack"], "TestRunTaskPropagatesPanic")
+}
+
+func TestGroup(t *testing.T) {
+	tracer, reporter := newGoroutineTracer()
+	defer tracer.Close()
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "parent")
+	defer parent.Finish()
+	group, groupCtx := tracer.NewGroup(ctx)
+	failure := errors.New("failure")
+	group.Go("ok", func(ctx context.Context) error {
+		return nil
+	})
+	group.Go("failed", func(ctx context.Context) error {
+		return failure
+	})
+	group.Go("canceled", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.Equal(t, failure, group.Wait())
+	assert.Error(t, groupCtx.Err(), "the context is canceled by the first error")
+
+	spans := reporter.GetSpans()
+	require.Len(t, spans, 3)
+	for _, s := range spans {
+		span := s.(*Span)
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+		assert.Equal(t, opentracing.FollowsFromRef, span.References()[0].Type)
+		assert.Equal(t, span.OperationName() != "ok", span.Status() == StatusError, span.OperationName())
+	}
+}
+
+func TestGroupPanic(t *testing.T) {
+	tracer, reporter := newGoroutineTracer()
+	defer tracer.Close()
+
+	group, _ := tracer.NewGroup(context.Background())
+	cause := errors.New("cause")
+	group.Go("panic", func(ctx context.Context) error {
+		panic(cause)
+	})
+	err := group.Wait()
+	var panicErr *PanicError
+	require.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, cause, panicErr.Value)
+	assert.True(t, errors.Is(err, cause))
+	assert.Equal(t, "panic: cause", err.Error())
+	assert.True(t, strings.Contains(panicErr.Stack, "TestGroupPanic"), panicErr.Stack)
+	assert.Equal(t, 1, reporter.SpansSubmitted())
+}
+
+func TestGroupWaitWithoutError(t *testing.T) {
+	tracer, _ := newGoroutineTracer()
+	defer tracer.Close()
+
+	group, ctx := tracer.NewGroup(context.Background())
+	group.Go("ok", func(ctx context.Context) error { return nil })
+	assert.NoError(t, group.Wait())
+	assert.Error(t, ctx.Err(), "the context is canceled when Wait returns")
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is how long before their expiration the tokens returned by
+// a TokenExchangeFunc are refreshed, to account for clock skew and request latency.
+const tokenRefreshMargin = 10 * time.Second
+
+// tokenExchangeTimeout bounds the calls to a TokenExchangeFunc.
+const tokenExchangeTimeout = 30 * time.Second
+
+// HTTPCredentials authenticate the HTTP requests sent to a collector or a sampling server.
+// Apply is called for every request and must be safe for concurrent use.
+type HTTPCredentials interface {
+	// Apply adds the credentials to the request, e.g. as an Authorization header.
+	// If it returns an error the request is not sent.
+	Apply(req *http.Request) error
+}
+
+// NewStaticTokenCredentials returns HTTPCredentials that send the given bearer token.
+func NewStaticTokenCredentials(token string) HTTPCredentials {
+	return staticTokenCredentials(token)
+}
+
+type staticTokenCredentials string
+
+func (c staticTokenCredentials) Apply(req *http.Request) error {
+	setBearerToken(req, string(c))
+	return nil
+}
+
+// NewTokenFileCredentials returns HTTPCredentials that send the bearer token read from
+// the given file, e.g. a projected Kubernetes service account token. The file is read
+// again when its modification time or size changes, so that rotated tokens are used
+// without a restart. If it cannot be read, the previous token is kept.
+func NewTokenFileCredentials(path string) (HTTPCredentials, error) {
+	c := &tokenFileCredentials{path: path}
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type tokenFileCredentials struct {
+	path string
+
+	sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+func (c *tokenFileCredentials) Apply(req *http.Request) error {
+	c.Lock()
+	defer c.Unlock()
+	if info, err := os.Stat(c.path); err == nil && (!info.ModTime().Equal(c.modTime) || info.Size() != c.size) {
+		// errors are ignored to keep the previous token while the file is rotated
+		_ = c.reloadLocked()
+	}
+	setBearerToken(req, c.token)
+	return nil
+}
+
+func (c *tokenFileCredentials) reload() error {
+	c.Lock()
+	defer c.Unlock()
+	return c.reloadLocked()
+}
+
+func (c *tokenFileCredentials) reloadLocked() error {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return fmt.Errorf("cannot read token file: %v", err)
+	}
+	content, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return fmt.Errorf("cannot read token file: %v", err)
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return fmt.Errorf("token file %s is empty", c.path)
+	}
+	c.token, c.modTime, c.size = token, info.ModTime(), info.Size()
+	return nil
+}
+
+// TokenExchangeFunc obtains a bearer token, e.g. from an OAuth2 token endpoint, and returns
+// it along with its expiration time. A zero expiration time means the token does not expire.
+type TokenExchangeFunc func(ctx context.Context) (token string, expiresAt time.Time, err error)
+
+// NewTokenExchangeCredentials returns HTTPCredentials that send the bearer token returned
+// by exchange. The token is cached and exchange is called again shortly before it expires.
+// Concurrent requests share a single exchange, which runs with its own context, cancelled
+// after tokenExchangeTimeout; a request stops waiting for it when its own context is done.
+func NewTokenExchangeCredentials(exchange TokenExchangeFunc) HTTPCredentials {
+	return &tokenExchangeCredentials{exchange: exchange, timeNow: time.Now}
+}
+
+type tokenExchangeCredentials struct {
+	exchange TokenExchangeFunc
+	timeNow  func() time.Time
+
+	sync.Mutex
+	token     string
+	expiresAt time.Time
+	pending   *tokenExchange // the exchange in progress, if any
+}
+
+// tokenExchange is the result of a call to TokenExchangeFunc, available once done is closed.
+type tokenExchange struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+func (c *tokenExchangeCredentials) Apply(req *http.Request) error {
+	c.Lock()
+	if c.token != "" && (c.expiresAt.IsZero() || !c.timeNow().Add(tokenRefreshMargin).After(c.expiresAt)) {
+		token := c.token
+		c.Unlock()
+		setBearerToken(req, token)
+		return nil
+	}
+	exchange := c.pending
+	if exchange == nil {
+		exchange = &tokenExchange{done: make(chan struct{})}
+		c.pending = exchange
+		go c.runExchange(exchange)
+	}
+	c.Unlock()
+
+	select {
+	case <-exchange.done:
+	case <-req.Context().Done():
+		return fmt.Errorf("cannot obtain token: %v", req.Context().Err())
+	}
+	if exchange.err != nil {
+		return fmt.Errorf("cannot obtain token: %v", exchange.err)
+	}
+	setBearerToken(req, exchange.token)
+	return nil
+}
+
+func (c *tokenExchangeCredentials) runExchange(exchange *tokenExchange) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenExchangeTimeout)
+	defer cancel()
+	token, expiresAt, err := c.exchange(ctx)
+	if err == nil && token == "" {
+		err = errors.New("empty token")
+	}
+	exchange.token, exchange.err = token, err
+
+	c.Lock()
+	if err == nil {
+		c.token, c.expiresAt = token, expiresAt
+	}
+	c.pending = nil
+	c.Unlock()
+	close(exchange.done)
+}
+
+func setBearerToken(req *http.Request, token string) {
+	req.Header.Set("Authorization", "Bearer "+token)
+}
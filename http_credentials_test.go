@@ -0,0 +1,172 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/jaeger-client-go/log"
+)
+
+func authorization(t *testing.T, credentials HTTPCredentials) string {
+	req, err := http.NewRequest(http.MethodGet, "http://collector", nil)
+	require.NoError(t, err)
+	require.NoError(t, credentials.Apply(req))
+	return req.Header.Get("Authorization")
+}
+
+func TestStaticTokenCredentials(t *testing.T) {
+	assert.Equal(t, "Bearer t0k3n", authorization(t, NewStaticTokenCredentials("t0k3n")))
+}
+
+func TestTokenFileCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jaeger-token")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token")
+	writeToken := func(token string, modTime time.Time) {
+		require.NoError(t, ioutil.WriteFile(path, []byte(token), 0600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	_, err = NewTokenFileCredentials(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot read token file")
+
+	writeToken(" \n", time.Now())
+	_, err = NewTokenFileCredentials(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is empty")
+
+	writeToken("first\n", time.Now())
+	credentials, err := NewTokenFileCredentials(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer first", authorization(t, credentials))
+
+	writeToken("second\n", time.Now().Add(time.Minute))
+	assert.Equal(t, "Bearer second", authorization(t, credentials))
+
+	// the previous token is kept while the file is being rotated
+	writeToken("", time.Now().Add(2*time.Minute))
+	assert.Equal(t, "Bearer second", authorization(t, credentials))
+	require.NoError(t, os.Remove(path))
+	assert.Equal(t, "Bearer second", authorization(t, credentials))
+}
+
+func TestTokenExchangeCredentials(t *testing.T) {
+	now := time.Now()
+	var exchanges int
+	var exchangeErr error
+	credentials := NewTokenExchangeCredentials(func(ctx context.Context) (string, time.Time, error) {
+		exchanges++
+		if exchangeErr != nil {
+			return "", time.Time{}, exchangeErr
+		}
+		if exchanges == 3 {
+			return "", time.Time{}, nil
+		}
+		return fmt.Sprintf("token-%d", exchanges), now.Add(time.Minute), nil
+	}).(*tokenExchangeCredentials)
+	credentials.timeNow = func() time.Time { return now }
+
+	assert.Equal(t, "Bearer token-1", authorization(t, credentials))
+	assert.Equal(t, "Bearer token-1", authorization(t, credentials))
+	assert.Equal(t, 1, exchanges)
+
+	// the token is refreshed shortly before it expires
+	now = now.Add(time.Minute - tokenRefreshMargin/2)
+	assert.Equal(t, "Bearer token-2", authorization(t, credentials))
+	assert.Equal(t, 2, exchanges)
+
+	now = now.Add(time.Minute)
+	req, err := http.NewRequest(http.MethodGet, "http://collector", nil)
+	require.NoError(t, err)
+	assert.EqualError(t, credentials.Apply(req), "cannot obtain token: empty token")
+
+	exchangeErr = errors.New("unauthorized client")
+	assert.EqualError(t, credentials.Apply(req), "cannot obtain token: unauthorized client")
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestTokenExchangeCredentialsContext(t *testing.T) {
+	started := make(chan context.Context, 1)
+	release := make(chan struct{})
+	var exchanges int32
+	credentials := NewTokenExchangeCredentials(func(ctx context.Context) (string, time.Time, error) {
+		atomic.AddInt32(&exchanges, 1)
+		started <- ctx
+		<-release
+		return "t0k3n", time.Time{}, nil
+	})
+
+	waiting := make(chan string)
+	go func() {
+		waiting <- authorization(t, credentials)
+	}()
+	exchangeCtx := <-started
+	_, hasDeadline := exchangeCtx.Deadline()
+	assert.True(t, hasDeadline, "the exchange is bounded by its own deadline")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequest(http.MethodGet, "http://collector", nil)
+	require.NoError(t, err)
+	err = credentials.Apply(req.WithContext(ctx))
+	assert.EqualError(t, err, "cannot obtain token: context canceled", "the request gives up without waiting for the exchange")
+	assert.NoError(t, exchangeCtx.Err(), "the exchange is not cancelled with the request")
+
+	close(release)
+	assert.Equal(t, "Bearer t0k3n", <-waiting)
+	assert.Equal(t, "Bearer t0k3n", authorization(t, credentials))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&exchanges))
+}
+
+func TestSamplingStrategyFetcherCredentials(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	credentials := NewStaticTokenCredentials("t0k3n")
+	_, err := newHTTPSamplingStrategyFetcher(server.URL, log.NullLogger, nil, credentials).Fetch("svc")
+	assert.EqualError(t, err, "StatusCode: 401, Body: ")
+	_, err = newThriftSamplingStrategyFetcher(server.URL, log.NullLogger, nil, credentials).Fetch("svc")
+	assert.EqualError(t, err, "StatusCode: 401, Body: ")
+	assert.Equal(t, []string{"Bearer t0k3n", "Bearer t0k3n"}, authorizations)
+
+	failing := NewTokenExchangeCredentials(func(context.Context) (string, time.Time, error) {
+		return "", time.Time{}, errors.New("unauthorized client")
+	})
+	_, err = newHTTPSamplingStrategyFetcher(server.URL, log.NullLogger, nil, failing).Fetch("svc")
+	assert.EqualError(t, err, "cannot obtain token: unauthorized client")
+	_, err = newThriftSamplingStrategyFetcher(server.URL, log.NullLogger, nil, failing).Fetch("svc")
+	assert.EqualError(t, err, "cannot obtain token: unauthorized client")
+	assert.Len(t, authorizations, 2)
+}
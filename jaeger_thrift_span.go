@@ -28,6 +28,10 @@ import (
 func BuildJaegerThrift(span *Span) *j.Span {
 	span.Lock()
 	defer span.Unlock()
+	return buildJaegerThrift(span)
+}
+
+func buildJaegerThrift(span *Span) *j.Span {
 	startTime := utils.TimeToMicrosecondsSinceEpochInt64(span.startTime)
 	duration := span.duration.Nanoseconds() / int64(time.Microsecond)
 	jaegerSpan := &j.Span{
@@ -43,6 +47,13 @@ func BuildJaegerThrift(span *Span) *j.Span {
 		Logs:          buildLogs(span.logs),
 		References:    buildReferences(span.references),
 	}
+	if len(span.links) > 0 {
+		jaegerSpan.References = append(jaegerSpan.References, buildLinkReferences(span.links)...)
+		jaegerSpan.Logs = append(jaegerSpan.Logs, buildLinkLogs(span.links, span.tracer.options.maxTagValueLength)...)
+	}
+	if droppedCountTags := span.droppedCountTags(); len(droppedCountTags) > 0 {
+		jaegerSpan.Tags = append(jaegerSpan.Tags, buildTags(droppedCountTags, span.tracer.options.maxTagValueLength)...)
+	}
 	return jaegerSpan
 }
 
@@ -55,12 +66,13 @@ func BuildJaegerProcessThrift(span *Span) *j.Process {
 }
 
 func buildJaegerProcessThrift(tracer *Tracer) *j.Process {
+	components := tracer.getComponents()
 	process := &j.Process{
 		ServiceName: tracer.serviceName,
-		Tags:        buildTags(tracer.tags, tracer.options.maxTagValueLength),
+		Tags:        buildTags(components.tags, tracer.options.maxTagValueLength),
 	}
-	if tracer.process.UUID != "" {
-		process.Tags = append(process.Tags, &j.Tag{Key: TracerUUIDTagKey, VStr: &tracer.process.UUID, VType: j.TagType_STRING})
+	if components.process.UUID != "" {
+		process.Tags = append(process.Tags, &j.Tag{Key: TracerUUIDTagKey, VStr: &components.process.UUID, VType: j.TagType_STRING})
 	}
 	return process
 }
@@ -152,7 +164,7 @@ func buildTag(tag *Tag, maxTagValueLength int) *j.Tag {
 		jTag.VBool = &vBool
 		jTag.VType = j.TagType_BOOL
 	default:
-		vStr := truncateString(stringify(value), maxTagValueLength)
+		vStr := stringifyTagValue(value, maxTagValueLength)
 		jTag.VStr = &vStr
 		jTag.VType = j.TagType_STRING
 	}
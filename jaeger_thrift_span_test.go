@@ -39,6 +39,7 @@ var (
 	someBinary      = []byte("hello")
 	someSlice       = []string{"a"}
 	someSliceString = "[a]"
+	someSliceJSON   = `["a"]`
 )
 
 func TestBuildJaegerThrift(t *testing.T) {
@@ -281,7 +282,7 @@ func TestBuildTags(t *testing.T) {
 		{tag: Tag{key: "k", value: float64(123)}, expected: &j.Tag{Key: "k", VType: j.TagType_DOUBLE, VDouble: &someDouble}},
 		{tag: Tag{key: "k", value: someBool}, expected: &j.Tag{Key: "k", VType: j.TagType_BOOL, VBool: &someBool}},
 		{tag: Tag{key: "k", value: someBinary}, expected: &j.Tag{Key: "k", VType: j.TagType_BINARY, VBinary: someBinary}},
-		{tag: Tag{key: "k", value: someSlice}, expected: &j.Tag{Key: "k", VType: j.TagType_STRING, VStr: &someSliceString}},
+		{tag: Tag{key: "k", value: someSlice}, expected: &j.Tag{Key: "k", VType: j.TagType_STRING, VStr: &someSliceJSON}},
 	}
 	for i, test := range tests {
 		testName := fmt.Sprintf("test-%02d", i)
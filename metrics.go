@@ -65,12 +65,30 @@ type Metrics struct {
 	// Number of spans dropped due to internal queue overflow
 	ReporterDropped metrics.Counter `metric:"reporter_spans" tags:"result=dropped" help:"Number of spans dropped due to internal queue overflow"`
 
+	// Number of tags dropped from spans because of MaxTagsPerSpan
+	SpanLimitDroppedTags metrics.Counter `metric:"span_limit_drops" tags:"limit=tags" help:"Number of tags dropped from spans because of MaxTagsPerSpan"`
+
+	// Number of references dropped from spans because of MaxReferencesPerSpan
+	SpanLimitDroppedReferences metrics.Counter `metric:"span_limit_drops" tags:"limit=references" help:"Number of references dropped from spans because of MaxReferencesPerSpan"`
+
+	// Number of links dropped from spans because of MaxLinksPerSpan
+	SpanLimitDroppedLinks metrics.Counter `metric:"span_limit_drops" tags:"limit=links" help:"Number of links dropped from spans because of MaxLinksPerSpan"`
+
+	// Number of log fields dropped from spans because of MaxFieldsPerLog
+	SpanLimitDroppedLogFields metrics.Counter `metric:"span_limit_drops" tags:"limit=log_fields" help:"Number of log fields dropped from spans because of MaxFieldsPerLog"`
+
+	// Number of tags and logs dropped from spans because of MaxSpanSize
+	SpanLimitDroppedForSize metrics.Counter `metric:"span_limit_drops" tags:"limit=size" help:"Number of tags and logs dropped from spans because of MaxSpanSize"`
+
 	// Current number of spans in the reporter queue
 	ReporterQueueLength metrics.Gauge `metric:"reporter_queue_length" help:"Current number of spans in the reporter queue"`
 
 	// Number of times the Sampler succeeded to retrieve sampling strategy
 	SamplerRetrieved metrics.Counter `metric:"sampler_queries" tags:"result=ok" help:"Number of times the Sampler succeeded to retrieve sampling strategy"`
 
+	// Number of times the Sampler was told that the sampling strategy did not change
+	SamplerNotModified metrics.Counter `metric:"sampler_queries" tags:"result=not_modified" help:"Number of times the Sampler was told that the sampling strategy did not change"`
+
 	// Number of times the Sampler failed to retrieve sampling strategy
 	SamplerQueryFailure metrics.Counter `metric:"sampler_queries" tags:"result=err" help:"Number of times the Sampler failed to retrieve sampling strategy"`
 
@@ -0,0 +1,59 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// The pprof labels set on the goroutines running sampled local root spans.
+const (
+	PprofTraceIDLabel   = "trace_id"
+	PprofSpanIDLabel    = "span_id"
+	PprofOperationLabel = "operation"
+)
+
+// setPprofLabels sets the pprof labels identifying the span on the current goroutine,
+// in addition to the labels of base, if the span is a sampled local root span, and
+// returns base with the labels of the span. The labels of base are restored by
+// restorePprofLabels, so base must hold the current labels of the goroutine.
+func (s *Span) setPprofLabels(base context.Context) context.Context {
+	ctx := s.context
+	if !s.tracer.options.pprofLabels || !ctx.IsSampled() || ctx.samplingState == nil ||
+		!ctx.samplingState.isLocalRootSpan(ctx.spanID) {
+		return base
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.pprofCtx = pprof.WithLabels(base, pprof.Labels(
+		PprofTraceIDLabel, ctx.traceID.String(),
+		PprofSpanIDLabel, ctx.spanID.String(),
+		PprofOperationLabel, s.operationName,
+	))
+	s.pprofRestoreCtx = base
+	pprof.SetGoroutineLabels(s.pprofCtx)
+	return s.pprofCtx
+}
+
+// restorePprofLabels restores the pprof labels of the current goroutine as they were
+// before setPprofLabels.
+func (s *Span) restorePprofLabels() {
+	if s.pprofRestoreCtx != nil {
+		pprof.SetGoroutineLabels(s.pprofRestoreCtx)
+		s.pprofRestoreCtx = nil
+		s.pprofCtx = nil
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goroutineLabels returns the goroutine profile, which lists the labels of the goroutines.
+func goroutineLabels(t *testing.T) string {
+	var buf bytes.Buffer
+	require.NoError(t, pprof.Lookup("goroutine").WriteTo(&buf, 1))
+	return buf.String()
+}
+
+func TestPprofLabels(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter(), TracerOptions.PprofLabels(true))
+	defer closer.Close()
+
+	root, ctx := tracer.(*Tracer).StartSpanFromContext(context.Background(), "root-op")
+	traceID := root.SpanContext().TraceID().String()
+	require.NotNil(t, root.pprofCtx)
+	value, _ := pprof.Label(root.pprofCtx, PprofTraceIDLabel)
+	assert.Equal(t, traceID, value)
+	value, _ = pprof.Label(root.pprofCtx, PprofSpanIDLabel)
+	assert.Equal(t, root.SpanContext().SpanID().String(), value)
+	value, _ = pprof.Label(root.pprofCtx, PprofOperationLabel)
+	assert.Equal(t, "root-op", value)
+	assert.Contains(t, goroutineLabels(t), `"trace_id":"`+traceID+`"`)
+
+	child, _ := tracer.(*Tracer).StartSpanFromContext(ctx, "child")
+	assert.Nil(t, child.pprofCtx, "only local root spans set labels")
+	child.Finish()
+	assert.Contains(t, goroutineLabels(t), `"trace_id":"`+traceID+`"`)
+
+	root.Finish()
+	assert.NotContains(t, goroutineLabels(t), `"trace_id":"`+traceID+`"`)
+}
+
+func TestPprofLabelsFromContext(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter(), TracerOptions.PprofLabels(true))
+	defer closer.Close()
+
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("worker", "w1"))
+	pprof.SetGoroutineLabels(ctx)
+	defer pprof.SetGoroutineLabels(context.Background())
+
+	span, spanCtx := tracer.(*Tracer).StartSpanFromContext(ctx, "op")
+	traceID := span.SpanContext().TraceID().String()
+	value, _ := pprof.Label(spanCtx, PprofTraceIDLabel)
+	assert.Equal(t, traceID, value, "the returned context holds the labels of the span")
+	value, _ = pprof.Label(spanCtx, "worker")
+	assert.Equal(t, "w1", value)
+	assert.Contains(t, goroutineLabels(t), `"trace_id":"`+traceID+`"`)
+	assert.Same(t, span, SpanFromContext(spanCtx))
+
+	span.Finish()
+	labels := goroutineLabels(t)
+	assert.NotContains(t, labels, `"trace_id":"`+traceID+`"`)
+	assert.Contains(t, labels, `"worker":"w1"`, "the labels of the context are restored")
+}
+
+func TestPprofLabelsStartSpan(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter(), TracerOptions.PprofLabels(true))
+	defer closer.Close()
+
+	pprof.Do(context.Background(), pprof.Labels("request", "r1"), func(context.Context) {
+		span := tracer.StartSpan("op").(*Span)
+		assert.Nil(t, span.pprofCtx, "StartSpan does not know the labels to restore")
+		span.Finish()
+		assert.Contains(t, goroutineLabels(t), `"request":"r1"`, "the labels of the goroutine are kept")
+	})
+}
+
+func TestPprofLabelsGo(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter(), TracerOptions.PprofLabels(true))
+	defer closer.Close()
+
+	var traceID string
+	running := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	pprof.Do(context.Background(), pprof.Labels("request", "r1"), func(ctx context.Context) {
+		tracer.(*Tracer).Go(ctx, "task", func(ctx context.Context) error {
+			defer close(done)
+			traceID = SpanFromContext(ctx).SpanContext().TraceID().String()
+			value, _ := pprof.Label(ctx, PprofTraceIDLabel)
+			assert.Equal(t, traceID, value, "the context of the function holds the labels of the span")
+			value, _ = pprof.Label(ctx, "request")
+			assert.Equal(t, "r1", value)
+			close(running)
+			<-release
+			return nil
+		})
+		<-running
+		labels := goroutineLabels(t)
+		assert.Contains(t, labels, `"trace_id":"`+traceID+`"`, "the worker goroutine has the labels")
+		assert.Equal(t, 1, strings.Count(labels, `"trace_id":"`+traceID+`"`), "the calling goroutine does not")
+		close(release)
+		<-done
+	})
+	assert.Eventually(t, func() bool {
+		return !strings.Contains(goroutineLabels(t), `"trace_id":"`+traceID+`"`)
+	}, time.Second, time.Millisecond)
+}
+
+func TestPprofLabelsNotSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		sampler Sampler
+		options []TracerOption
+	}{
+		{name: "disabled", sampler: NewConstSampler(true)},
+		{name: "not sampled", sampler: NewConstSampler(false), options: []TracerOption{TracerOptions.PprofLabels(true)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, closer := NewTracer("svc", tt.sampler, NewNullReporter(), tt.options...)
+			defer closer.Close()
+
+			span, ctx := tracer.(*Tracer).StartSpanFromContext(context.Background(), "op")
+			defer span.Finish()
+			assert.Nil(t, span.pprofCtx)
+			_, ok := pprof.Label(ctx, PprofTraceIDLabel)
+			assert.False(t, ok)
+		})
+	}
+}
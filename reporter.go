@@ -15,6 +15,7 @@
 package jaeger
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -37,6 +38,13 @@ type Reporter interface {
 	Close()
 }
 
+// ReporterFlusher is implemented by the reporters that buffer spans and can send them
+// synchronously, e.g. before the process crashes.
+type ReporterFlusher interface {
+	// Flush sends the spans reported so far, blocking until they are sent or ctx is done.
+	Flush(ctx context.Context) error
+}
+
 // ------------------------------
 
 type nullReporter struct{}
@@ -152,6 +160,16 @@ func (r *compositeReporter) Report(span *Span) {
 	}
 }
 
+// SetProcess implements ProcessSetter by passing the process to each underlying reporter
+// that implements ProcessSetter.
+func (r *compositeReporter) SetProcess(process Process) {
+	for _, reporter := range r.reporters {
+		if setter, ok := reporter.(ProcessSetter); ok {
+			setter.SetProcess(process)
+		}
+	}
+}
+
 // Close implements Close() method of Reporter by closing each underlying reporter.
 func (r *compositeReporter) Close() {
 	for _, reporter := range r.reporters {
@@ -159,6 +177,19 @@ func (r *compositeReporter) Close() {
 	}
 }
 
+// Flush implements ReporterFlusher by flushing each underlying reporter that supports it.
+func (r *compositeReporter) Flush(ctx context.Context) error {
+	var firstErr error
+	for _, reporter := range r.reporters {
+		if flusher, ok := reporter.(ReporterFlusher); ok {
+			if err := flusher.Flush(ctx); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
 // ------------- REMOTE REPORTER -----------------
 
 type reporterQueueItemType int
@@ -169,12 +200,16 @@ const (
 
 	reporterQueueItemSpan reporterQueueItemType = iota
 	reporterQueueItemClose
+	reporterQueueItemProcess
+	reporterQueueItemFlush
 )
 
 type reporterQueueItem struct {
 	itemType reporterQueueItemType
 	span     *Span
 	close    *sync.WaitGroup
+	process  *Process
+	flushed  chan struct{}
 }
 
 // reporterStats implements reporterstats.ReporterStats.
@@ -202,6 +237,10 @@ type remoteReporter struct {
 	sender        Transport
 	queue         chan reporterQueueItem
 	reporterStats *reporterStats
+
+	// done is closed by Close once the background go-routine has flushed the spans and
+	// returned, so that the concurrent calls to Flush do not wait for it.
+	done chan struct{}
 }
 
 // NewRemoteReporter creates a new reporter that sends spans out of process by means of Sender.
@@ -230,6 +269,7 @@ func NewRemoteReporter(sender Transport, opts ...ReporterOption) Reporter {
 		sender:          sender,
 		queue:           make(chan reporterQueueItem, options.queueSize),
 		reporterStats:   new(reporterStats),
+		done:            make(chan struct{}),
 	}
 	if receiver, ok := sender.(reporterstats.Receiver); ok {
 		receiver.SetReporterStats(reporter.reporterStats)
@@ -255,6 +295,16 @@ func (r *remoteReporter) Report(span *Span) {
 	}
 }
 
+// SetProcess implements ProcessSetter. The spans queued before the call are flushed
+// before the process is passed to the sender, if it implements ProcessSetter.
+func (r *remoteReporter) SetProcess(process Process) {
+	if atomic.LoadInt64(&r.closed) == 1 {
+		return
+	}
+	r.queue <- reporterQueueItem{itemType: reporterQueueItemProcess, process: &process}
+	atomic.AddInt64(&r.queueLength, 1)
+}
+
 // Close implements Close() method of Reporter by waiting for the queue to be drained.
 func (r *remoteReporter) Close() {
 	r.logger.Debugf("closing reporter")
@@ -263,9 +313,37 @@ func (r *remoteReporter) Close() {
 		return
 	}
 	r.sendCloseEvent()
+	close(r.done)
 	_ = r.sender.Close()
 }
 
+// Flush implements ReporterFlusher by asking the background go-routine to flush the sender,
+// and waiting until it is done. The spans reported prior to the call to Flush are sent.
+// Flush does nothing if the reporter has been closed, since Close already flushes the spans,
+// and returns as soon as the background go-routine exits if Close is called concurrently.
+func (r *remoteReporter) Flush(ctx context.Context) error {
+	if atomic.LoadInt64(&r.closed) == 1 {
+		return nil
+	}
+	item := reporterQueueItem{itemType: reporterQueueItemFlush, flushed: make(chan struct{})}
+	select {
+	case r.queue <- item:
+		atomic.AddInt64(&r.queueLength, 1)
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-item.flushed:
+		return nil
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (r *remoteReporter) sendCloseEvent() {
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
@@ -311,6 +389,14 @@ func (r *remoteReporter) processQueue() {
 					r.logger.Debugf("flushed %d spans", flushed)
 				}
 				span.Release()
+			case reporterQueueItemProcess:
+				if setter, ok := r.sender.(ProcessSetter); ok {
+					flush()
+					setter.SetProcess(*item.process)
+				}
+			case reporterQueueItemFlush:
+				flush()
+				close(item.flushed)
 			case reporterQueueItemClose:
 				timer.Stop()
 				flush()
@@ -15,6 +15,7 @@
 package jaeger
 
 import (
+	"context"
 	"errors"
 	"io"
 	"strings"
@@ -206,6 +207,40 @@ func TestRemoteReporterReportAfterClose(t *testing.T) {
 	assert.Equal(t, span, item.span, "since the reporter is closed and its worker routing finished, the span should be in the queue")
 }
 
+func TestRemoteReporterFlush(t *testing.T) {
+	s := makeReporterSuite(t)
+	defer s.close()
+	s.tracer.StartSpan("sp1").Finish()
+	require.NoError(t, s.reporter.Flush(context.Background()))
+	assert.Len(t, s.sender.FlushedSpans(), 1, "the span is flushed synchronously")
+	s.assertCounter(t, "jaeger.tracer.reporter_spans", map[string]string{"result": "ok"}, 1)
+}
+
+func TestRemoteReporterFlushAfterClose(t *testing.T) {
+	s := makeReporterSuite(t)
+	s.close()
+	assert.NoError(t, s.reporter.Flush(context.Background()))
+}
+
+func TestRemoteReporterFlushRacingClose(t *testing.T) {
+	s := makeReporterSuite(t)
+	s.close()
+	// as if Flush checked the closed flag just before Close set it
+	atomic.StoreInt64(&s.reporter.closed, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, s.reporter.Flush(ctx), "Flush returns when the reporter is closed")
+}
+
+func TestRemoteReporterFlushTimeout(t *testing.T) {
+	// the queue is never processed, so the flush cannot complete
+	reporter := &remoteReporter{queue: make(chan reporterQueueItem, 1)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, reporter.Flush(ctx))
+	assert.Equal(t, context.DeadlineExceeded, reporter.Flush(ctx), "the queue is full")
+}
+
 func TestUDPReporter(t *testing.T) {
 	agent, err := testutils.StartMockAgent()
 	require.NoError(t, err)
@@ -290,6 +325,15 @@ func TestCompositeReporterReport(t *testing.T) {
 	assert.Len(t, reporter2.GetSpans(), 1, "expected number of spans submitted")
 }
 
+func TestCompositeReporterFlush(t *testing.T) {
+	s := makeReporterSuite(t)
+	defer s.close()
+	reporter := NewCompositeReporter(NewInMemoryReporter(), s.reporter)
+	reporter.Report(s.tracer.StartSpan("sp1").(*Span))
+	require.NoError(t, reporter.(ReporterFlusher).Flush(context.Background()))
+	assert.Len(t, s.sender.FlushedSpans(), 1)
+}
+
 func TestLoggingReporter(t *testing.T) {
 	logger := &log.BytesBufferLogger{}
 	reporter := NewLoggingReporter(logger)
@@ -0,0 +1,100 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+const (
+	defaultCgroupPath    = "/proc/self/cgroup"
+	defaultMountInfoPath = "/proc/self/mountinfo"
+)
+
+var (
+	// cgroupContainerID matches the container ID at the end of a cgroup v1 path, e.g.
+	// "/docker/<id>", "/kubepods/burstable/pod<uid>/<id>" or "/system.slice/crio-<id>.scope".
+	cgroupContainerID = regexp.MustCompile(`([0-9a-f]{64})(?:\.scope)?$`)
+
+	// mountInfoContainerID matches the container ID in the source of the files mounted by
+	// the container runtime with cgroup v2, e.g. "/var/lib/docker/containers/<id>/hostname".
+	// The "/sandboxes/<id>/" files mounted by containerd belong to the pod sandbox, whose
+	// ID is not the one of the container.
+	mountInfoContainerID = regexp.MustCompile(`/containers/([0-9a-f]{64})/`)
+)
+
+// Container returns a Detector of the ID of the container the process runs in, read
+// from /proc/self/cgroup with cgroup v1, or from /proc/self/mountinfo with cgroup v2.
+// It detects nothing outside of a container, or on systems without these files.
+func Container() Detector {
+	return &containerDetector{cgroupPath: defaultCgroupPath, mountInfoPath: defaultMountInfoPath}
+}
+
+type containerDetector struct {
+	cgroupPath    string
+	mountInfoPath string
+}
+
+func (d *containerDetector) Detect() ([]opentracing.Tag, error) {
+	id, err := findInFile(d.cgroupPath, func(line string) string {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) < 3 {
+			return ""
+		}
+		return submatch(cgroupContainerID, parts[2])
+	})
+	if id == "" && err == nil {
+		id, err = findInFile(d.mountInfoPath, func(line string) string {
+			return submatch(mountInfoContainerID, line)
+		})
+	}
+	if id == "" {
+		return nil, err
+	}
+	return []opentracing.Tag{{Key: ContainerIDTagKey, Value: id}}, nil
+}
+
+// findInFile returns the first non-empty value returned by match for the lines of the file.
+// A missing file is not an error, since the process might not run on Linux.
+func findInFile(path string, match func(line string) string) (string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value := match(scanner.Text()); value != "" {
+			return value, nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+func submatch(re *regexp.Regexp, s string) string {
+	if m := re.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return ""
+}
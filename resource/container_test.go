@@ -0,0 +1,109 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	containerID = "3c0d2b33ebbd2b3f3b9fcb2bd8e0b2c8d2e1c2c33a0b7d1f3e4b2d1e0c9a8b7f"
+	sandboxID   = "9f1e8d7c6b5a49382716a5b4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4"
+)
+
+func TestContainer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jaeger-resource")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name      string
+		cgroup    string
+		mountInfo string
+		expected  string
+	}{
+		{
+			name:     "docker",
+			cgroup:   "12:pids:/docker/" + containerID + "\n11:memory:/docker/" + containerID,
+			expected: containerID,
+		},
+		{
+			name:     "kubernetes",
+			cgroup:   "1:name=systemd:/kubepods/burstable/pod2c48913c-b29f-11e7-9350-020968147796/" + containerID,
+			expected: containerID,
+		},
+		{
+			name:     "systemd scope",
+			cgroup:   "0::/system.slice/crio-" + containerID + ".scope",
+			expected: containerID,
+		},
+		{
+			name:   "cgroup v2",
+			cgroup: "0::/",
+			mountInfo: "1 0 8:1 / / rw - ext4 /dev/sda1 rw\n" +
+				"2 1 8:1 /var/lib/docker/containers/" + containerID + "/hostname /etc/hostname rw - ext4 /dev/sda1 rw",
+			expected: containerID,
+		},
+		{
+			name:   "containerd",
+			cgroup: "0::/",
+			mountInfo: "1 0 0:31 / / rw - overlay overlay rw\n" +
+				"2 1 259:1 /var/lib/kubelet/pods/2c48913c-b29f-11e7-9350-020968147796/etc-hosts /etc/hosts rw - ext4 /dev/root rw\n" +
+				"3 1 259:1 /var/lib/kubelet/pods/2c48913c-b29f-11e7-9350-020968147796/containers/app/0d1e2f3a /dev/termination-log rw - ext4 /dev/root rw\n" +
+				"4 1 259:1 /var/lib/containerd/io.containerd.grpc.v1.cri/sandboxes/" + sandboxID + "/hostname /etc/hostname rw - ext4 /dev/root rw\n" +
+				"5 1 259:1 /var/lib/containerd/io.containerd.grpc.v1.cri/sandboxes/" + sandboxID + "/resolv.conf /etc/resolv.conf rw - ext4 /dev/root rw",
+		},
+		{
+			name:      "host",
+			cgroup:    "0::/user.slice/user-1000.slice/session-1.scope",
+			mountInfo: "1 0 8:1 / / rw - ext4 /dev/sda1 rw",
+		},
+		{
+			name: "no proc files",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			detector := &containerDetector{
+				cgroupPath:    filepath.Join(dir, strings.Replace(test.name, " ", "-", -1)+"-cgroup"),
+				mountInfoPath: filepath.Join(dir, strings.Replace(test.name, " ", "-", -1)+"-mountinfo"),
+			}
+			if test.cgroup != "" {
+				require.NoError(t, ioutil.WriteFile(detector.cgroupPath, []byte(test.cgroup), 0600))
+			}
+			if test.mountInfo != "" {
+				require.NoError(t, ioutil.WriteFile(detector.mountInfoPath, []byte(test.mountInfo), 0600))
+			}
+			tags, err := detector.Detect()
+			require.NoError(t, err)
+			if test.expected == "" {
+				assert.Empty(t, tags)
+			} else {
+				assert.Equal(t, []opentracing.Tag{{Key: ContainerIDTagKey, Value: test.expected}}, tags)
+			}
+		})
+	}
+
+	_, err = (&containerDetector{cgroupPath: dir}).Detect()
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resource provides detectors of the attributes of the environment the
+// process runs in, such as its container or Kubernetes pod, to be added to the
+// tracer-level tags reported with the process of every span.
+package resource
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// Tag keys set by the detectors, following the OpenTelemetry semantic conventions where they exist.
+const (
+	ContainerIDTagKey           = "container.id"
+	K8SPodNameTagKey            = "k8s.pod.name"
+	K8SPodUIDTagKey             = "k8s.pod.uid"
+	K8SNamespaceTagKey          = "k8s.namespace.name"
+	K8SNodeNameTagKey           = "k8s.node.name"
+	ProcessPIDTagKey            = "process.pid"
+	ProcessExecutableNameTagKey = "process.executable.name"
+	ProcessExecutablePathTagKey = "process.executable.path"
+	RuntimeNameTagKey           = "process.runtime.name"
+	RuntimeVersionTagKey        = "process.runtime.version"
+	BuildModulePathTagKey       = "build.module.path"
+	BuildModuleVersionTagKey    = "build.module.version"
+)
+
+// Detector detects attributes of the environment the process runs in.
+// A detector that does not apply to the current environment, e.g. Kubernetes
+// outside of a cluster, returns no tags and no error.
+type Detector interface {
+	Detect() ([]opentracing.Tag, error)
+}
+
+// DetectorFunc is an adapter to use an ordinary function as a Detector.
+type DetectorFunc func() ([]opentracing.Tag, error)
+
+// Detect implements Detector.
+func (f DetectorFunc) Detect() ([]opentracing.Tag, error) {
+	return f()
+}
+
+// Detect runs the detectors in order and returns the tags they detected. If a tag key
+// is detected more than once, the first value is kept. The detectors that fail do not
+// prevent the others from running, and the first error is returned along with the tags.
+func Detect(detectors ...Detector) ([]opentracing.Tag, error) {
+	var tags []opentracing.Tag
+	var firstErr error
+	seen := make(map[string]bool)
+	for _, detector := range detectors {
+		detected, err := detector.Detect()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		for _, tag := range detected {
+			if !seen[tag.Key] {
+				seen[tag.Key] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags, firstErr
+}
+
+// GoRuntime returns a Detector of the name and version of the Go runtime.
+func GoRuntime() Detector {
+	return DetectorFunc(func() ([]opentracing.Tag, error) {
+		return []opentracing.Tag{
+			{Key: RuntimeNameTagKey, Value: "go"},
+			{Key: RuntimeVersionTagKey, Value: runtime.Version()},
+		}, nil
+	})
+}
+
+// Process returns a Detector of the PID and the executable of the process.
+func Process() Detector {
+	return DetectorFunc(func() ([]opentracing.Tag, error) {
+		tags := []opentracing.Tag{{Key: ProcessPIDTagKey, Value: os.Getpid()}}
+		executable, err := os.Executable()
+		if err != nil {
+			return tags, fmt.Errorf("cannot detect the process executable: %v", err)
+		}
+		return append(tags,
+			opentracing.Tag{Key: ProcessExecutableNameTagKey, Value: filepath.Base(executable)},
+			opentracing.Tag{Key: ProcessExecutablePathTagKey, Value: executable},
+		), nil
+	})
+}
+
+// BuildInfo returns a Detector of the path and version of the main module, as embedded
+// in the binary by the Go toolchain. The version is omitted for development builds.
+func BuildInfo() Detector {
+	return DetectorFunc(func() ([]opentracing.Tag, error) {
+		return buildInfoTags(debug.ReadBuildInfo())
+	})
+}
+
+func buildInfoTags(info *debug.BuildInfo, ok bool) ([]opentracing.Tag, error) {
+	if !ok || info.Main.Path == "" {
+		// binaries built without module support carry no build information
+		return nil, nil
+	}
+	tags := []opentracing.Tag{{Key: BuildModulePathTagKey, Value: info.Main.Path}}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		tags = append(tags, opentracing.Tag{Key: BuildModuleVersionTagKey, Value: info.Main.Version})
+	}
+	return tags, nil
+}
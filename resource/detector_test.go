@@ -0,0 +1,94 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tagsMap(tags []opentracing.Tag) map[string]interface{} {
+	m := make(map[string]interface{}, len(tags))
+	for _, tag := range tags {
+		m[tag.Key] = tag.Value
+	}
+	return m
+}
+
+func TestDetect(t *testing.T) {
+	failing := DetectorFunc(func() ([]opentracing.Tag, error) {
+		return []opentracing.Tag{{Key: "partial", Value: true}}, errors.New("detection failed")
+	})
+	tags, err := Detect(
+		DetectorFunc(func() ([]opentracing.Tag, error) {
+			return []opentracing.Tag{{Key: "a", Value: 1}}, nil
+		}),
+		failing,
+		DetectorFunc(func() ([]opentracing.Tag, error) {
+			return []opentracing.Tag{{Key: "a", Value: 2}, {Key: "b", Value: 3}}, errors.New("ignored")
+		}),
+	)
+	assert.EqualError(t, err, "detection failed")
+	assert.Equal(t, []opentracing.Tag{
+		{Key: "a", Value: 1},
+		{Key: "partial", Value: true},
+		{Key: "b", Value: 3},
+	}, tags)
+}
+
+func TestGoRuntime(t *testing.T) {
+	tags, err := GoRuntime().Detect()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		RuntimeNameTagKey:    "go",
+		RuntimeVersionTagKey: runtime.Version(),
+	}, tagsMap(tags))
+}
+
+func TestProcess(t *testing.T) {
+	tags, err := Process().Detect()
+	require.NoError(t, err)
+	m := tagsMap(tags)
+	assert.Equal(t, os.Getpid(), m[ProcessPIDTagKey])
+	assert.Contains(t, m[ProcessExecutablePathTagKey], m[ProcessExecutableNameTagKey])
+	assert.Contains(t, m[ProcessExecutableNameTagKey], "resource.test")
+}
+
+func TestBuildInfo(t *testing.T) {
+	_, err := BuildInfo().Detect()
+	require.NoError(t, err)
+
+	tags, err := buildInfoTags(&debug.BuildInfo{Main: debug.Module{Path: "example.com/svc", Version: "v1.2.3"}}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []opentracing.Tag{
+		{Key: BuildModulePathTagKey, Value: "example.com/svc"},
+		{Key: BuildModuleVersionTagKey, Value: "v1.2.3"},
+	}, tags)
+
+	tags, err = buildInfoTags(&debug.BuildInfo{Main: debug.Module{Path: "example.com/svc", Version: "(devel)"}}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []opentracing.Tag{{Key: BuildModulePathTagKey, Value: "example.com/svc"}}, tags)
+
+	tags, err = buildInfoTags(nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+}
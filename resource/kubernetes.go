@@ -0,0 +1,100 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+const (
+	// defaultPodInfoDir is where the pod metadata is conventionally mounted with a downward API volume.
+	defaultPodInfoDir = "/etc/podinfo"
+
+	// defaultServiceAccountNamespacePath is the namespace file mounted with the service account token.
+	defaultServiceAccountNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// Kubernetes returns a Detector of the pod name, UID and namespace and of the node name, read from:
+//   - the POD_NAME, POD_UID, POD_NAMESPACE and NODE_NAME environment variables (or the same with
+//     a K8S_ prefix instead), usually populated with the downward API,
+//   - the name, uid and namespace files of a downward API volume mounted at /etc/podinfo,
+//   - the namespace of the service account, and the host name for the pod name.
+//
+// It detects nothing outside of a Kubernetes cluster.
+func Kubernetes() Detector {
+	return &kubernetesDetector{
+		podInfoDir:    defaultPodInfoDir,
+		namespacePath: defaultServiceAccountNamespacePath,
+		getenv:        os.Getenv,
+		hostname:      os.Hostname,
+	}
+}
+
+type kubernetesDetector struct {
+	podInfoDir    string
+	namespacePath string
+	getenv        func(string) string
+	hostname      func() (string, error)
+}
+
+func (d *kubernetesDetector) Detect() ([]opentracing.Tag, error) {
+	if d.getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return nil, nil
+	}
+	var tags []opentracing.Tag
+	add := func(key string, values ...func() string) {
+		for _, value := range values {
+			if v := value(); v != "" {
+				tags = append(tags, opentracing.Tag{Key: key, Value: v})
+				return
+			}
+		}
+	}
+	add(K8SPodNameTagKey, d.env("POD_NAME"), d.file(d.podInfoDir, "name"), func() string {
+		// the host name of a pod is its name, unless overridden in the pod spec
+		hostname, _ := d.hostname()
+		return hostname
+	})
+	add(K8SPodUIDTagKey, d.env("POD_UID"), d.file(d.podInfoDir, "uid"))
+	add(K8SNamespaceTagKey, d.env("POD_NAMESPACE"), d.file(d.podInfoDir, "namespace"), d.file(d.namespacePath))
+	add(K8SNodeNameTagKey, d.env("NODE_NAME"))
+	return tags, nil
+}
+
+// env returns the value of the environment variable, or of the same variable with a K8S_ prefix.
+func (d *kubernetesDetector) env(name string) func() string {
+	return func() string {
+		if v := d.getenv(name); v != "" {
+			return v
+		}
+		return d.getenv("K8S_" + name)
+	}
+}
+
+// file returns the trimmed content of the file, or an empty string if it cannot be read.
+func (d *kubernetesDetector) file(elem ...string) func() string {
+	return func() string {
+		content, err := ioutil.ReadFile(filepath.Join(elem...))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(content))
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubernetes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jaeger-resource")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	podInfoDir := filepath.Join(dir, "podinfo")
+	require.NoError(t, os.Mkdir(podInfoDir, 0700))
+	namespacePath := filepath.Join(dir, "namespace")
+	require.NoError(t, ioutil.WriteFile(namespacePath, []byte("sa-namespace\n"), 0600))
+
+	env := map[string]string{}
+	detector := &kubernetesDetector{
+		podInfoDir:    podInfoDir,
+		namespacePath: namespacePath,
+		getenv:        func(name string) string { return env[name] },
+		hostname:      func() (string, error) { return "hostname-pod", nil },
+	}
+
+	tags, err := detector.Detect()
+	require.NoError(t, err)
+	assert.Empty(t, tags, "outside of a cluster")
+
+	env["KUBERNETES_SERVICE_HOST"] = "10.0.0.1"
+	tags, err = detector.Detect()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		K8SPodNameTagKey:   "hostname-pod",
+		K8SNamespaceTagKey: "sa-namespace",
+	}, tagsMap(tags))
+
+	for name, content := range map[string]string{"name": "volume-pod", "uid": "volume-uid", "namespace": "volume-ns"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(podInfoDir, name), []byte(content+"\n"), 0600))
+	}
+	tags, err = detector.Detect()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		K8SPodNameTagKey:   "volume-pod",
+		K8SPodUIDTagKey:    "volume-uid",
+		K8SNamespaceTagKey: "volume-ns",
+	}, tagsMap(tags))
+
+	env["POD_NAME"] = "env-pod"
+	env["K8S_POD_UID"] = "env-uid"
+	env["POD_NAMESPACE"] = "env-ns"
+	env["NODE_NAME"] = "env-node"
+	tags, err = detector.Detect()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		K8SPodNameTagKey:   "env-pod",
+		K8SPodUIDTagKey:    "env-uid",
+		K8SNamespaceTagKey: "env-ns",
+		K8SNodeNameTagKey:  "env-node",
+	}, tagsMap(tags))
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+	"runtime/trace"
+	"sync"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// The keys of the runtime/trace logs annotating the tasks and regions of the spans.
+const (
+	RuntimeTraceTraceIDKey   = "jaeger.trace_id"
+	RuntimeTraceSpanIDKey    = "jaeger.span_id"
+	RuntimeTraceOperationKey = "jaeger.operation"
+)
+
+// NewRuntimeTraceObserver returns an observer that makes the spans visible in the
+// execution traces of the Go runtime (see runtime/trace), to cross-reference the
+// output of `go tool trace` with the Jaeger UI. While the execution tracer is enabled,
+// a trace.Task is created for each local root span, i.e. each span without a parent
+// in the process, and a trace.Region within this task for each of its descendants.
+// The tasks and regions are named after the operations, and annotated with the
+// jaeger.trace_id and jaeger.span_id logs.
+//
+// As the regions of the Go runtime, the child spans must be finished on the goroutine
+// that started them, and in the reverse order they were started. The child spans of
+// Tracer.Go and Group.Go, which are started on the calling goroutine and finished on
+// the new one, have no region, and are only annotated with the jaeger.span_id and
+// jaeger.operation logs.
+func NewRuntimeTraceObserver() ContribObserver {
+	return &runtimeTraceObserver{}
+}
+
+type runtimeTraceObserver struct {
+	// tasks holds the context of the task of each unfinished local root span.
+	tasks sync.Map // runtimeTraceKey -> context.Context
+}
+
+type runtimeTraceKey struct {
+	traceID TraceID
+	spanID  SpanID
+}
+
+func (o *runtimeTraceObserver) OnStartSpan(
+	sp opentracing.Span,
+	operationName string,
+	options opentracing.StartSpanOptions,
+) (ContribSpanObserver, bool) {
+	if !trace.IsEnabled() {
+		return nil, false
+	}
+	span, ok := sp.(*Span)
+	if !ok || span.context.samplingState == nil {
+		return nil, false
+	}
+	ctx := span.context
+	localRoot := runtimeTraceKey{traceID: ctx.traceID, spanID: ctx.samplingState.localRootSpan}
+	if localRoot.spanID == ctx.spanID {
+		taskCtx, task := trace.NewTask(context.Background(), operationName)
+		trace.Log(taskCtx, RuntimeTraceTraceIDKey, ctx.traceID.String())
+		trace.Log(taskCtx, RuntimeTraceSpanIDKey, ctx.spanID.String())
+		o.tasks.Store(localRoot, taskCtx)
+		return &runtimeTraceTask{observer: o, key: localRoot, task: task}, true
+	}
+	taskCtx := context.Background()
+	if v, ok := o.tasks.Load(localRoot); ok {
+		taskCtx = v.(context.Context)
+	}
+	trace.Log(taskCtx, RuntimeTraceSpanIDKey, ctx.spanID.String())
+	if span.goroutineTask {
+		// a region must end on the goroutine that started it, so the spans of Tracer.Go
+		// and Group.Go are only annotated with logs in the task of their local root span
+		trace.Log(taskCtx, RuntimeTraceOperationKey, operationName)
+		return nil, false
+	}
+	return &runtimeTraceRegion{region: trace.StartRegion(taskCtx, operationName)}, true
+}
+
+type runtimeTraceTask struct {
+	observer *runtimeTraceObserver
+	key      runtimeTraceKey
+	task     *trace.Task
+}
+
+func (t *runtimeTraceTask) OnSetOperationName(operationName string) {}
+
+func (t *runtimeTraceTask) OnSetTag(key string, value interface{}) {}
+
+func (t *runtimeTraceTask) OnFinish(options opentracing.FinishOptions) {
+	t.observer.tasks.Delete(t.key)
+	t.task.End()
+}
+
+type runtimeTraceRegion struct {
+	region *trace.Region
+}
+
+func (r *runtimeTraceRegion) OnSetOperationName(operationName string) {}
+
+func (r *runtimeTraceRegion) OnSetTag(key string, value interface{}) {}
+
+func (r *runtimeTraceRegion) OnFinish(options opentracing.FinishOptions) {
+	r.region.End()
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"bytes"
+	"context"
+	"runtime/trace"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeTraceObserver(t *testing.T) {
+	observer := NewRuntimeTraceObserver().(*runtimeTraceObserver)
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter(),
+		TracerOptions.ContribObserver(observer))
+	defer closer.Close()
+
+	// no tasks nor regions while the execution tracer is disabled
+	span := tracer.StartSpan("disabled-op").(*Span)
+	assert.Equal(t, noopSpanObserver, span.observer)
+	span.Finish()
+
+	var buf bytes.Buffer
+	require.NoError(t, trace.Start(&buf))
+	root := tracer.StartSpan("root-op").(*Span)
+	_, ok := observer.tasks.Load(runtimeTraceKey{traceID: root.context.traceID, spanID: root.context.spanID})
+	assert.True(t, ok)
+	child := tracer.StartSpan("child-op", opentracing.ChildOf(root.Context())).(*Span)
+	child.Finish()
+	root.Finish()
+	orphan := tracer.StartSpan("orphan-op", opentracing.ChildOf(root.Context())).(*Span)
+	orphan.Finish()
+	trace.Stop()
+
+	_, ok = observer.tasks.Load(runtimeTraceKey{traceID: root.context.traceID, spanID: root.context.spanID})
+	assert.False(t, ok, "the task is forgotten when the local root span finishes")
+	out := buf.String()
+	for _, s := range []string{"root-op", "child-op", "orphan-op", RuntimeTraceTraceIDKey, root.context.traceID.String(), child.context.spanID.String()} {
+		assert.Contains(t, out, s)
+	}
+	assert.NotContains(t, out, "disabled-op")
+}
+
+func TestRuntimeTraceObserverGo(t *testing.T) {
+	observer := NewRuntimeTraceObserver()
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter(),
+		TracerOptions.ContribObserver(observer))
+	defer closer.Close()
+
+	var buf bytes.Buffer
+	require.NoError(t, trace.Start(&buf))
+	defer trace.Stop()
+	root, ctx := tracer.(*Tracer).StartSpanFromContext(context.Background(), "root-op")
+	done := make(chan *Span)
+	tracer.(*Tracer).Go(ctx, "go-op", func(ctx context.Context) error {
+		span := SpanFromContext(ctx)
+		assert.Equal(t, noopSpanObserver, span.observer, "no region for a span finished on another goroutine")
+		done <- span
+		return nil
+	})
+	child := <-done
+	group, ctx := tracer.(*Tracer).NewGroup(ctx)
+	group.Go("group-op", func(ctx context.Context) error {
+		assert.Equal(t, noopSpanObserver, SpanFromContext(ctx).observer)
+		return nil
+	})
+	require.NoError(t, group.Wait())
+	root.Finish()
+	trace.Stop()
+
+	out := buf.String()
+	for _, s := range []string{"go-op", "group-op", RuntimeTraceOperationKey, child.context.spanID.String()} {
+		assert.Contains(t, out, s)
+	}
+}
+
+func TestRuntimeTraceObserverOtherSpans(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, trace.Start(&buf))
+	defer trace.Stop()
+
+	observer := NewRuntimeTraceObserver()
+	_, ok := observer.OnStartSpan(opentracing.NoopTracer{}.StartSpan("op"), "op", opentracing.StartSpanOptions{})
+	assert.False(t, ok)
+}
@@ -15,11 +15,15 @@
 package jaeger
 
 import (
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -31,9 +35,17 @@ import (
 const (
 	defaultRemoteSamplingTimeout   = 10 * time.Second
 	defaultSamplingRefreshInterval = time.Minute
+	defaultSamplingMaxBackoff      = 10 * time.Minute
 )
 
+// ErrSamplingStrategyNotModified can be returned by a SamplingStrategyFetcher to indicate
+// that the strategy has not changed since the previous successful fetch, in which case
+// RemotelyControlledSampler skips parsing and applying the response.
+var ErrSamplingStrategyNotModified = errors.New("sampling strategy not modified")
+
 // SamplingStrategyFetcher is used to fetch sampling strategy updates from remote server.
+// Fetch may return ErrSamplingStrategyNotModified if the server indicated that the strategy
+// is unchanged since the previous call.
 type SamplingStrategyFetcher interface {
 	Fetch(service string) ([]byte, error)
 }
@@ -70,6 +82,18 @@ type RemotelyControlledSampler struct {
 
 	serviceName string
 	doneChan    chan *sync.WaitGroup
+
+	// failures and nextPoll are only accessed by the polling goroutine
+	failures int
+	nextPoll time.Time
+
+	pollState struct {
+		sync.Mutex
+		lastUpdateTime time.Time
+		lastError      error
+		lastErrorTime  time.Time
+		pollCount      int64
+	}
 }
 
 // NewRemotelyControlledSampler creates a sampler that periodically pulls
@@ -84,6 +108,7 @@ func NewRemotelyControlledSampler(
 		serviceName:    serviceName,
 		doneChan:       make(chan *sync.WaitGroup),
 	}
+	sampler.loadCachedStrategy()
 	go sampler.pollController()
 	return sampler
 }
@@ -143,6 +168,18 @@ func (s *RemotelyControlledSampler) Equal(other Sampler) bool {
 }
 
 func (s *RemotelyControlledSampler) pollController() {
+	// Delay the first poll by a random fraction of the refresh interval so that
+	// a fleet of processes restarted at the same time does not poll in lockstep.
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(s.samplingRefreshInterval))))
+	select {
+	case now := <-timer.C:
+		s.poll(now)
+	case wg := <-s.doneChan:
+		timer.Stop()
+		wg.Done()
+		return
+	}
+
 	ticker := time.NewTicker(s.samplingRefreshInterval)
 	defer ticker.Stop()
 	s.pollControllerWithTicker(ticker)
@@ -151,8 +188,8 @@ func (s *RemotelyControlledSampler) pollController() {
 func (s *RemotelyControlledSampler) pollControllerWithTicker(ticker *time.Ticker) {
 	for {
 		select {
-		case <-ticker.C:
-			s.UpdateSampler()
+		case now := <-ticker.C:
+			s.poll(now)
 		case wg := <-s.doneChan:
 			wg.Done()
 			return
@@ -160,6 +197,39 @@ func (s *RemotelyControlledSampler) pollControllerWithTicker(ticker *time.Ticker
 	}
 }
 
+// poll updates the sampler, unless it is backing off after failures, in which case
+// the polls are skipped until the backoff interval of the last failure has elapsed.
+func (s *RemotelyControlledSampler) poll(now time.Time) {
+	if now.Before(s.nextPoll) {
+		return
+	}
+	if err := s.updateSampler(); err != nil {
+		s.failures++
+		s.nextPoll = now.Add(s.backoffInterval(s.failures))
+	} else {
+		s.failures = 0
+	}
+}
+
+// backoffInterval returns the delay before the next poll after the given number of
+// consecutive failures. The delay grows exponentially from samplingRefreshInterval,
+// is capped at samplingMaxBackoff and randomized to avoid synchronized retries.
+func (s *RemotelyControlledSampler) backoffInterval(failures int) time.Duration {
+	maxBackoff := s.samplingMaxBackoff
+	if maxBackoff < s.samplingRefreshInterval {
+		maxBackoff = s.samplingRefreshInterval
+	}
+	backoff := s.samplingRefreshInterval
+	for i := 0; i < failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
 // Sampler returns the currently active sampler.
 func (s *RemotelyControlledSampler) Sampler() SamplerV2 {
 	s.RLock()
@@ -172,32 +242,164 @@ func (s *RemotelyControlledSampler) setSampler(sampler SamplerV2) {
 	s.sampler = sampler
 }
 
+// State returns a snapshot of the currently applied sampling strategy,
+// along with statistics about polling the remote server.
+func (s *RemotelyControlledSampler) State() SamplerState {
+	s.RLock()
+	state := NewSamplerState(s.sampler)
+	s.RUnlock()
+
+	s.pollState.Lock()
+	defer s.pollState.Unlock()
+	if !s.pollState.lastUpdateTime.IsZero() {
+		lastUpdateTime := s.pollState.lastUpdateTime
+		state.LastUpdateTime = &lastUpdateTime
+	}
+	if s.pollState.lastError != nil {
+		state.LastError = s.pollState.lastError.Error()
+	}
+	if !s.pollState.lastErrorTime.IsZero() {
+		lastErrorTime := s.pollState.lastErrorTime
+		state.LastErrorTime = &lastErrorTime
+	}
+	state.PollCount = s.pollState.pollCount
+	return state
+}
+
 // UpdateSampler forces the sampler to fetch sampling strategy from backend server.
 // This function is called automatically on a timer, but can also be safely called manually, e.g. from tests.
 func (s *RemotelyControlledSampler) UpdateSampler() {
-	res, err := s.samplingFetcher.Fetch(s.serviceName)
+	_ = s.updateSampler()
+}
+
+func (s *RemotelyControlledSampler) updateSampler() error {
+	err := s.fetchAndApplyStrategy()
+	s.pollState.Lock()
+	defer s.pollState.Unlock()
+	s.pollState.pollCount++
+	if err != nil {
+		s.pollState.lastError = err
+		s.pollState.lastErrorTime = time.Now()
+	} else {
+		s.pollState.lastUpdateTime = time.Now()
+		s.pollState.lastError = nil
+	}
+	return err
+}
+
+func (s *RemotelyControlledSampler) fetchAndApplyStrategy() error {
+	var res []byte
+	var etag string
+	var err error
+	etagFetcher, hasETag := s.samplingFetcher.(etagSamplingStrategyFetcher)
+	if hasETag {
+		res, etag, err = etagFetcher.fetchWithETag(s.serviceName)
+	} else {
+		res, err = s.samplingFetcher.Fetch(s.serviceName)
+	}
+	if err == ErrSamplingStrategyNotModified {
+		s.metrics.SamplerNotModified.Inc(1)
+		return nil
+	}
 	if err != nil {
 		s.metrics.SamplerQueryFailure.Inc(1)
 		s.logger.Infof("failed to fetch sampling strategy: %v", err)
-		return
+		return err
 	}
 	strategy, err := s.samplingParser.Parse(res)
 	if err != nil {
 		s.metrics.SamplerUpdateFailure.Inc(1)
 		s.logger.Infof("failed to parse sampling strategy response: %v", err)
-		return
+		return err
 	}
 
 	s.Lock()
-	defer s.Unlock()
-
 	s.metrics.SamplerRetrieved.Inc(1)
 	if err := s.updateSamplerViaUpdaters(strategy); err != nil {
+		s.Unlock()
 		s.metrics.SamplerUpdateFailure.Inc(1)
 		s.logger.Infof("failed to handle sampling strategy response %+v. Got error: %v", res, err)
-		return
+		return err
 	}
+	s.Unlock()
 	s.metrics.SamplerUpdated.Inc(1)
+	s.storeCachedStrategy(res, etag)
+	if hasETag {
+		// only now that the strategy is applied can the server skip sending it again
+		etagFetcher.commitETag(etag)
+	}
+	return nil
+}
+
+// loadCachedStrategy applies the last known good strategy persisted by a previous
+// process, so that the sampler starts with the right rates before the first poll.
+func (s *RemotelyControlledSampler) loadCachedStrategy() {
+	if s.samplingCacheFile == "" {
+		return
+	}
+	res, err := ioutil.ReadFile(s.samplingCacheFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			s.logger.Infof("failed to read cached sampling strategy: %v", err)
+		}
+		return
+	}
+	strategy, err := s.samplingParser.Parse(res)
+	if err != nil {
+		s.logger.Infof("failed to parse cached sampling strategy: %v", err)
+		return
+	}
+	s.Lock()
+	err = s.updateSamplerViaUpdaters(strategy)
+	s.Unlock()
+	if err != nil {
+		s.logger.Infof("failed to apply cached sampling strategy: %v", err)
+		return
+	}
+	// the server can answer the first poll with 304 Not Modified if the cached
+	// strategy is still the current one
+	if etagFetcher, ok := s.samplingFetcher.(etagSamplingStrategyFetcher); ok {
+		if etag, err := ioutil.ReadFile(s.samplingCacheFile + etagFileSuffix); err == nil {
+			etagFetcher.commitETag(string(etag))
+		}
+	}
+}
+
+// etagFileSuffix is appended to the path of the cache file to store the ETag of
+// the cached strategy next to it.
+const etagFileSuffix = ".etag"
+
+// storeCachedStrategy persists the strategy response and its ETag, if any, so that
+// they can be loaded by loadCachedStrategy on the next start. The files are replaced
+// atomically, and the ETag is removed while the strategy is replaced so that it never
+// describes another strategy than the cached one.
+func (s *RemotelyControlledSampler) storeCachedStrategy(res []byte, etag string) {
+	if s.samplingCacheFile == "" {
+		return
+	}
+	etagFile := s.samplingCacheFile + etagFileSuffix
+	if err := os.Remove(etagFile); err != nil && !os.IsNotExist(err) {
+		s.logger.Infof("failed to remove cached sampling strategy ETag: %v", err)
+		return
+	}
+	if err := writeFileAtomically(s.samplingCacheFile, res); err != nil {
+		s.logger.Infof("failed to write cached sampling strategy: %v", err)
+		return
+	}
+	if etag == "" {
+		return
+	}
+	if err := writeFileAtomically(etagFile, []byte(etag)); err != nil {
+		s.logger.Infof("failed to write cached sampling strategy ETag: %v", err)
+	}
+}
+
+func writeFileAtomically(path string, data []byte) error {
+	tmpFile := path + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, path)
 }
 
 // NB: this function should only be called while holding a Write lock
@@ -298,33 +500,91 @@ func (u *AdaptiveSamplerUpdater) Update(sampler SamplerV2, strategy interface{})
 
 // -----------------------
 
+// etagSamplingStrategyFetcher is implemented by the fetchers supporting conditional
+// requests. The sampler commits the ETag of a response once its strategy is applied,
+// so that a strategy that failed to parse or apply is fetched again by the next poll.
+type etagSamplingStrategyFetcher interface {
+	fetchWithETag(serviceName string) (body []byte, etag string, err error)
+	commitETag(etag string)
+}
+
 type httpSamplingStrategyFetcher struct {
-	serverURL  string
-	logger     log.DebugLogger
-	httpClient http.Client
+	serverURL   string
+	logger      log.DebugLogger
+	httpClient  http.Client
+	credentials HTTPCredentials
+
+	sync.Mutex // guards etag
+	etag       string
+}
+
+func newHTTPSamplingStrategyFetcher(
+	serverURL string,
+	logger log.DebugLogger,
+	tlsConfig *tls.Config,
+	credentials HTTPCredentials,
+) *httpSamplingStrategyFetcher {
+	return &httpSamplingStrategyFetcher{
+		serverURL:   serverURL,
+		logger:      logger,
+		httpClient:  newSamplingHTTPClient(tlsConfig),
+		credentials: credentials,
+	}
 }
 
-func newHTTPSamplingStrategyFetcher(serverURL string, logger log.DebugLogger) *httpSamplingStrategyFetcher {
+// newSamplingHTTPClient returns the client used to fetch sampling strategies.
+// A nil tlsConfig selects the default TLS configuration.
+func newSamplingHTTPClient(tlsConfig *tls.Config) http.Client {
 	customTransport := http.DefaultTransport.(*http.Transport).Clone()
 	customTransport.ResponseHeaderTimeout = defaultRemoteSamplingTimeout
-
-	return &httpSamplingStrategyFetcher{
-		serverURL: serverURL,
-		logger:    logger,
-		httpClient: http.Client{
-			Transport: customTransport,
-		},
+	if tlsConfig != nil {
+		customTransport.TLSClientConfig = tlsConfig
+	}
+	return http.Client{
+		Transport: customTransport,
 	}
 }
 
+// Fetch implements SamplingStrategyFetcher. The ETag of the response is sent in the
+// If-None-Match header of the following calls.
 func (f *httpSamplingStrategyFetcher) Fetch(serviceName string) ([]byte, error) {
+	body, etag, err := f.fetchWithETag(serviceName)
+	if err == nil {
+		f.commitETag(etag)
+	}
+	return body, err
+}
+
+func (f *httpSamplingStrategyFetcher) commitETag(etag string) {
+	f.Lock()
+	defer f.Unlock()
+	f.etag = etag
+}
+
+func (f *httpSamplingStrategyFetcher) fetchWithETag(serviceName string) ([]byte, string, error) {
 	v := url.Values{}
 	v.Set("service", serviceName)
 	uri := f.serverURL + "?" + v.Encode()
 
-	resp, err := f.httpClient.Get(uri)
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	f.Lock()
+	etag := f.etag
+	f.Unlock()
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if f.credentials != nil {
+		if err := f.credentials.Apply(req); err != nil {
+			return nil, "", err
+		}
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
 	}
 
 	defer func() {
@@ -335,14 +595,18 @@ func (f *httpSamplingStrategyFetcher) Fetch(serviceName string) ([]byte, error)
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, "", ErrSamplingStrategyNotModified
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("StatusCode: %d, Body: %s", resp.StatusCode, body)
+		return nil, "", fmt.Errorf("StatusCode: %d, Body: %s", resp.StatusCode, body)
 	}
 
-	return body, nil
+	return body, resp.Header.Get("ETag"), nil
 }
 
 // -----------------------
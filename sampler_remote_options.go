@@ -15,6 +15,7 @@
 package jaeger
 
 import (
+	"crypto/tls"
 	"time"
 
 	"github.com/uber/jaeger-client-go/log"
@@ -38,6 +39,11 @@ type samplerOptions struct {
 	logger                  log.DebugLogger
 	samplingServerURL       string
 	samplingRefreshInterval time.Duration
+	samplingMaxBackoff      time.Duration
+	samplingCacheFile       string
+	thriftSamplingManager   bool
+	samplingTLSConfig       *tls.Config
+	samplingCredentials     HTTPCredentials
 	samplingFetcher         SamplingStrategyFetcher
 	samplingParser          SamplingStrategyParser
 	updaters                []SamplerUpdater
@@ -99,6 +105,55 @@ func (SamplerOptionsFactory) SamplingRefreshInterval(samplingRefreshInterval tim
 	}
 }
 
+// SamplingMaxBackoff creates a SamplerOption that sets the upper bound on the
+// exponentially growing delay between polls after consecutive failures to retrieve
+// the sampling strategy. Values below the refresh interval disable the backoff.
+func (SamplerOptionsFactory) SamplingMaxBackoff(samplingMaxBackoff time.Duration) SamplerOption {
+	return func(o *samplerOptions) {
+		o.samplingMaxBackoff = samplingMaxBackoff
+	}
+}
+
+// SamplingStrategyCacheFile creates a SamplerOption that sets the path of a file
+// where the last successfully applied sampling strategy is persisted, with its ETag in
+// the same path suffixed with ".etag". When the file exists at startup, its strategy is
+// applied before the first poll, which sends the ETag in the If-None-Match header.
+func (SamplerOptionsFactory) SamplingStrategyCacheFile(path string) SamplerOption {
+	return func(o *samplerOptions) {
+		o.samplingCacheFile = path
+	}
+}
+
+// ThriftSamplingManager creates a SamplerOption that makes the sampler retrieve sampling
+// strategies by calling the Thrift SamplingManager service at the sampling server URL,
+// using HTTP and the binary protocol, instead of the JSON endpoint. Unless overridden
+// with the SamplingStrategyFetcher and SamplingStrategyParser options, the sampler uses
+// a Thrift fetcher and ThriftSamplingStrategyParser.
+func (SamplerOptionsFactory) ThriftSamplingManager(enable bool) SamplerOption {
+	return func(o *samplerOptions) {
+		o.thriftSamplingManager = enable
+	}
+}
+
+// SamplingTLSConfig creates a SamplerOption that sets the TLS configuration used by the
+// default sampling strategy fetchers to connect to an https sampling server, e.g. to
+// provide a custom CA or a client certificate. It is ignored if a custom fetcher is
+// provided with the SamplingStrategyFetcher option.
+func (SamplerOptionsFactory) SamplingTLSConfig(tlsConfig *tls.Config) SamplerOption {
+	return func(o *samplerOptions) {
+		o.samplingTLSConfig = tlsConfig
+	}
+}
+
+// SamplingCredentials creates a SamplerOption that sets the credentials applied by the
+// default sampling strategy fetchers to every request sent to the sampling server.
+// It is ignored if a custom fetcher is provided with the SamplingStrategyFetcher option.
+func (SamplerOptionsFactory) SamplingCredentials(credentials HTTPCredentials) SamplerOption {
+	return func(o *samplerOptions) {
+		o.samplingCredentials = credentials
+	}
+}
+
 // SamplingStrategyFetcher creates a SamplerOption that initializes sampling strategy fetcher.
 func (SamplerOptionsFactory) SamplingStrategyFetcher(fetcher SamplingStrategyFetcher) SamplerOption {
 	return func(o *samplerOptions) {
@@ -139,11 +194,22 @@ func (o *samplerOptions) applyOptionsAndDefaults(opts ...SamplerOption) *sampler
 	if o.samplingRefreshInterval <= 0 {
 		o.samplingRefreshInterval = defaultSamplingRefreshInterval
 	}
+	if o.samplingMaxBackoff <= 0 {
+		o.samplingMaxBackoff = defaultSamplingMaxBackoff
+	}
 	if o.samplingFetcher == nil {
-		o.samplingFetcher = newHTTPSamplingStrategyFetcher(o.samplingServerURL, o.logger)
+		if o.thriftSamplingManager {
+			o.samplingFetcher = newThriftSamplingStrategyFetcher(o.samplingServerURL, o.logger, o.samplingTLSConfig, o.samplingCredentials)
+		} else {
+			o.samplingFetcher = newHTTPSamplingStrategyFetcher(o.samplingServerURL, o.logger, o.samplingTLSConfig, o.samplingCredentials)
+		}
 	}
 	if o.samplingParser == nil {
-		o.samplingParser = new(samplingStrategyParser)
+		if o.thriftSamplingManager {
+			o.samplingParser = ThriftSamplingStrategyParser{}
+		} else {
+			o.samplingParser = new(samplingStrategyParser)
+		}
 	}
 	if o.updaters == nil {
 		o.updaters = []SamplerUpdater{
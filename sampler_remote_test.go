@@ -15,8 +15,16 @@
 package jaeger
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -143,6 +151,7 @@ func TestRemoteSamplerOptions(t *testing.T) {
 		SamplerOptions.SamplingStrategyParser(parser),
 		SamplerOptions.Updaters(updaters...),
 	)
+	sampler.Close() // stop timer-based updates, the metrics above are not initialized
 	assert.Same(t, m, sampler.metrics)
 	assert.Equal(t, 42, sampler.posParams.MaxOperations)
 	assert.True(t, sampler.posParams.OperationNameLateBinding)
@@ -599,3 +608,310 @@ func TestRemotelyControlledSampler_printErrorForBrokenUpstream(t *testing.T) {
 	sampler.UpdateSampler()
 	assert.Contains(t, logger.String(), "failed to fetch sampling strategy:")
 }
+
+func TestHTTPSamplingStrategyFetcher_ETag(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"strategyType":"PROBABILISTIC","probabilisticSampling":{"samplingRate":0.5}}`))
+	}))
+	defer server.Close()
+
+	fetcher := newHTTPSamplingStrategyFetcher(server.URL, log.NullLogger, nil, nil)
+	res, err := fetcher.Fetch("svc")
+	require.NoError(t, err)
+	assert.Contains(t, string(res), "samplingRate")
+
+	res, err = fetcher.Fetch("svc")
+	assert.Equal(t, ErrSamplingStrategyNotModified, err)
+	assert.Nil(t, res)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+}
+
+func TestRemotelyControlledSampler_ETagCommittedAfterApply(t *testing.T) {
+	var requests int32
+	var ifNoneMatch []string
+	var mutex sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		mutex.Unlock()
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`not a strategy`))
+			return
+		}
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v2"`)
+		w.Write([]byte(`{"strategyType":"PROBABILISTIC","probabilisticSampling":{"samplingRate":0.5}}`))
+	}))
+	defer server.Close()
+
+	sampler := NewRemotelyControlledSampler(
+		"svc",
+		SamplerOptions.SamplingServerURL(server.URL),
+		SamplerOptions.SamplingRefreshInterval(time.Hour),
+	)
+	sampler.Close() // stop timer-based updates, we want to call them manually
+
+	require.Error(t, sampler.updateSampler(), "the first strategy cannot be parsed")
+	require.NoError(t, sampler.updateSampler())
+	require.NoError(t, sampler.updateSampler())
+
+	probabilistic, ok := sampler.Sampler().(*ProbabilisticSampler)
+	require.True(t, ok)
+	assert.Equal(t, 0.5, probabilistic.SamplingRate())
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []string{"", "", `"v2"`}, ifNoneMatch, "the ETag of a failed strategy is not sent")
+}
+
+type notModifiedSamplingFetcher struct{}
+
+func (c *notModifiedSamplingFetcher) Fetch(serviceName string) ([]byte, error) {
+	return nil, ErrSamplingStrategyNotModified
+}
+
+func TestRemotelyControlledSampler_notModified(t *testing.T) {
+	agent, sampler, metricsFactory := initAgent(t)
+	defer agent.Close()
+
+	sampler.samplingFetcher = &notModifiedSamplingFetcher{}
+	initSampler := sampler.Sampler()
+
+	sampler.UpdateSampler()
+	assert.Same(t, initSampler, sampler.Sampler())
+	metricsFactory.AssertCounterMetrics(t,
+		mTestutils.ExpectedMetric{Name: "jaeger.tracer.sampler_queries", Tags: map[string]string{"result": "not_modified"}, Value: 1},
+		mTestutils.ExpectedMetric{Name: "jaeger.tracer.sampler_queries", Tags: map[string]string{"result": "ok"}, Value: 0},
+		mTestutils.ExpectedMetric{Name: "jaeger.tracer.sampler_updates", Tags: map[string]string{"result": "ok"}, Value: 0},
+	)
+}
+
+func TestRemotelyControlledSampler_backoffInterval(t *testing.T) {
+	sampler := NewRemotelyControlledSampler(
+		"test",
+		SamplerOptions.SamplingRefreshInterval(time.Second),
+		SamplerOptions.SamplingMaxBackoff(10*time.Second),
+	)
+	sampler.Close()
+
+	tests := []struct {
+		failures int
+		max      time.Duration
+	}{
+		{failures: 1, max: 2 * time.Second},
+		{failures: 2, max: 4 * time.Second},
+		{failures: 3, max: 8 * time.Second},
+		{failures: 4, max: 10 * time.Second},
+		{failures: 100, max: 10 * time.Second},
+	}
+	for _, test := range tests {
+		for i := 0; i < 100; i++ {
+			backoff := sampler.backoffInterval(test.failures)
+			assert.True(t, backoff >= test.max/2 && backoff <= test.max,
+				"failures=%d, backoff=%v", test.failures, backoff)
+		}
+	}
+
+	sampler.samplingMaxBackoff = 0
+	assert.True(t, sampler.backoffInterval(5) <= time.Second, "max backoff below refresh interval")
+}
+
+type countingSamplingFetcher struct {
+	calls int32
+}
+
+func (c *countingSamplingFetcher) Fetch(serviceName string) ([]byte, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return nil, errors.New("query error")
+}
+
+func TestRemotelyControlledSampler_pollBackoff(t *testing.T) {
+	fetcher := &countingSamplingFetcher{}
+	sampler := NewRemotelyControlledSampler(
+		"test",
+		SamplerOptions.SamplingRefreshInterval(time.Minute),
+		SamplerOptions.SamplingMaxBackoff(time.Hour),
+		SamplerOptions.SamplingStrategyFetcher(fetcher),
+	)
+	sampler.Close() // stop timer-based updates, we want to drive the ticker manually
+
+	c := make(chan time.Time)
+	ticker := &time.Ticker{C: c}
+	go sampler.pollControllerWithTicker(ticker)
+
+	start := time.Now()
+	c <- start // first failure, backs off for at least 1 minute
+	c <- start.Add(time.Second)
+	c <- start.Add(2 * time.Second)
+	c <- start.Add(2 * time.Minute) // beyond the max backoff after one failure
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	sampler.doneChan <- &wg
+	wg.Wait()
+
+	assert.EqualValues(t, 2, atomic.LoadInt32(&fetcher.calls))
+}
+
+func TestRemotelyControlledSampler_pollCountsFailures(t *testing.T) {
+	fetcher := &countingSamplingFetcher{}
+	sampler := NewRemotelyControlledSampler(
+		"test",
+		SamplerOptions.SamplingRefreshInterval(time.Minute),
+		SamplerOptions.SamplingStrategyFetcher(fetcher),
+	)
+	sampler.Close() // stop timer-based updates, we want to call them manually
+
+	// the jittered first poll goes through poll too, so its failure starts the backoff
+	start := time.Now()
+	sampler.poll(start)
+	sampler.poll(start.Add(time.Second))
+	assert.Equal(t, 1, sampler.failures)
+	assert.True(t, sampler.nextPoll.After(start))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&fetcher.calls))
+}
+
+func TestRemotelyControlledSampler_cacheFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jaeger-sampling")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cacheFile := filepath.Join(dir, "strategy.json")
+
+	agent, err := testutils.StartMockAgent()
+	require.NoError(t, err)
+	defer agent.Close()
+	agent.AddSamplingStrategy("client app",
+		getSamplingStrategyResponse(sampling.SamplingStrategyType_PROBABILISTIC, testDefaultSamplingProbability))
+
+	sampler := NewRemotelyControlledSampler(
+		"client app",
+		SamplerOptions.SamplingServerURL("http://"+agent.SamplingServerAddr()),
+		SamplerOptions.SamplingStrategyCacheFile(cacheFile),
+	)
+	sampler.Close() // stop timer-based updates, we want to call them manually
+	sampler.UpdateSampler()
+
+	cached, err := ioutil.ReadFile(cacheFile)
+	require.NoError(t, err)
+	var strategy sampling.SamplingStrategyResponse
+	require.NoError(t, json.Unmarshal(cached, &strategy))
+	assert.EqualValues(t, testDefaultSamplingProbability, strategy.ProbabilisticSampling.SamplingRate)
+
+	// a new sampler starts with the cached strategy without talking to the agent
+	restarted := NewRemotelyControlledSampler(
+		"client app",
+		SamplerOptions.SamplingStrategyFetcher(&fakeSamplingFetcher{}),
+		SamplerOptions.SamplingStrategyCacheFile(cacheFile),
+	)
+	restarted.Close()
+	s, ok := restarted.Sampler().(*ProbabilisticSampler)
+	require.True(t, ok)
+	assert.EqualValues(t, testDefaultSamplingProbability, s.SamplingRate())
+
+	// corrupted cache is ignored
+	require.NoError(t, ioutil.WriteFile(cacheFile, []byte("garbage"), 0644))
+	logger := &log.BytesBufferLogger{}
+	broken := NewRemotelyControlledSampler(
+		"client app",
+		SamplerOptions.Logger(logger),
+		SamplerOptions.SamplingStrategyFetcher(&fakeSamplingFetcher{}),
+		SamplerOptions.SamplingStrategyCacheFile(cacheFile),
+	)
+	broken.Close()
+	assert.Contains(t, logger.String(), "failed to parse cached sampling strategy")
+}
+
+func TestRemotelyControlledSampler_cacheFileETag(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jaeger-sampling")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cacheFile := filepath.Join(dir, "strategy.json")
+
+	var ifNoneMatch []string
+	var mutex sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		mutex.Unlock()
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"strategyType":"PROBABILISTIC","probabilisticSampling":{"samplingRate":0.5}}`))
+	}))
+	defer server.Close()
+
+	newSampler := func() *RemotelyControlledSampler {
+		sampler := NewRemotelyControlledSampler(
+			"svc",
+			SamplerOptions.SamplingServerURL(server.URL),
+			SamplerOptions.SamplingStrategyCacheFile(cacheFile),
+		)
+		sampler.Close() // stop timer-based updates, we want to call them manually
+		return sampler
+	}
+	require.NoError(t, newSampler().updateSampler())
+	etag, err := ioutil.ReadFile(cacheFile + ".etag")
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, string(etag))
+
+	// after a restart, the first poll does not download the cached strategy again
+	restarted := newSampler()
+	require.NoError(t, restarted.updateSampler())
+	probabilistic, ok := restarted.Sampler().(*ProbabilisticSampler)
+	require.True(t, ok)
+	assert.Equal(t, 0.5, probabilistic.SamplingRate())
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []string{"", `"v1"`}, ifNoneMatch)
+}
+
+func TestRemotelyControlledSampler_State(t *testing.T) {
+	agent, sampler, _ := initAgent(t)
+	defer agent.Close()
+
+	state := sampler.State()
+	assert.Equal(t, SamplerTypeProbabilistic, state.Type)
+	assert.Equal(t, map[string]interface{}{"samplingRate": 0.001}, state.Params)
+	assert.Zero(t, state.PollCount)
+	assert.Nil(t, state.LastUpdateTime)
+
+	agent.AddSamplingStrategy("client app",
+		getSamplingStrategyResponse(sampling.SamplingStrategyType_RATE_LIMITING, 5))
+	sampler.UpdateSampler()
+
+	state = sampler.State()
+	assert.Equal(t, SamplerTypeRateLimiting, state.Type)
+	assert.Equal(t, map[string]interface{}{"maxTracesPerSecond": 5.0}, state.Params)
+	assert.EqualValues(t, 1, state.PollCount)
+	assert.NotNil(t, state.LastUpdateTime)
+	assert.Empty(t, state.LastError)
+
+	sampler.samplingFetcher = &fakeSamplingFetcher{}
+	sampler.UpdateSampler()
+
+	state = sampler.State()
+	assert.Equal(t, SamplerTypeRateLimiting, state.Type)
+	assert.EqualValues(t, 2, state.PollCount)
+	assert.Equal(t, "query error", state.LastError)
+	assert.NotNil(t, state.LastErrorTime)
+	assert.Equal(t, state, NewSamplerState(sampler))
+
+	sampler.samplingFetcher = &notModifiedSamplingFetcher{}
+	sampler.UpdateSampler()
+
+	state = sampler.State()
+	assert.Empty(t, state.LastError, "the error is cleared by a successful poll")
+	assert.NotNil(t, state.LastErrorTime)
+}
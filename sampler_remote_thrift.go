@@ -0,0 +1,174 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/uber/jaeger-client-go/log"
+	"github.com/uber/jaeger-client-go/thrift"
+	"github.com/uber/jaeger-client-go/thrift-gen/sampling"
+)
+
+const (
+	thriftSamplingMethod      = "getSamplingStrategy"
+	thriftSamplingContentType = "application/x-thrift"
+)
+
+// thriftSamplingStrategyFetcher retrieves sampling strategies by calling the
+// getSamplingStrategy method of the Thrift SamplingManager service over HTTP,
+// using the binary protocol. The response body is the raw Thrift reply message,
+// which is decoded by ThriftSamplingStrategyParser.
+type thriftSamplingStrategyFetcher struct {
+	serverURL   string
+	logger      log.DebugLogger
+	httpClient  http.Client
+	credentials HTTPCredentials
+}
+
+func newThriftSamplingStrategyFetcher(
+	serverURL string,
+	logger log.DebugLogger,
+	tlsConfig *tls.Config,
+	credentials HTTPCredentials,
+) *thriftSamplingStrategyFetcher {
+	return &thriftSamplingStrategyFetcher{
+		serverURL:   serverURL,
+		logger:      logger,
+		httpClient:  newSamplingHTTPClient(tlsConfig),
+		credentials: credentials,
+	}
+}
+
+func (f *thriftSamplingStrategyFetcher) Fetch(serviceName string) ([]byte, error) {
+	request, err := serializeSamplingStrategyRequest(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, f.serverURL, bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", thriftSamplingContentType)
+	req.Header.Set("Accept", thriftSamplingContentType)
+	if f.credentials != nil {
+		if err := f.credentials.Apply(req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			f.logger.Error(fmt.Sprintf("failed to close HTTP response body: %+v", err))
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("StatusCode: %d, Body: %s", resp.StatusCode, body)
+	}
+
+	return body, nil
+}
+
+// serializeSamplingStrategyRequest encodes a getSamplingStrategy call message
+// for the given service with the Thrift binary protocol.
+func serializeSamplingStrategyRequest(serviceName string) ([]byte, error) {
+	ctx := context.Background()
+	buffer := thrift.NewTMemoryBuffer()
+	protocol := thrift.NewTBinaryProtocolConf(buffer, &thrift.TConfiguration{})
+
+	args := sampling.NewSamplingManagerGetSamplingStrategyArgs()
+	args.ServiceName = serviceName
+
+	if err := protocol.WriteMessageBegin(ctx, thriftSamplingMethod, thrift.CALL, 1); err != nil {
+		return nil, err
+	}
+	if err := args.Write(ctx, protocol); err != nil {
+		return nil, err
+	}
+	if err := protocol.WriteMessageEnd(ctx); err != nil {
+		return nil, err
+	}
+	if err := protocol.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// -----------------------
+
+// ThriftSamplingStrategyParser is a SamplingStrategyParser that decodes the reply
+// of the getSamplingStrategy method of the Thrift SamplingManager service, encoded
+// with the binary protocol, into *sampling.SamplingStrategyResponse. Exceptions
+// returned by the service are reported as errors.
+type ThriftSamplingStrategyParser struct{}
+
+// Parse implements Parse of SamplingStrategyParser.
+func (p ThriftSamplingStrategyParser) Parse(response []byte) (interface{}, error) {
+	ctx := context.Background()
+	buffer := thrift.NewTMemoryBuffer()
+	if _, err := buffer.Write(response); err != nil {
+		return nil, err
+	}
+	protocol := thrift.NewTBinaryProtocolConf(buffer, &thrift.TConfiguration{})
+
+	method, messageType, _, err := protocol.ReadMessageBegin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if method != thriftSamplingMethod {
+		return nil, fmt.Errorf("unexpected method %q in Thrift sampling strategy response", method)
+	}
+	switch messageType {
+	case thrift.REPLY:
+	case thrift.EXCEPTION:
+		exception := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "")
+		if err := exception.Read(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return nil, exception
+	default:
+		return nil, fmt.Errorf("unexpected message type %d in Thrift sampling strategy response", messageType)
+	}
+
+	result := sampling.NewSamplingManagerGetSamplingStrategyResult()
+	if err := result.Read(ctx, protocol); err != nil {
+		return nil, err
+	}
+	if err := protocol.ReadMessageEnd(ctx); err != nil {
+		return nil, err
+	}
+	if !result.IsSetSuccess() {
+		return nil, errors.New("Thrift sampling strategy response has no result")
+	}
+	return result.GetSuccess(), nil
+}
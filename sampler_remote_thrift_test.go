@@ -0,0 +1,190 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/jaeger-client-go/log"
+	"github.com/uber/jaeger-client-go/thrift"
+	"github.com/uber/jaeger-client-go/thrift-gen/sampling"
+)
+
+type testSamplingManager struct {
+	strategies map[string]*sampling.SamplingStrategyResponse
+}
+
+func (m *testSamplingManager) GetSamplingStrategy(
+	ctx context.Context,
+	serviceName string,
+) (*sampling.SamplingStrategyResponse, error) {
+	if s, ok := m.strategies[serviceName]; ok {
+		return s, nil
+	}
+	return nil, errors.New("no strategy for " + serviceName)
+}
+
+func startThriftSamplingServer(t *testing.T, manager sampling.SamplingManager) *httptest.Server {
+	processor := sampling.NewSamplingManagerProcessor(manager)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, thriftSamplingContentType, r.Header.Get("Content-Type"))
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		in := thrift.NewTMemoryBuffer()
+		_, err = in.Write(body)
+		require.NoError(t, err)
+		out := thrift.NewTMemoryBuffer()
+		// application errors are written to the client as Thrift exceptions
+		_, _ = processor.Process(
+			r.Context(),
+			thrift.NewTBinaryProtocolConf(in, &thrift.TConfiguration{}),
+			thrift.NewTBinaryProtocolConf(out, &thrift.TConfiguration{}),
+		)
+		w.Header().Set("Content-Type", thriftSamplingContentType)
+		_, _ = w.Write(out.Bytes())
+	}))
+}
+
+func TestThriftSamplingStrategyFetcher(t *testing.T) {
+	manager := &testSamplingManager{
+		strategies: map[string]*sampling.SamplingStrategyResponse{
+			"svc": {
+				StrategyType:          sampling.SamplingStrategyType_PROBABILISTIC,
+				ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{SamplingRate: 0.25},
+			},
+		},
+	}
+	server := startThriftSamplingServer(t, manager)
+	defer server.Close()
+
+	fetcher := newThriftSamplingStrategyFetcher(server.URL, log.NullLogger, nil, nil)
+	parser := ThriftSamplingStrategyParser{}
+
+	resp, err := fetcher.Fetch("svc")
+	require.NoError(t, err)
+	strategy, err := parser.Parse(resp)
+	require.NoError(t, err)
+	assert.Equal(t, manager.strategies["svc"], strategy)
+
+	resp, err = fetcher.Fetch("unknown")
+	require.NoError(t, err)
+	_, err = parser.Parse(resp)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no strategy for unknown")
+}
+
+func TestThriftSamplingStrategyFetcherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := newThriftSamplingStrategyFetcher(server.URL, log.NullLogger, nil, nil).Fetch("svc")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "StatusCode: 500")
+
+	_, err = newThriftSamplingStrategyFetcher("http://%", log.NullLogger, nil, nil).Fetch("svc")
+	assert.Error(t, err)
+}
+
+func TestThriftSamplingStrategyParserErrors(t *testing.T) {
+	serializeMessage := func(method string, messageType thrift.TMessageType, result thrift.TStruct) []byte {
+		ctx := context.Background()
+		buffer := thrift.NewTMemoryBuffer()
+		protocol := thrift.NewTBinaryProtocolConf(buffer, &thrift.TConfiguration{})
+		require.NoError(t, protocol.WriteMessageBegin(ctx, method, messageType, 1))
+		require.NoError(t, result.Write(ctx, protocol))
+		require.NoError(t, protocol.WriteMessageEnd(ctx))
+		require.NoError(t, protocol.Flush(ctx))
+		return buffer.Bytes()
+	}
+	emptyResult := sampling.NewSamplingManagerGetSamplingStrategyResult()
+
+	tests := []struct {
+		name     string
+		response []byte
+		err      string
+	}{
+		{
+			name:     "not thrift",
+			response: []byte("{}"),
+		},
+		{
+			name:     "wrong method",
+			response: serializeMessage("getBaggageRestrictions", thrift.REPLY, emptyResult),
+			err:      `unexpected method "getBaggageRestrictions"`,
+		},
+		{
+			name:     "wrong message type",
+			response: serializeMessage(thriftSamplingMethod, thrift.CALL, emptyResult),
+			err:      "unexpected message type 1",
+		},
+		{
+			name:     "no result",
+			response: serializeMessage(thriftSamplingMethod, thrift.REPLY, emptyResult),
+			err:      "has no result",
+		},
+		{
+			name: "exception",
+			response: serializeMessage(thriftSamplingMethod, thrift.EXCEPTION,
+				thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "oops")),
+			err: "oops",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ThriftSamplingStrategyParser{}.Parse(test.response)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestRemotelyControlledSampler_thriftSamplingManager(t *testing.T) {
+	manager := &testSamplingManager{
+		strategies: map[string]*sampling.SamplingStrategyResponse{
+			"svc": {
+				StrategyType:         sampling.SamplingStrategyType_RATE_LIMITING,
+				RateLimitingSampling: &sampling.RateLimitingSamplingStrategy{MaxTracesPerSecond: 7},
+			},
+		},
+	}
+	server := startThriftSamplingServer(t, manager)
+	defer server.Close()
+
+	sampler := NewRemotelyControlledSampler(
+		"svc",
+		SamplerOptions.SamplingServerURL(server.URL),
+		SamplerOptions.ThriftSamplingManager(true),
+	)
+	sampler.Close() // stop the background poller, updates are driven manually
+	assert.IsType(t, &thriftSamplingStrategyFetcher{}, sampler.samplingFetcher)
+	assert.IsType(t, ThriftSamplingStrategyParser{}, sampler.samplingParser)
+
+	require.NoError(t, sampler.updateSampler())
+	s, ok := sampler.Sampler().(*RateLimitingSampler)
+	require.True(t, ok)
+	assert.EqualValues(t, 7, s.maxTracesPerSecond)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const samplerTypePerOperation = "peroperation"
+
+// SamplerState is a snapshot of the sampling strategy applied by a sampler,
+// intended for introspection, e.g. rendering as JSON on an admin endpoint.
+type SamplerState struct {
+	// Type is the type of the sampler in lowercase, e.g. "probabilistic" or "peroperation",
+	// as in the sampler.type tag and the sampler configuration. Samplers that do not
+	// report their state are named after their Go type, e.g. "guaranteedthroughputprobabilistic".
+	Type string `json:"type"`
+
+	// Params are the parameters of the sampler, keyed by name.
+	Params map[string]interface{} `json:"params,omitempty"`
+
+	// Operations is the per-operation strategy table of a per-operation sampler.
+	Operations []OperationSamplerState `json:"operations,omitempty"`
+
+	// Delegates are the states of the samplers a composite sampler delegates to.
+	Delegates []SamplerState `json:"delegates,omitempty"`
+
+	// LastUpdateTime is the time the strategy was last successfully retrieved
+	// from the remote server, nil if it never was. Only set by RemotelyControlledSampler.
+	LastUpdateTime *time.Time `json:"lastUpdateTime,omitempty"`
+
+	// LastError is the error that occurred while retrieving or applying the strategy
+	// during the last poll, empty if it succeeded. Only set by RemotelyControlledSampler.
+	LastError string `json:"lastError,omitempty"`
+
+	// LastErrorTime is the time of the last failed poll, nil if no poll failed.
+	LastErrorTime *time.Time `json:"lastErrorTime,omitempty"`
+
+	// PollCount is the number of times the strategy was polled from the remote server.
+	// Only set by RemotelyControlledSampler.
+	PollCount int64 `json:"pollCount,omitempty"`
+}
+
+// OperationSamplerState describes the sampling strategy of a single operation.
+type OperationSamplerState struct {
+	Operation    string  `json:"operation"`
+	SamplingRate float64 `json:"samplingRate"`
+	LowerBound   float64 `json:"lowerBound"`
+}
+
+// NewSamplerState returns a snapshot of the state of the given sampler.
+// Samplers can describe themselves by implementing a State() SamplerState method,
+// otherwise only the type of the sampler is reported.
+func NewSamplerState(sampler SamplerV2) SamplerState {
+	switch s := sampler.(type) {
+	case interface{ State() SamplerState }:
+		return s.State()
+	case *ConstSampler:
+		return SamplerState{
+			Type:   SamplerTypeConst,
+			Params: map[string]interface{}{"decision": s.Decision},
+		}
+	case *ProbabilisticSampler:
+		return SamplerState{
+			Type:   SamplerTypeProbabilistic,
+			Params: map[string]interface{}{"samplingRate": s.samplingRate},
+		}
+	case *RateLimitingSampler:
+		return SamplerState{
+			Type:   SamplerTypeRateLimiting,
+			Params: map[string]interface{}{"maxTracesPerSecond": s.maxTracesPerSecond},
+		}
+	case *PerOperationSampler:
+		return s.state()
+	default:
+		return SamplerState{Type: samplerTypeName(sampler)}
+	}
+}
+
+// samplerTypeName returns the name of the Go type of the sampler in lowercase,
+// without the package and the Sampler suffix.
+func samplerTypeName(sampler SamplerV2) string {
+	name := fmt.Sprintf("%T", sampler)
+	name = name[strings.LastIndex(name, ".")+1:]
+	return strings.ToLower(strings.TrimSuffix(name, "Sampler"))
+}
+
+func (s *PerOperationSampler) state() SamplerState {
+	s.RLock()
+	defer s.RUnlock()
+	operations := make([]OperationSamplerState, 0, len(s.samplers))
+	for operation, sampler := range s.samplers {
+		operations = append(operations, OperationSamplerState{
+			Operation:    operation,
+			SamplingRate: sampler.samplingRate,
+			LowerBound:   sampler.lowerBound,
+		})
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].Operation < operations[j].Operation
+	})
+	return SamplerState{
+		Type: samplerTypePerOperation,
+		Params: map[string]interface{}{
+			"defaultSamplingProbability":       s.defaultSampler.SamplingRate(),
+			"defaultLowerBoundTracesPerSecond": s.lowerBound,
+			"maxOperations":                    s.maxOperations,
+			"operationNameLateBinding":         s.operationNameLateBinding,
+		},
+		Operations: operations,
+	}
+}
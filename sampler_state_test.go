@@ -0,0 +1,104 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/jaeger-client-go/thrift-gen/sampling"
+)
+
+func TestNewSamplerState(t *testing.T) {
+	perOperationSampler := NewPerOperationSampler(PerOperationSamplerParams{
+		MaxOperations: 42,
+		Strategies: &sampling.PerOperationSamplingStrategies{
+			DefaultSamplingProbability:       0.1,
+			DefaultLowerBoundTracesPerSecond: 0.5,
+			PerOperationStrategies: []*sampling.OperationSamplingStrategy{
+				{
+					Operation:             "op2",
+					ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{SamplingRate: 0.2},
+				},
+				{
+					Operation:             "op1",
+					ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{SamplingRate: 0.3},
+				},
+			},
+		},
+	})
+	tests := []struct {
+		name     string
+		sampler  SamplerV2
+		expected SamplerState
+	}{
+		{
+			name:    "const",
+			sampler: NewConstSampler(true),
+			expected: SamplerState{
+				Type:   SamplerTypeConst,
+				Params: map[string]interface{}{"decision": true},
+			},
+		},
+		{
+			name:    "probabilistic",
+			sampler: newProbabilisticSampler(0.25),
+			expected: SamplerState{
+				Type:   SamplerTypeProbabilistic,
+				Params: map[string]interface{}{"samplingRate": 0.25},
+			},
+		},
+		{
+			name:    "rate limiting",
+			sampler: NewRateLimitingSampler(10),
+			expected: SamplerState{
+				Type:   SamplerTypeRateLimiting,
+				Params: map[string]interface{}{"maxTracesPerSecond": 10.0},
+			},
+		},
+		{
+			name:    "per operation",
+			sampler: perOperationSampler,
+			expected: SamplerState{
+				Type: "peroperation",
+				Params: map[string]interface{}{
+					"defaultSamplingProbability":       0.1,
+					"defaultLowerBoundTracesPerSecond": 0.5,
+					"maxOperations":                    42,
+					"operationNameLateBinding":         false,
+				},
+				Operations: []OperationSamplerState{
+					{Operation: "op1", SamplingRate: 0.3, LowerBound: 0.5},
+					{Operation: "op2", SamplingRate: 0.2, LowerBound: 0.5},
+				},
+			},
+		},
+		{
+			name:     "unknown",
+			sampler:  samplerV1toV2(new(unknownSampler)),
+			expected: SamplerState{Type: "legacysamplerv1tov2adapter"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, NewSamplerState(test.sampler))
+		})
+	}
+}
+
+type unknownSampler struct {
+	SamplerV2Base
+}
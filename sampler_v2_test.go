@@ -199,7 +199,7 @@ func TestSpanIsWriteableIfSampledOrNotFinalized(t *testing.T) {
 	assert.False(t, span.context.IsSampled(), "span is not sampled")
 	assert.True(t, span.context.isWriteable(), "span is writeable")
 
-	tracer.(*Tracer).sampler = NewConstSampler(true)
+	tracer.(*Tracer).Reconfigure(ReconfigureOptions.Sampler(NewConstSampler(true)))
 	span = tracer.StartSpan("span").(*Span)
 	assert.True(t, span.context.isSamplingFinalized(), "span is finalized when created")
 	assert.True(t, span.context.IsSampled(), "span is sampled")
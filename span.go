@@ -15,6 +15,7 @@
 package jaeger
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -46,10 +47,11 @@ type Span struct {
 	// and the ingress spans when the process joins another trace.
 	firstInProcess bool
 
-	// startTime is the timestamp indicating when the span began, with microseconds precision.
+	// startTime is the timestamp indicating when the span began, with the precision of the
+	// clock, which is truncated to microseconds by the Thrift encoders.
 	startTime time.Time
 
-	// duration returns duration of the span with microseconds precision.
+	// duration returns duration of the span, never negative, with the precision of the clock.
 	// Zero value means duration is unknown.
 	duration time.Duration
 
@@ -62,9 +64,33 @@ type Span struct {
 	// The number of logs dropped because of MaxLogsPerSpan.
 	numDroppedLogs int
 
+	// The number of tags, references and log fields dropped because of the span limits.
+	numDroppedTags        int
+	numDroppedReferences  int
+	numDroppedLogFields   int
+	numDroppedLogsForSize int
+
 	// references for this span
 	references []Reference
 
+	// links added to this span after it started
+	links []Link
+
+	// The number of links dropped because of MaxLinksPerSpan.
+	numDroppedLinks int
+
+	// status set with SetStatus
+	status StatusCode
+
+	// pprofCtx holds the pprof labels set on the goroutine running the span,
+	// and pprofRestoreCtx the labels to restore on Finish, see TracerOptions.PprofLabels.
+	pprofCtx        context.Context
+	pprofRestoreCtx context.Context
+
+	// goroutineTask is true if the span is started on the calling goroutine and
+	// finished on another one, see Tracer.Go.
+	goroutineTask bool
+
 	observer ContribSpanObserver
 }
 
@@ -88,7 +114,7 @@ func (s *Span) SetOperationName(operationName string) opentracing.Span {
 	ctx := s.context
 	s.Unlock()
 	if !ctx.isSamplingFinalized() {
-		decision := s.tracer.sampler.OnSetOperationName(s, operationName)
+		decision := s.tracer.getSampler().OnSetOperationName(s, operationName)
 		s.applySamplingDecision(decision, true)
 	}
 	s.observer.OnSetOperationName(operationName)
@@ -116,7 +142,7 @@ func (s *Span) setTagInternal(key string, value interface{}, lock bool) opentrac
 		return s
 	}
 	if !ctx.isSamplingFinalized() {
-		decision := s.tracer.sampler.OnSetTag(s, key, value)
+		decision := s.tracer.getSampler().OnSetTag(s, key, value)
 		s.applySamplingDecision(decision, lock)
 	}
 	if ctx.isWriteable() {
@@ -129,6 +155,18 @@ func (s *Span) setTagInternal(key string, value interface{}, lock bool) opentrac
 	return s
 }
 
+// setUniqueTagNoLocking appends a tag like appendTagNoLocking, but replaces the value
+// of the tag if it was already set.
+func (s *Span) setUniqueTagNoLocking(key string, value interface{}) {
+	for i := range s.tags {
+		if s.tags[i].key == key {
+			s.tags[i].value = value
+			return
+		}
+	}
+	s.appendTagNoLocking(key, value)
+}
+
 // SpanContext returns span context
 func (s *Span) SpanContext() SpanContext {
 	s.Lock()
@@ -150,6 +188,13 @@ func (s *Span) Duration() time.Duration {
 	return s.duration
 }
 
+// EndTime returns span end time, i.e. its start time plus its duration.
+func (s *Span) EndTime() time.Time {
+	s.Lock()
+	defer s.Unlock()
+	return s.startTime.Add(s.duration)
+}
+
 // Tags returns tags for span
 func (s *Span) Tags() opentracing.Tags {
 	s.Lock()
@@ -158,6 +203,9 @@ func (s *Span) Tags() opentracing.Tags {
 	for _, tag := range s.tags {
 		result[tag.key] = tag.value
 	}
+	for _, tag := range s.droppedCountTags() {
+		result[tag.key] = tag.value
+	}
 	return result
 }
 
@@ -191,7 +239,20 @@ func (s *Span) References() []opentracing.SpanReference {
 }
 
 func (s *Span) appendTagNoLocking(key string, value interface{}) {
-	s.tags = append(s.tags, Tag{key: key, value: value})
+	maxTags := s.tracer.options.maxTagsPerSpan
+	if maxTags == 0 || len(s.tags) < maxTags {
+		s.tags = append(s.tags, Tag{key: key, value: value})
+		return
+	}
+	// Once the limit is reached the tags already on the span can still be updated.
+	for i := len(s.tags) - 1; i >= 0; i-- {
+		if s.tags[i].key == key {
+			s.tags[i].value = value
+			return
+		}
+	}
+	s.numDroppedTags++
+	s.tracer.metrics.SpanLimitDroppedTags.Inc(1)
 }
 
 // LogFields implements opentracing.Span API
@@ -253,6 +314,7 @@ func (s *Span) Log(ld opentracing.LogData) {
 
 // this function should only be called while holding a Write lock
 func (s *Span) appendLogNoLocking(lr opentracing.LogRecord) {
+	lr = s.limitLogFieldsNoLocking(lr)
 	maxLogs := s.tracer.options.maxLogsPerSpan
 	if maxLogs == 0 || len(s.logs) < maxLogs {
 		s.logs = append(s.logs, lr)
@@ -340,6 +402,14 @@ func (s *Span) BaggageItem(key string) string {
 // After finishing the Span object it returns back to the allocator unless the reporter retains it again,
 // so after that, the Span object should no longer be used because it won't be valid anymore.
 func (s *Span) Finish() {
+	if s.tracer.options.recordPanics {
+		// recover only stops the panic when called directly by a deferred function,
+		// i.e. when Finish is deferred
+		if r := recover(); r != nil {
+			s.finishWithPanic(r)
+			panic(r)
+		}
+	}
 	s.FinishWithOptions(opentracing.FinishOptions{})
 }
 
@@ -350,11 +420,12 @@ func (s *Span) FinishWithOptions(options opentracing.FinishOptions) {
 	}
 	s.observer.OnFinish(options)
 	s.Lock()
-	s.duration = options.FinishTime.Sub(s.startTime)
+	s.restorePprofLabels()
+	s.duration = s.durationUntil(options.FinishTime)
 	ctx := s.context
 	s.Unlock()
 	if !ctx.isSamplingFinalized() {
-		decision := s.tracer.sampler.OnFinishSpan(s)
+		decision := s.tracer.getSampler().OnFinishSpan(s)
 		s.applySamplingDecision(decision, true)
 	}
 	if ctx.IsSampled() {
@@ -362,13 +433,14 @@ func (s *Span) FinishWithOptions(options opentracing.FinishOptions) {
 		s.fixLogsIfDropped()
 		if len(options.LogRecords) > 0 || len(options.BulkLogData) > 0 {
 			// Note: bulk logs are not subject to maxLogsPerSpan limit
-			if options.LogRecords != nil {
-				s.logs = append(s.logs, options.LogRecords...)
+			for _, lr := range options.LogRecords {
+				s.logs = append(s.logs, s.limitLogFieldsNoLocking(lr))
 			}
 			for _, ld := range options.BulkLogData {
-				s.logs = append(s.logs, ld.ToLogRecord())
+				s.logs = append(s.logs, s.limitLogFieldsNoLocking(ld.ToLogRecord()))
 			}
 		}
+		s.limitSizeNoLocking()
 		s.Unlock()
 	}
 	// call reportSpan even for non-sampled traces, to return span to the pool
@@ -376,6 +448,30 @@ func (s *Span) FinishWithOptions(options opentracing.FinishOptions) {
 	s.tracer.reportSpan(s)
 }
 
+// durationUntil returns the duration of the span finished at finishTime, never negative.
+// Sub uses the monotonic clock when both times are read by the tracer, but the wall clock,
+// which can be stepped, when the finish time is given explicitly. The latter is then measured
+// from the current time instead: the monotonic time elapsed since the start of the span,
+// plus the offset of finishTime from the current wall clock time.
+// The caller must hold s.Lock.
+func (s *Span) durationUntil(finishTime time.Time) time.Duration {
+	duration := finishTime.Sub(s.startTime)
+	if !hasMonotonicClock(finishTime) && hasMonotonicClock(s.startTime) {
+		if now := s.tracer.timeNow(); hasMonotonicClock(now) {
+			duration = now.Sub(s.startTime) + finishTime.Sub(now.Round(0))
+		}
+	}
+	if duration < 0 {
+		return 0
+	}
+	return duration
+}
+
+// hasMonotonicClock returns true if t carries a monotonic clock reading, which Round(0) strips.
+func hasMonotonicClock(t time.Time) bool {
+	return t != t.Round(0)
+}
+
 // Context implements opentracing.Span API
 func (s *Span) Context() opentracing.SpanContext {
 	s.Lock()
@@ -430,7 +526,17 @@ func (s *Span) reset() {
 	s.tags = s.tags[:0]
 	s.logs = s.logs[:0]
 	s.numDroppedLogs = 0
+	s.numDroppedTags = 0
+	s.numDroppedReferences = 0
+	s.numDroppedLogFields = 0
+	s.numDroppedLogsForSize = 0
+	s.status = StatusUnset
+	s.pprofCtx = nil
+	s.pprofRestoreCtx = nil
+	s.goroutineTask = false
 	s.references = s.references[:0]
+	s.links = s.links[:0]
+	s.numDroppedLinks = 0
 }
 
 func (s *Span) serviceName() string {
@@ -501,3 +607,16 @@ func EnableFirehose(s *Span) {
 	defer s.Unlock()
 	s.context.samplingState.setFirehose()
 }
+
+// EnableDebug sets the debug and sampled flags on the span context, unless debug
+// spans for the span's operation are throttled by the tracer's DebugThrottler.
+// Returns true if the flags were set.
+func EnableDebug(s *Span) bool {
+	s.Lock()
+	defer s.Unlock()
+	if !s.tracer.isDebugAllowed(s.operationName) {
+		return false
+	}
+	s.context.samplingState.setDebugAndSampled()
+	return true
+}
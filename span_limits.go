@@ -0,0 +1,112 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/uber/jaeger-client-go/thrift"
+)
+
+// limitLogFieldsNoLocking returns the log record with at most MaxFieldsPerLog fields.
+// This function should only be called while holding a Write lock.
+func (s *Span) limitLogFieldsNoLocking(lr opentracing.LogRecord) opentracing.LogRecord {
+	maxFields := s.tracer.options.maxFieldsPerLog
+	if maxFields == 0 || len(lr.Fields) <= maxFields {
+		return lr
+	}
+	numDropped := len(lr.Fields) - maxFields
+	s.numDroppedLogFields += numDropped
+	s.tracer.metrics.SpanLimitDroppedLogFields.Inc(int64(numDropped))
+	// the caller owns the fields, so they are copied rather than resliced
+	lr.Fields = append(lr.Fields[:0:0], lr.Fields[:maxFields]...)
+	return lr
+}
+
+// droppedCountTags returns the tags reporting the number of tags, references, links, logs
+// and log fields dropped from the span because of the span limits.
+// This function should only be called while holding a lock.
+func (s *Span) droppedCountTags() []Tag {
+	var tags []Tag
+	add := func(key string, count int) {
+		if count > 0 {
+			tags = append(tags, Tag{key: key, value: count})
+		}
+	}
+	add(DroppedTagsTagKey, s.numDroppedTags)
+	add(DroppedReferencesTagKey, s.numDroppedReferences)
+	add(DroppedLinksTagKey, s.numDroppedLinks)
+	add(DroppedLogFieldsTagKey, s.numDroppedLogFields)
+	add(DroppedLogsTagKey, s.numDroppedLogsForSize)
+	return tags
+}
+
+// limitSizeNoLocking drops the newest logs, and then the newest tags, of a span that
+// exceeds MaxSpanSize once serialized, until it fits.
+// This function should only be called while holding a Write lock.
+func (s *Span) limitSizeNoLocking() {
+	maxSize := s.tracer.options.maxSpanSize
+	if maxSize == 0 {
+		return
+	}
+	sizer := newThriftSizer()
+	for {
+		jSpan := buildJaegerThrift(s)
+		excess := sizer.size(jSpan) - maxSize
+		if excess <= 0 {
+			return
+		}
+		numDropped := 0
+		for ; excess > 0 && len(s.logs) > 0; numDropped++ {
+			excess -= sizer.size(jSpan.Logs[len(s.logs)-1])
+			s.logs = s.logs[:len(s.logs)-1]
+			s.numDroppedLogsForSize++
+		}
+		for ; excess > 0 && len(s.tags) > 0; numDropped++ {
+			excess -= sizer.size(jSpan.Tags[len(s.tags)-1])
+			s.tags = s.tags[:len(s.tags)-1]
+			s.numDroppedTags++
+		}
+		if numDropped == 0 {
+			// nothing left to drop
+			return
+		}
+		s.tracer.metrics.SpanLimitDroppedForSize.Inc(int64(numDropped))
+		// the dropped-count tags grew, so check the size again
+	}
+}
+
+// thriftSizer computes the size of Thrift structs serialized with the compact
+// protocol, which the agent transport uses.
+type thriftSizer struct {
+	buffer   *thrift.TMemoryBuffer
+	protocol thrift.TProtocol
+}
+
+func newThriftSizer() *thriftSizer {
+	buffer := thrift.NewTMemoryBuffer()
+	return &thriftSizer{
+		buffer:   buffer,
+		protocol: thrift.NewTCompactProtocolFactory().GetProtocol(buffer),
+	}
+}
+
+func (s *thriftSizer) size(thriftStruct thrift.TStruct) int {
+	s.buffer.Reset()
+	_ = thriftStruct.Write(context.Background(), s.protocol)
+	return s.buffer.Len()
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+)
+
+func newLimitsTracer(options ...TracerOption) (*Tracer, *InMemoryReporter, *metricstest.Factory) {
+	metricsFactory := metricstest.NewFactory(0)
+	reporter := NewInMemoryReporter()
+	options = append(options, TracerOptions.Metrics(NewMetrics(metricsFactory, nil)))
+	tracer, _ := NewTracer("limits", NewConstSampler(true), reporter, options...)
+	return tracer.(*Tracer), reporter, metricsFactory
+}
+
+func TestSpanLimitTags(t *testing.T) {
+	tracer, _, metricsFactory := newLimitsTracer(TracerOptions.MaxTagsPerSpan(4))
+	defer tracer.Close()
+
+	// the root span already has the sampler.type and sampler.param tags
+	span := tracer.StartSpan("op", opentracing.Tags{"a": 1}).(*Span)
+	span.SetTag("b", 2)
+	span.SetTag("c", 3)
+	span.SetTag("d", 4)
+	span.SetTag("a", 5)
+
+	tags := span.Tags()
+	assert.Equal(t, 5, tags["a"], "existing tags can still be updated")
+	assert.Equal(t, 2, tags["b"])
+	assert.NotContains(t, tags, "c")
+	assert.NotContains(t, tags, "d")
+	assert.Equal(t, 2, tags[DroppedTagsTagKey])
+	assert.Equal(t, int64(2), *findTag(BuildJaegerThrift(span), DroppedTagsTagKey).VLong)
+	span.Finish()
+
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "jaeger.tracer.span_limit_drops", Tags: map[string]string{"limit": "tags"}, Value: 2,
+	})
+}
+
+func TestSpanLimitReferences(t *testing.T) {
+	tracer, _, metricsFactory := newLimitsTracer(TracerOptions.MaxReferencesPerSpan(2))
+	defer tracer.Close()
+
+	var refs []opentracing.StartSpanOption
+	for i := 0; i < 5; i++ {
+		parent := tracer.StartSpan(fmt.Sprintf("parent%d", i))
+		defer parent.Finish()
+		refs = append(refs, opentracing.FollowsFrom(parent.Context()))
+	}
+	span := tracer.StartSpan("op", refs...).(*Span)
+	defer span.Finish()
+
+	assert.Len(t, span.References(), 2)
+	assert.Len(t, BuildJaegerThrift(span).References, 2)
+	assert.Equal(t, 3, span.Tags()[DroppedReferencesTagKey])
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "jaeger.tracer.span_limit_drops", Tags: map[string]string{"limit": "references"}, Value: 3,
+	})
+}
+
+func TestSpanLimitLogFields(t *testing.T) {
+	tracer, _, metricsFactory := newLimitsTracer(TracerOptions.MaxFieldsPerLog(2))
+	defer tracer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	fields := []log.Field{log.Int("a", 1), log.Int("b", 2), log.Int("c", 3)}
+	span.LogFields(fields...)
+	span.LogKV("d", 4)
+	span.FinishWithOptions(opentracing.FinishOptions{
+		LogRecords: []opentracing.LogRecord{{Fields: fields}},
+	})
+
+	logs := span.Logs()
+	require.Len(t, logs, 3)
+	assert.Len(t, logs[0].Fields, 2)
+	assert.Len(t, logs[1].Fields, 1)
+	assert.Len(t, logs[2].Fields, 2)
+	assert.Len(t, fields, 3, "the fields of the caller must not be modified")
+	assert.Equal(t, 2, span.Tags()[DroppedLogFieldsTagKey])
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "jaeger.tracer.span_limit_drops", Tags: map[string]string{"limit": "log_fields"}, Value: 2,
+	})
+}
+
+func TestSpanLimitSize(t *testing.T) {
+	const maxSpanSize = 400
+	tracer, reporter, metricsFactory := newLimitsTracer(TracerOptions.MaxSpanSize(maxSpanSize))
+	defer tracer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	for i := 0; i < 10; i++ {
+		span.SetTag(fmt.Sprintf("tag%d", i), "some value to take room")
+	}
+	for i := 0; i < 10; i++ {
+		span.LogKV("event", "some event to take room", "i", i)
+	}
+	span.Finish()
+	require.Len(t, reporter.GetSpans(), 1)
+
+	jSpan := BuildJaegerThrift(span)
+	assert.LessOrEqual(t, newThriftSizer().size(jSpan), maxSpanSize)
+	assert.Empty(t, jSpan.Logs, "the logs are dropped before the tags")
+	assert.Equal(t, int64(10), *findTag(jSpan, DroppedLogsTagKey).VLong)
+	droppedTags := *findTag(jSpan, DroppedTagsTagKey).VLong
+	assert.True(t, droppedTags > 0)
+	assert.NotNil(t, findTag(jSpan, SamplerTypeTagKey), "the oldest tags are kept")
+
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "jaeger.tracer.span_limit_drops", Tags: map[string]string{"limit": "size"}, Value: 10 + int(droppedTags),
+	})
+}
+
+func TestSpanLimitSizeNotExceeded(t *testing.T) {
+	tracer, _, _ := newLimitsTracer(TracerOptions.MaxSpanSize(10000))
+	defer tracer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	span.SetTag("a", "b")
+	span.LogKV("c", "d")
+	span.Finish()
+
+	assert.Len(t, span.Logs(), 1)
+	assert.NotContains(t, span.Tags(), DroppedTagsTagKey)
+	assert.NotContains(t, span.Tags(), DroppedLogsTagKey)
+}
+
+func TestSpanLimitZipkinThrift(t *testing.T) {
+	tracer, _, _ := newLimitsTracer(TracerOptions.MaxTagsPerSpan(2))
+	defer tracer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	span.SetTag("dropped", true)
+	defer span.Finish()
+
+	var found bool
+	for _, anno := range BuildZipkinThrift(span).BinaryAnnotations {
+		if anno.Key == DroppedTagsTagKey {
+			found = true
+		}
+		assert.NotEqual(t, "dropped", anno.Key)
+	}
+	assert.True(t, found)
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+
+	j "github.com/uber/jaeger-client-go/thrift-gen/jaeger"
+	"github.com/uber/jaeger-client-go/utils"
+)
+
+// Link is a causal link from a span to another span, e.g. one of the messages
+// processed by a batch consumer, with attributes describing it such as the
+// offset of the message. Unlike the references, links can be added after the
+// span has started.
+//
+// Links are reported as FOLLOWS_FROM references with Jaeger Thrift, and their
+// attributes as a log with event=link. Transports supporting links natively,
+// such as OTLP, can encode them from Span.Links.
+type Link struct {
+	Context    SpanContext
+	Attributes []opentracing.Tag
+	// Timestamp is the time when the link was added.
+	Timestamp time.Time
+}
+
+// AddLink adds a link to the span with the given context, which must be a
+// jaeger.SpanContext, with optional attributes. The links beyond
+// TracerOptions.MaxLinksPerSpan are dropped.
+func (s *Span) AddLink(ctx opentracing.SpanContext, attributes ...opentracing.Tag) {
+	linkCtx, ok := ctx.(SpanContext)
+	if !ok {
+		s.tracer.logger.Error(fmt.Sprintf(
+			"Link contains invalid type of SpanContext: %s", reflect.ValueOf(ctx)))
+		return
+	}
+	if !linkCtx.IsValid() {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	if !s.context.isWriteable() {
+		return
+	}
+	if maxLinks := s.tracer.options.maxLinksPerSpan; maxLinks > 0 && len(s.links) >= maxLinks {
+		s.numDroppedLinks++
+		s.tracer.metrics.SpanLimitDroppedLinks.Inc(1)
+		return
+	}
+	s.links = append(s.links, Link{
+		Context:    linkCtx,
+		Attributes: append([]opentracing.Tag(nil), attributes...),
+		Timestamp:  s.tracer.timeNow(),
+	})
+}
+
+// Links returns the links added to the span with AddLink.
+func (s *Span) Links() []Link {
+	s.Lock()
+	defer s.Unlock()
+	if len(s.links) == 0 {
+		return nil
+	}
+	return append([]Link(nil), s.links...)
+}
+
+func buildLinkReferences(links []Link) []*j.SpanRef {
+	refs := make([]*j.SpanRef, 0, len(links))
+	for _, link := range links {
+		refs = append(refs, spanRef(link.Context, j.SpanRefType_FOLLOWS_FROM))
+	}
+	return refs
+}
+
+// buildLinkLogs returns a log with the attributes of each link that has some, since
+// the Jaeger Thrift references cannot carry attributes.
+func buildLinkLogs(links []Link, maxTagValueLength int) []*j.Log {
+	var jLogs []*j.Log
+	for _, link := range links {
+		if len(link.Attributes) == 0 {
+			continue
+		}
+		tags := make([]Tag, 0, 3+len(link.Attributes))
+		tags = append(tags,
+			Tag{key: "event", value: "link"},
+			Tag{key: "link.trace_id", value: link.Context.TraceID().String()},
+			Tag{key: "link.span_id", value: link.Context.SpanID().String()},
+		)
+		for _, attribute := range link.Attributes {
+			tags = append(tags, Tag{key: attribute.Key, value: attribute.Value})
+		}
+		jLogs = append(jLogs, &j.Log{
+			Timestamp: utils.TimeToMicrosecondsSinceEpochInt64(link.Timestamp),
+			Fields:    buildTags(tags, maxTagValueLength),
+		})
+	}
+	return jLogs
+}
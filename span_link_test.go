@@ -0,0 +1,103 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+
+	j "github.com/uber/jaeger-client-go/thrift-gen/jaeger"
+)
+
+func TestSpanAddLink(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter(),
+		TracerOptions.TimeNow(func() time.Time { return time.Unix(1000, 0) }))
+	defer closer.Close()
+
+	message1 := tracer.StartSpan("produce1")
+	message2 := tracer.StartSpan("produce2")
+	defer message1.Finish()
+	defer message2.Finish()
+
+	span := tracer.StartSpan("consume").(*Span)
+	defer span.Finish()
+	span.AddLink(message1.Context(), opentracing.Tag{Key: "messaging.offset", Value: 42})
+	span.AddLink(message2.Context())
+	span.AddLink(SpanContext{})
+	span.AddLink(mocktracer.New().StartSpan("mock").Context())
+
+	links := span.Links()
+	require.Len(t, links, 2)
+	assert.Equal(t, message1.Context(), links[0].Context)
+	assert.Equal(t, []opentracing.Tag{{Key: "messaging.offset", Value: 42}}, links[0].Attributes)
+	assert.Equal(t, time.Unix(1000, 0), links[0].Timestamp)
+	assert.Empty(t, span.References(), "links are not start references")
+
+	jSpan := BuildJaegerThrift(span)
+	require.Len(t, jSpan.References, 2)
+	for i, message := range []opentracing.Span{message1, message2} {
+		ctx := message.Context().(SpanContext)
+		assert.Equal(t, j.SpanRefType_FOLLOWS_FROM, jSpan.References[i].RefType)
+		assert.Equal(t, int64(ctx.SpanID()), jSpan.References[i].SpanId)
+		assert.Equal(t, int64(ctx.TraceID().Low), jSpan.References[i].TraceIdLow)
+	}
+
+	require.Len(t, jSpan.Logs, 1, "only the links with attributes are logged")
+	assert.Equal(t, int64(1000000000), jSpan.Logs[0].Timestamp)
+	fields := jSpan.Logs[0].Fields
+	require.Len(t, fields, 4)
+	assert.Equal(t, "link", fields[0].GetVStr())
+	assert.Equal(t, message1.Context().(SpanContext).TraceID().String(), fields[1].GetVStr())
+	assert.Equal(t, message1.Context().(SpanContext).SpanID().String(), fields[2].GetVStr())
+	assert.Equal(t, "messaging.offset", fields[3].Key)
+	assert.Equal(t, int64(42), fields[3].GetVLong())
+}
+
+func TestSpanAddLinkLimit(t *testing.T) {
+	tracer, _, metricsFactory := newLimitsTracer(TracerOptions.MaxLinksPerSpan(1))
+	defer tracer.Close()
+
+	other := tracer.StartSpan("other")
+	defer other.Finish()
+	span := tracer.StartSpan("op").(*Span)
+	defer span.Finish()
+	for i := 0; i < 3; i++ {
+		span.AddLink(other.Context())
+	}
+
+	assert.Len(t, span.Links(), 1)
+	assert.Equal(t, 2, span.Tags()[DroppedLinksTagKey])
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "jaeger.tracer.span_limit_drops", Tags: map[string]string{"limit": "links"}, Value: 2,
+	})
+}
+
+func TestSpanAddLinkNotSampled(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(false), NewNullReporter())
+	defer closer.Close()
+
+	other := tracer.StartSpan("other")
+	defer other.Finish()
+	span := tracer.StartSpan("op").(*Span)
+	defer span.Finish()
+	span.AddLink(other.Context())
+	assert.Empty(t, span.Links())
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// panicFlushTimeout bounds the time spent reporting the span of a panic.
+const panicFlushTimeout = 5 * time.Second
+
+// FinishWithRecover finishes the span like Finish, and must be deferred as is:
+//
+//     span := tracer.StartSpan("operation").(*jaeger.Span)
+//     defer span.FinishWithRecover()
+//
+// If the function deferring it panics, the panic value and stack are recorded as an
+// error log, the error tag is set, the span is finished and reported synchronously,
+// since the process is likely about to crash, and the panic is propagated.
+func (s *Span) FinishWithRecover() {
+	if r := recover(); r != nil {
+		s.finishWithPanic(r)
+		panic(r)
+	}
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+// finishWithPanic records the panic, finishes the span and flushes the reporter.
+func (s *Span) finishWithPanic(r interface{}) {
+	tracer := s.tracer
+	s.recordPanic(newPanicError(r))
+	s.FinishWithOptions(opentracing.FinishOptions{})
+	// the span might have been released by now, so it must not be used anymore
+	tracer.flushReporter()
+}
+
+// flushReporter synchronously sends the spans reported so far, if the reporter supports it.
+func (t *Tracer) flushReporter() {
+	flusher, ok := t.getComponents().reporter.(ReporterFlusher)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), panicFlushTimeout)
+	defer cancel()
+	if err := flusher.Flush(ctx); err != nil {
+		t.logger.Error(fmt.Sprintf("Cannot flush the reporter: %v", err))
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPanicTracer(options ...TracerOption) (*Tracer, *fakeSender) {
+	// the periodic flush never happens during the tests, so the flushed spans
+	// can only come from the synchronous flush of the panicking span
+	sender := &fakeSender{bufferSize: 100}
+	reporter := NewRemoteReporter(sender, ReporterOptions.BufferFlushInterval(time.Hour))
+	tracer, _ := NewTracer("svc", NewConstSampler(true), reporter, options...)
+	return tracer.(*Tracer), sender
+}
+
+func assertPanicRecorded(t *testing.T, sender *fakeSender, testName string) {
+	spans := sender.FlushedSpans()
+	require.Len(t, spans, 1, "the span is reported synchronously")
+	span := spans[0]
+	assert.Equal(t, true, span.Tags()["error"])
+	logs := span.Logs()
+	require.Len(t, logs, 1)
+	fields := logFields(logs[0])
+	assert.Equal(t, "error", fields["event"])
+	assert.Equal(t, "panic", fields["error.kind"])
+	assert.Equal(t, "boom", fields["message"])
+	assert.Contains(t, fields["stack"], testName)
+}
+
+func TestFinishWithRecover(t *testing.T) {
+	tracer, sender := newPanicTracer()
+	defer tracer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	assert.PanicsWithValue(t, "boom", func() {
+		defer span.FinishWithRecover()
+		panic("boom")
+	})
+	assertPanicRecorded(t, sender, "TestFinishWithRecover")
+}
+
+func TestFinishWithRecoverWithoutPanic(t *testing.T) {
+	tracer, sender := newPanicTracer()
+	defer tracer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	assert.NotPanics(t, func() {
+		defer span.FinishWithRecover()
+	})
+	sender.assertBufferedSpans(t, 1)
+	assert.Empty(t, sender.FlushedSpans(), "the span is reported asynchronously")
+	assert.Empty(t, span.Logs())
+	assert.Nil(t, span.Tags()["error"])
+}
+
+func TestFinishRecordPanics(t *testing.T) {
+	tracer, sender := newPanicTracer(TracerOptions.RecordPanics(true))
+	defer tracer.Close()
+
+	var span opentracing.Span = tracer.StartSpan("op")
+	assert.PanicsWithValue(t, "boom", func() {
+		defer span.Finish()
+		panic("boom")
+	})
+	assertPanicRecorded(t, sender, "TestFinishRecordPanics")
+}
+
+func TestFinishDoesNotRecordPanicsByDefault(t *testing.T) {
+	tracer, sender := newPanicTracer()
+	defer tracer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	assert.PanicsWithValue(t, "boom", func() {
+		defer span.Finish()
+		panic("boom")
+	})
+	sender.assertBufferedSpans(t, 1)
+	assert.Empty(t, sender.FlushedSpans())
+	assert.Empty(t, span.Logs())
+}
+
+func TestFinishWithPanicWithoutFlusher(t *testing.T) {
+	tracer, reporter := newGoroutineTracer()
+	defer tracer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	assert.PanicsWithValue(t, "boom", func() {
+		defer span.FinishWithRecover()
+		panic("boom")
+	})
+	assert.Equal(t, 1, reporter.SpansSubmitted())
+	assert.Equal(t, true, span.Tags()["error"])
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// StatusCode is the status of the operation of a span, set with Span.SetStatus.
+type StatusCode int
+
+const (
+	// StatusUnset is the default status of a span.
+	StatusUnset StatusCode = iota
+	// StatusOK indicates that the operation was validated as successful by the application.
+	StatusOK
+	// StatusError indicates that the operation failed.
+	StatusError
+)
+
+// maxErrorChainLength bounds the number of wrapped errors recorded by RecordError.
+const maxErrorChainLength = 10
+
+func (c StatusCode) String() string {
+	switch c {
+	case StatusOK:
+		return "OK"
+	case StatusError:
+		return "ERROR"
+	default:
+		return "UNSET"
+	}
+}
+
+// SetStatus sets the status of the span, recorded in the otel.status_code and
+// otel.status_description tags. StatusError also sets the error=true tag, and the
+// description is only recorded for StatusError. StatusOK resets the error tag to
+// false if it was set to true.
+//
+// As with OpenTelemetry, StatusOK is final: it overrides StatusError and cannot be
+// changed afterwards, and StatusUnset is ignored.
+func (s *Span) SetStatus(code StatusCode, description string) {
+	if code == StatusUnset {
+		return
+	}
+	s.Lock()
+	if s.status == StatusOK {
+		s.Unlock()
+		return
+	}
+	tags := s.statusTagsNoLocking(code, description)
+	s.Unlock()
+
+	// the observer and the sampler are notified like for SetTag, without holding the lock
+	for _, tag := range tags {
+		s.observer.OnSetTag(tag.key, tag.value)
+		if !s.SpanContext().isSamplingFinalized() {
+			decision := s.tracer.getSampler().OnSetTag(s, tag.key, tag.value)
+			s.applySamplingDecision(decision, true)
+		}
+	}
+
+	// the status and its tags are updated together, in case SetStatus is called concurrently
+	s.Lock()
+	defer s.Unlock()
+	if s.status == StatusOK {
+		return
+	}
+	s.status = code
+	if s.context.isWriteable() {
+		for _, tag := range s.statusTagsNoLocking(code, description) {
+			s.setUniqueTagNoLocking(tag.key, tag.value)
+		}
+	}
+}
+
+// statusTagsNoLocking returns the tags recording the given status.
+func (s *Span) statusTagsNoLocking(code StatusCode, description string) []Tag {
+	tags := []Tag{{key: StatusCodeTagKey, value: code.String()}}
+	if code == StatusError {
+		tags = append(tags, Tag{key: string(ext.Error), value: true})
+		if description != "" {
+			tags = append(tags, Tag{key: StatusDescriptionTagKey, value: description})
+		}
+	} else if s.hasErrorTagNoLocking() {
+		tags = append(tags, Tag{key: string(ext.Error), value: false})
+	}
+	return tags
+}
+
+func (s *Span) hasErrorTagNoLocking() bool {
+	for _, tag := range s.tags {
+		if tag.key == string(ext.Error) && tag.value == true {
+			return true
+		}
+	}
+	return false
+}
+
+// Status returns the status set with SetStatus.
+func (s *Span) Status() StatusCode {
+	s.RLock()
+	defer s.RUnlock()
+	return s.status
+}
+
+// RecordError records the error as an event=error log with the error.object,
+// error.kind and message fields, followed by the error.cause.N.kind and
+// error.cause.N.message fields of the errors it wraps (see errors.Unwrap).
+// If the status of the span is StatusUnset, RecordError also sets it to
+// StatusError, with the message of the error as the description.
+//
+// It does nothing if err is nil.
+func (s *Span) RecordError(err error, options ...RecordErrorOption) {
+	if err == nil {
+		return
+	}
+	opts := recordErrorOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	fields := []log.Field{
+		log.String("event", "error"),
+		log.Error(err),
+		log.String("error.kind", errorKind(err)),
+		log.String("message", err.Error()),
+	}
+	cause := errors.Unwrap(err)
+	for i := 1; cause != nil && i <= maxErrorChainLength; i++ {
+		fields = append(fields,
+			log.String(fmt.Sprintf("error.cause.%d.kind", i), errorKind(cause)),
+			log.String(fmt.Sprintf("error.cause.%d.message", i), cause.Error()),
+		)
+		cause = errors.Unwrap(cause)
+	}
+	if opts.stackTrace {
+		// skip runtime.Callers, stackTrace and RecordError
+		fields = append(fields, log.String("stack", stackTrace(3)))
+	}
+	fields = append(fields, opts.fields...)
+	s.logError(err.Error(), fields, opts.timestamp)
+}
+
+// logError sets the error status with the description unless the status was already
+// set, and logs the fields of the error.
+func (s *Span) logError(description string, fields []log.Field, timestamp time.Time) {
+	if s.Status() == StatusUnset {
+		s.SetStatus(StatusError, description)
+	}
+	s.Lock()
+	defer s.Unlock()
+	if !s.context.IsSampled() {
+		return
+	}
+	if timestamp.IsZero() {
+		timestamp = s.tracer.timeNow()
+	}
+	s.appendLogNoLocking(opentracing.LogRecord{Fields: fields, Timestamp: timestamp})
+}
+
+// RecordErrorOption is a function that sets some option of Span.RecordError
+type RecordErrorOption func(options *recordErrorOptions)
+
+type recordErrorOptions struct {
+	stackTrace bool
+	timestamp  time.Time
+	fields     []log.Field
+}
+
+// RecordErrorOptions is a factory for all available RecordErrorOption's.
+var RecordErrorOptions RecordErrorOptionsFactory
+
+// RecordErrorOptionsFactory is a factory for all available RecordErrorOption's.
+// The type acts as a namespace for factory functions. It is public to
+// make the functions discoverable via godoc. Recommended to be used
+// via global RecordErrorOptions variable.
+type RecordErrorOptionsFactory struct{}
+
+// StackTrace records the stack trace of the caller of RecordError in the stack field.
+func (RecordErrorOptionsFactory) StackTrace() RecordErrorOption {
+	return func(options *recordErrorOptions) {
+		options.stackTrace = true
+	}
+}
+
+// Timestamp sets the timestamp of the error log, the current time by default.
+func (RecordErrorOptionsFactory) Timestamp(timestamp time.Time) RecordErrorOption {
+	return func(options *recordErrorOptions) {
+		options.timestamp = timestamp
+	}
+}
+
+// Fields adds fields to the error log.
+func (RecordErrorOptionsFactory) Fields(fields ...log.Field) RecordErrorOption {
+	return func(options *recordErrorOptions) {
+		options.fields = append(options.fields, fields...)
+	}
+}
+
+// errorKind returns the type of the error, e.g. "*os.PathError".
+func errorKind(err error) string {
+	return fmt.Sprintf("%T", err)
+}
+
+// stackTrace formats the stack of the current goroutine like runtime/debug.Stack,
+// skipping the given number of frames.
+func stackTrace(skip int) string {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(skip, pcs)]
+	frames := runtime.CallersFrames(pcs)
+	var sb strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s()\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpanSetStatus(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter())
+	defer closer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	defer span.Finish()
+	assert.Equal(t, StatusUnset, span.Status())
+
+	span.SetStatus(StatusUnset, "ignored")
+	assert.NotContains(t, span.Tags(), StatusCodeTagKey)
+
+	span.SetStatus(StatusError, "first")
+	span.SetStatus(StatusError, "second")
+	assert.Equal(t, StatusError, span.Status())
+	tags := span.Tags()
+	assert.Equal(t, "ERROR", tags[StatusCodeTagKey])
+	assert.Equal(t, "second", tags[StatusDescriptionTagKey])
+	assert.Equal(t, true, tags["error"])
+	assert.Len(t, BuildJaegerThrift(span).Tags, len(tags), "status tags are not duplicated")
+
+	span.SetStatus(StatusOK, "")
+	span.SetStatus(StatusError, "ignored after OK")
+	assert.Equal(t, StatusOK, span.Status())
+	tags = span.Tags()
+	assert.Equal(t, "OK", tags[StatusCodeTagKey])
+	assert.Equal(t, false, tags["error"])
+}
+
+func TestSpanSetStatusOK(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter())
+	defer closer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	defer span.Finish()
+	span.SetStatus(StatusOK, "")
+	assert.Equal(t, "OK", span.Tags()[StatusCodeTagKey])
+	assert.NotContains(t, span.Tags(), "error", "the error tag is only reset if it was set")
+}
+
+func TestSpanSetStatusConcurrently(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter())
+	defer closer.Close()
+
+	for i := 0; i < 100; i++ {
+		span := tracer.StartSpan("op").(*Span)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			span.SetStatus(StatusError, "failed")
+		}()
+		go func() {
+			defer wg.Done()
+			span.SetStatus(StatusOK, "")
+		}()
+		wg.Wait()
+		assert.Equal(t, StatusOK, span.Status())
+		assert.NotEqual(t, true, span.Tags()["error"])
+		assert.Equal(t, "OK", span.Tags()[StatusCodeTagKey])
+		span.Finish()
+	}
+}
+
+func TestStatusCodeString(t *testing.T) {
+	assert.Equal(t, "UNSET", StatusUnset.String())
+	assert.Equal(t, "OK", StatusOK.String())
+	assert.Equal(t, "ERROR", StatusError.String())
+}
+
+func TestSpanRecordError(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter())
+	defer closer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	defer span.Finish()
+
+	span.RecordError(nil)
+	assert.Empty(t, span.Logs())
+	assert.Equal(t, StatusUnset, span.Status())
+
+	root := errors.New("no such file")
+	cause := &testError{msg: "cannot open", cause: root}
+	err := fmt.Errorf("cannot load: %w", cause)
+	timestamp := time.Unix(1000, 0)
+	span.RecordError(err,
+		RecordErrorOptions.Timestamp(timestamp),
+		RecordErrorOptions.StackTrace(),
+		RecordErrorOptions.Fields(log.String("retry", "no")),
+	)
+
+	assert.Equal(t, StatusError, span.Status())
+	assert.Equal(t, err.Error(), span.Tags()[StatusDescriptionTagKey])
+	assert.Equal(t, true, span.Tags()["error"])
+
+	logs := span.Logs()
+	require.Len(t, logs, 1)
+	assert.Equal(t, timestamp, logs[0].Timestamp)
+	fields := make(map[string]interface{})
+	for _, field := range logs[0].Fields {
+		fields[field.Key()] = field.Value()
+	}
+	assert.Equal(t, "error", fields["event"])
+	assert.Equal(t, err, fields["error.object"])
+	assert.Equal(t, "*fmt.wrapError", fields["error.kind"])
+	assert.Equal(t, err.Error(), fields["message"])
+	assert.Equal(t, "*jaeger.testError", fields["error.cause.1.kind"])
+	assert.Equal(t, cause.Error(), fields["error.cause.1.message"])
+	assert.Equal(t, "*errors.errorString", fields["error.cause.2.kind"])
+	assert.Equal(t, "no such file", fields["error.cause.2.message"])
+	assert.NotContains(t, fields, "error.cause.3.kind")
+	assert.Equal(t, "no", fields["retry"])
+	stack := fields["stack"].(string)
+	assert.True(t, strings.HasPrefix(stack, "github.com/uber/jaeger-client-go.TestSpanRecordError()\n"), stack)
+}
+
+func TestSpanRecordErrorKeepsStatus(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter())
+	defer closer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	defer span.Finish()
+
+	span.SetStatus(StatusError, "request failed")
+	span.RecordError(errors.New("timeout"))
+	assert.Equal(t, "request failed", span.Tags()[StatusDescriptionTagKey])
+	assert.Len(t, span.Logs(), 1)
+	assert.Len(t, span.Logs()[0].Fields, 4, "no stack trace by default")
+}
+
+func TestSpanRecordErrorNotSampled(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(false), NewNullReporter())
+	defer closer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	defer span.Finish()
+
+	span.RecordError(errors.New("timeout"))
+	assert.Empty(t, span.Logs())
+	assert.Equal(t, StatusError, span.Status())
+}
+
+type testError struct {
+	msg   string
+	cause error
+}
+
+func (e *testError) Error() string { return e.msg + ": " + e.cause.Error() }
+
+func (e *testError) Unwrap() error { return e.cause }
@@ -95,6 +95,47 @@ func TestSpanProperties(t *testing.T) {
 	assert.Equal(t, sp1.Tags(), expectedTags)
 }
 
+func TestSpanDurationNanosecondPrecision(t *testing.T) {
+	tracer, closer := NewTracer("DOOP", NewConstSampler(true), NewNullReporter())
+	defer closer.Close()
+
+	start := time.Unix(1600000000, 123456789)
+	sp := tracer.StartSpan("s1", opentracing.StartTime(start)).(*Span)
+	sp.FinishWithOptions(opentracing.FinishOptions{FinishTime: start.Add(1500 * time.Nanosecond)})
+
+	assert.Equal(t, start, sp.StartTime())
+	assert.Equal(t, 1500*time.Nanosecond, sp.Duration())
+	assert.Equal(t, start.Add(1500*time.Nanosecond), sp.EndTime())
+
+	thriftSpan := BuildJaegerThrift(sp)
+	assert.EqualValues(t, 1600000000123456, thriftSpan.StartTime, "Thrift is truncated to microseconds")
+	assert.EqualValues(t, 1, thriftSpan.Duration)
+}
+
+func TestSpanDurationNotNegative(t *testing.T) {
+	tracer, closer := NewTracer("DOOP", NewConstSampler(true), NewNullReporter())
+	defer closer.Close()
+
+	sp := tracer.StartSpan("s1").(*Span)
+	// an explicit finish time read from a wall clock that was stepped back
+	sp.FinishWithOptions(opentracing.FinishOptions{FinishTime: sp.StartTime().Round(0).Add(-time.Second)})
+	assert.Equal(t, time.Duration(0), sp.Duration())
+	assert.Equal(t, sp.StartTime(), sp.EndTime())
+}
+
+func TestSpanDurationExplicitFinishTime(t *testing.T) {
+	tracer, closer := NewTracer("DOOP", NewConstSampler(true), NewNullReporter())
+	defer closer.Close()
+
+	sp := tracer.StartSpan("s1").(*Span)
+	require.True(t, hasMonotonicClock(sp.StartTime()))
+	time.Sleep(10 * time.Millisecond)
+	// a finish time without monotonic clock reading is measured from the current time
+	sp.FinishWithOptions(opentracing.FinishOptions{FinishTime: time.Now().Round(0).Add(-5 * time.Millisecond)})
+	assert.True(t, sp.Duration() >= 5*time.Millisecond, "duration %v", sp.Duration())
+	assert.True(t, sp.Duration() < time.Second, "duration %v", sp.Duration())
+}
+
 func TestSpanOperationName(t *testing.T) {
 	tracer, closer := NewTracer("DOOP", NewConstSampler(true), NewNullReporter())
 	defer closer.Close()
@@ -209,6 +250,25 @@ func TestSetFirehoseMode(t *testing.T) {
 	assert.True(t, sp1.context.IsFirehose())
 }
 
+func TestEnableDebug(t *testing.T) {
+	tracer, closer := NewTracer("DOOP", NewConstSampler(false), NewNullReporter())
+	defer closer.Close()
+
+	sp1 := tracer.StartSpan("s1").(*Span)
+	assert.True(t, EnableDebug(sp1))
+	assert.True(t, sp1.context.IsDebug())
+	assert.True(t, sp1.context.IsSampled())
+
+	throttledTracer, throttledCloser := NewTracer("DOOP", NewConstSampler(false), NewNullReporter(),
+		TracerOptions.DebugThrottler(testThrottler{allowAll: false}))
+	defer throttledCloser.Close()
+
+	sp2 := throttledTracer.StartSpan("s2").(*Span)
+	assert.False(t, EnableDebug(sp2))
+	assert.False(t, sp2.context.IsDebug())
+	assert.False(t, sp2.context.IsSampled())
+}
+
 type testThrottler struct {
 	allowAll bool
 }
@@ -0,0 +1,175 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// TruncatedTags returns the tags of the span, including the tags reporting the data
+// dropped because of the span limits, with their values truncated consistently with
+// the Thrift encoders under TracerOptions.MaxTagValueLength. Arrays and maps with
+// string keys are returned as []interface{} and map[string]interface{} values, for
+// the encoders of transports supporting them natively, such as JSON or OTLP.
+func (s *Span) TruncatedTags() []opentracing.Tag {
+	s.Lock()
+	defer s.Unlock()
+	maxLength := s.tracer.options.maxTagValueLength
+	tags := append(s.tags[:len(s.tags):len(s.tags)], s.droppedCountTags()...)
+	result := make([]opentracing.Tag, len(tags))
+	for i, tag := range tags {
+		result[i] = opentracing.Tag{Key: tag.key, Value: truncateTagValue(tag.value, maxLength)}
+	}
+	return result
+}
+
+// truncateTagValue truncates the strings and byte slices to maxLength, and converts
+// the arrays and maps to values whose JSON encoding fits in maxLength.
+func truncateTagValue(value interface{}, maxLength int) interface{} {
+	switch v := value.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case string:
+		return truncateString(v, maxLength)
+	case []byte:
+		if len(v) > maxLength {
+			return v[:maxLength]
+		}
+		return v
+	}
+	if composite, ok := truncateCompositeTagValue(value, maxLength); ok {
+		return composite
+	}
+	return truncateString(stringify(value), maxLength)
+}
+
+// stringifyTagValue returns the value as a string for the encoders without native
+// support for it, i.e. JSON for arrays and maps, truncated to maxLength.
+func stringifyTagValue(value interface{}, maxLength int) string {
+	if composite, ok := truncateCompositeTagValue(value, maxLength); ok {
+		if b, err := json.Marshal(composite); err == nil {
+			return string(b)
+		}
+	}
+	return truncateString(stringify(value), maxLength)
+}
+
+// truncateCompositeTagValue converts an array, or a map with string keys, to a
+// []interface{} or a map[string]interface{} with truncated elements. To keep the JSON
+// encoding valid, the elements beyond maxLength are dropped rather than cut in the
+// middle: the last elements of arrays, and the last keys in order for maps, except
+// for the strings that are cut to fit.
+func truncateCompositeTagValue(value interface{}, maxLength int) (interface{}, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// byte slices are binary values
+			return nil, false
+		}
+		budget := maxLength - len("[]")
+		result := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				budget -= len(",")
+			}
+			elem, size, fits := fitTagElement(v.Index(i).Interface(), maxLength, budget)
+			if !fits {
+				break
+			}
+			budget -= size
+			result = append(result, elem)
+		}
+		return result, true
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+			values[key.String()] = v.MapIndex(key)
+		}
+		sort.Strings(keys)
+		budget := maxLength - len("{}")
+		result := make(map[string]interface{}, len(keys))
+		for i, key := range keys {
+			keySize, _ := jsonSize(key)
+			budget -= keySize + len(":")
+			if i > 0 {
+				budget -= len(",")
+			}
+			elem, size, fits := fitTagElement(values[key].Interface(), maxLength, budget)
+			if !fits {
+				break
+			}
+			budget -= size
+			result[key] = elem
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// fitTagElement truncates an element of an array or map, and returns it along with
+// the size of its JSON encoding if it fits in budget. A string element that does
+// not fit is cut to fit, so that a single long string is truncated as a scalar is.
+func fitTagElement(elem interface{}, maxLength, budget int) (interface{}, int, bool) {
+	if composite, ok := truncateCompositeTagValue(elem, minInt(maxLength, budget)); ok {
+		size, _ := jsonSize(composite)
+		return composite, size, size <= budget
+	}
+	elem = truncateTagValue(elem, maxLength)
+	size, err := jsonSize(elem)
+	if err != nil {
+		// e.g. NaN or infinite floats
+		elem = truncateString(stringify(elem), maxLength)
+		size, _ = jsonSize(elem)
+	}
+	if size <= budget {
+		return elem, size, true
+	}
+	str, ok := elem.(string)
+	if !ok {
+		return nil, 0, false
+	}
+	// the JSON encoding of a string is longer than the string because of the quotes
+	// and the escaped characters, up to 6 bytes each, so cut it until it fits
+	for length := minInt(len(str), budget-len(`""`)); length > 0; {
+		str = str[:length]
+		if size, _ = jsonSize(str); size <= budget {
+			return str, size, true
+		}
+		length -= (size - budget + 5) / 6
+	}
+	return nil, 0, false
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func jsonSize(value interface{}) (int, error) {
+	b, err := json.Marshal(value)
+	return len(b), err
+}
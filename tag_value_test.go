@@ -0,0 +1,127 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/jaeger-client-go/thrift-gen/zipkincore"
+)
+
+func TestStringifyTagValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		maxLength int
+		want      string
+	}{
+		{name: "strings", value: []string{"a", "b", "c"}, maxLength: 256, want: `["a","b","c"]`},
+		{name: "ints", value: []int64{1, 2, 3}, maxLength: 256, want: `[1,2,3]`},
+		{name: "array", value: [2]bool{true, false}, maxLength: 256, want: `[true,false]`},
+		{name: "mixed", value: []interface{}{"a", 1, nil, 1.5}, maxLength: 256, want: `["a",1,null,1.5]`},
+		{name: "nested", value: [][]string{{"a"}, {"b", "c"}}, maxLength: 256, want: `[["a"],["b","c"]]`},
+		{name: "map", value: map[string]int{"b": 2, "a": 1}, maxLength: 256, want: `{"a":1,"b":2}`},
+		{name: "map of strings", value: map[string][]string{"k": {"v"}}, maxLength: 256, want: `{"k":["v"]}`},
+		{name: "empty", value: []string{}, maxLength: 256, want: `[]`},
+		{name: "NaN", value: []float64{math.NaN()}, maxLength: 256, want: `["NaN"]`},
+		{name: "struct", value: struct{ A int }{1}, maxLength: 256, want: `{A:1}`},
+		{name: "non-string keys", value: map[int]int{1: 2}, maxLength: 256, want: `map[1:2]`},
+		{name: "partially dropped", value: []string{"abcdef", "ghijkl"}, maxLength: 12, want: `["abcdef"]`},
+		{name: "dropped elements", value: []string{"abc", "def", "ghi"}, maxLength: 13, want: `["abc","def"]`},
+		{name: "long elements", value: []string{"abcdefghij"}, maxLength: 10, want: `["abcdef"]`},
+		{name: "too short", value: []string{"abcdefghij"}, maxLength: 4, want: `[]`},
+		{name: "nested truncated", value: []interface{}{[]string{"abcdefghijklmnop"}}, maxLength: 8, want: `[["ab"]]`},
+		{name: "escaped", value: []string{"<<<<<<"}, maxLength: 16, want: `["\u003c\u003c"]`},
+		{name: "dropped keys", value: map[string]string{"a": "1", "b": "2"}, maxLength: 12, want: `{"a":"1"}`},
+		{name: "truncated map value", value: map[string]string{"a": "12345", "b": "2"}, maxLength: 10, want: `{"a":"12"}`},
+		{name: "truncated string element", value: []string{"abcdefghijklmnopqrstuvwxyz"}, maxLength: 20, want: `["abcdefghijklmnop"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stringifyTagValue(tt.value, tt.maxLength)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, len(got) <= tt.maxLength)
+		})
+	}
+}
+
+func TestTruncateTagValueElements(t *testing.T) {
+	value := truncateTagValue([]string{"abcdefghij", "k"}, 8)
+	assert.Equal(t, []interface{}{"abcd"}, value)
+	value = truncateTagValue([]string{"abcdefghij", "k"}, 30)
+	assert.Equal(t, []interface{}{"abcdefghij", "k"}, value)
+	value = truncateTagValue([]interface{}{1, []byte("abcdefghij")}, 5)
+	assert.Equal(t, []interface{}{1}, value)
+}
+
+func TestArrayTagsThrift(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter(),
+		TracerOptions.MaxTagValueLength(24))
+	defer closer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	defer span.Finish()
+	span.SetTag("strings", []string{"a", "b", "c"})
+	span.SetTag("long", []string{"abcdefgh", "ijklmnop", "qrstuvwx"})
+	span.SetTag("map", map[string]interface{}{"n": 1, "s": "x"})
+
+	jSpan := BuildJaegerThrift(span)
+	assert.Equal(t, `["a","b","c"]`, findTag(jSpan, "strings").GetVStr())
+	assert.Equal(t, `["abcdefgh","ijklmnop"]`, findTag(jSpan, "long").GetVStr())
+	var decoded []string
+	require.NoError(t, json.Unmarshal([]byte(findTag(jSpan, "long").GetVStr()), &decoded))
+	assert.Equal(t, `{"n":1,"s":"x"}`, findTag(jSpan, "map").GetVStr())
+
+	zSpan := BuildZipkinThrift(span)
+	for _, anno := range zSpan.BinaryAnnotations {
+		if anno.Key == "long" {
+			assert.Equal(t, zipkincore.AnnotationType_STRING, anno.AnnotationType)
+			assert.Equal(t, `["abcdefgh","ijklmnop"]`, string(anno.Value))
+		}
+	}
+
+	tags := make(map[string]interface{})
+	for _, tag := range span.TruncatedTags() {
+		tags[tag.Key] = tag.Value
+	}
+	assert.Equal(t, []interface{}{"a", "b", "c"}, tags["strings"])
+	assert.Equal(t, []interface{}{"abcdefgh", "ijklmnop"}, tags["long"])
+	assert.Equal(t, map[string]interface{}{"n": 1, "s": "x"}, tags["map"])
+	assert.Equal(t, "const", tags[SamplerTypeTagKey])
+}
+
+func TestTruncatedTagsScalars(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter(),
+		TracerOptions.MaxTagValueLength(3), TracerOptions.MaxTagsPerSpan(3))
+	defer closer.Close()
+
+	span := tracer.StartSpan("op").(*Span)
+	defer span.Finish()
+	span.SetTag("string", "abcdef")
+	span.SetTag("dropped", 1)
+
+	assert.Equal(t, []opentracing.Tag{
+		{Key: SamplerTypeTagKey, Value: "con"},
+		{Key: SamplerParamTagKey, Value: true},
+		{Key: "string", Value: "abc"},
+		{Key: DroppedTagsTagKey, Value: 1},
+	}, span.TruncatedTags())
+}
@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
@@ -36,12 +37,13 @@ import (
 // Tracer implements opentracing.Tracer.
 type Tracer struct {
 	serviceName string
-	hostIPv4    uint32 // this is for zipkin endpoint conversion
 
-	sampler  SamplerV2
-	reporter Reporter
-	metrics  Metrics
-	logger   log.DebugLogger
+	// components holds the *tracerComponents in use, swapped by Reconfigure.
+	components      atomic.Value
+	reconfigureLock sync.Mutex // serializes the calls to Reconfigure
+
+	metrics Metrics
+	logger  log.DebugLogger
 
 	timeNow      func() time.Time
 	randomNumber func() uint64
@@ -53,6 +55,15 @@ type Tracer struct {
 		maxTagValueLength           int
 		noDebugFlagOnForcedSampling bool
 		maxLogsPerSpan              int
+		maxTagsPerSpan              int
+		maxReferencesPerSpan        int
+		maxLinksPerSpan             int
+		maxFieldsPerLog             int
+		maxSpanSize                 int
+		pprofLabels                 bool
+		recordPanics                bool
+		tags                        []Tag  // tracer-level tags, extended with the default ones by NewTracer
+		hostIPv4                    uint32 // this is for zipkin endpoint conversion
 		// more options to come
 	}
 	// allocator of Span objects
@@ -63,9 +74,6 @@ type Tracer struct {
 
 	observer compositeObserver
 
-	tags    []Tag
-	process Process
-
 	baggageRestrictionManager baggage.RestrictionManager
 	baggageSetter             *baggageSetter
 
@@ -84,8 +92,6 @@ func NewTracer(
 ) (opentracing.Tracer, io.Closer) {
 	t := &Tracer{
 		serviceName:   serviceName,
-		sampler:       samplerV1toV2(sampler),
-		reporter:      reporter,
 		injectors:     make(map[interface{}]Injector),
 		extractors:    make(map[interface{}]Extractor),
 		metrics:       *NewNullMetrics(),
@@ -143,25 +149,6 @@ func NewTracer(
 	if t.logger == nil {
 		t.logger = log.NullLogger
 	}
-	// Set tracer-level tags
-	t.tags = append(t.tags, Tag{key: JaegerClientVersionTagKey, value: JaegerClientVersion})
-	if hostname, err := os.Hostname(); err == nil {
-		t.tags = append(t.tags, Tag{key: TracerHostnameTagKey, value: hostname})
-	}
-	if ipval, ok := t.getTag(TracerIPTagKey); ok {
-		ipv4, err := utils.ParseIPToUint32(ipval.(string))
-		if err != nil {
-			t.hostIPv4 = 0
-			t.logger.Error("Unable to convert the externally provided ip to uint32: " + err.Error())
-		} else {
-			t.hostIPv4 = ipv4
-		}
-	} else if ip, err := utils.HostIP(); err == nil {
-		t.tags = append(t.tags, Tag{key: TracerIPTagKey, value: ip.String()})
-		t.hostIPv4 = utils.PackIPAsUint32(ip)
-	} else {
-		t.logger.Error("Unable to determine this host's IP address: " + err.Error())
-	}
 
 	if t.options.gen128Bit {
 		if t.options.highTraceIDGenerator == nil {
@@ -174,18 +161,51 @@ func NewTracer(
 	if t.options.maxTagValueLength == 0 {
 		t.options.maxTagValueLength = DefaultMaxTagValueLength
 	}
-	t.process = Process{
-		Service: serviceName,
-		UUID:    strconv.FormatUint(t.randomNumber(), 16),
-		Tags:    t.tags,
-	}
+	tags, hostIPv4 := t.withDefaultTags(t.options.tags, t.options.hostIPv4)
+	t.components.Store(&tracerComponents{
+		sampler:  samplerV1toV2(sampler),
+		reporter: reporter,
+		reports:  newPendingReports(),
+		tags:     tags,
+		hostIPv4: hostIPv4,
+		process: Process{
+			Service: serviceName,
+			UUID:    strconv.FormatUint(t.randomNumber(), 16),
+			Tags:    tags,
+		},
+	})
 	if throttler, ok := t.debugThrottler.(ProcessSetter); ok {
-		throttler.SetProcess(t.process)
+		throttler.SetProcess(t.getComponents().process)
 	}
 
 	return t, t
 }
 
+// withDefaultTags returns the given tracer-level tags extended with the client version,
+// hostname and IP address tags, and the IPv4 address to use for Zipkin endpoints.
+// The hostIPv4 value is returned unchanged if the host IP address cannot be determined.
+func (t *Tracer) withDefaultTags(tags []Tag, hostIPv4 uint32) ([]Tag, uint32) {
+	tags = append(tags, Tag{key: JaegerClientVersionTagKey, value: JaegerClientVersion})
+	if hostname, err := os.Hostname(); err == nil {
+		tags = append(tags, Tag{key: TracerHostnameTagKey, value: hostname})
+	}
+	if ipval, ok := getTagValue(tags, TracerIPTagKey); ok {
+		ipv4, err := utils.ParseIPToUint32(ipval.(string))
+		if err != nil {
+			hostIPv4 = 0
+			t.logger.Error("Unable to convert the externally provided ip to uint32: " + err.Error())
+		} else {
+			hostIPv4 = ipv4
+		}
+	} else if ip, err := utils.HostIP(); err == nil {
+		tags = append(tags, Tag{key: TracerIPTagKey, value: ip.String()})
+		hostIPv4 = utils.PackIPAsUint32(ip)
+	} else {
+		t.logger.Error("Unable to determine this host's IP address: " + err.Error())
+	}
+	return tags, hostIPv4
+}
+
 // addCodec adds registers injector and extractor for given propagation format if not already defined.
 func (t *Tracer) addCodec(format interface{}, injector Injector, extractor Extractor) {
 	if _, ok := t.injectors[format]; !ok {
@@ -202,15 +222,21 @@ func (t *Tracer) StartSpan(
 	options ...opentracing.StartSpanOption,
 ) opentracing.Span {
 	sso := opentracing.StartSpanOptions{}
+	goroutineTask := false
 	for _, o := range options {
+		if _, ok := o.(goroutineTaskOption); ok {
+			goroutineTask = true
+			continue
+		}
 		o.Apply(&sso)
 	}
-	return t.startSpanWithOptions(operationName, sso)
+	return t.startSpanWithOptions(operationName, sso, goroutineTask)
 }
 
 func (t *Tracer) startSpanWithOptions(
 	operationName string,
 	options opentracing.StartSpanOptions,
+	goroutineTask bool,
 ) opentracing.Span {
 	if options.StartTime.IsZero() {
 		options.StartTime = t.timeNow()
@@ -260,6 +286,12 @@ func (t *Tracer) startSpanWithOptions(
 		// the FollowFromRef as the parent
 		hasParent = true
 	}
+	numDroppedReferences := 0
+	if maxRefs := t.options.maxReferencesPerSpan; maxRefs > 0 && len(references) > maxRefs {
+		numDroppedReferences = len(references) - maxRefs
+		references = references[:maxRefs]
+		t.metrics.SpanLimitDroppedReferences.Inc(int64(numDroppedReferences))
+	}
 
 	rpcServer := false
 	if v, ok := options.Tags[ext.SpanKindRPCServer.Key]; ok {
@@ -318,10 +350,12 @@ func (t *Tracer) startSpanWithOptions(
 	sp.startTime = options.StartTime
 	sp.duration = 0
 	sp.references = references
+	sp.numDroppedReferences = numDroppedReferences
 	sp.firstInProcess = rpcServer || sp.context.parentID == 0
+	sp.goroutineTask = goroutineTask
 
 	if !sp.context.isSamplingFinalized() {
-		decision := t.sampler.OnCreateSpan(sp)
+		decision := t.getSampler().OnCreateSpan(sp)
 		sp.applySamplingDecision(decision, false)
 	}
 	sp.observer = t.observer.OnStartSpan(sp, operationName, options)
@@ -370,8 +404,9 @@ func (t *Tracer) Extract(
 // Close releases all resources used by the Tracer and flushes any remaining buffered spans.
 func (t *Tracer) Close() error {
 	t.logger.Debugf("closing tracer")
-	t.reporter.Close()
-	t.sampler.Close()
+	components := t.getComponents()
+	components.reporter.Close()
+	components.sampler.Close()
 	if mgr, ok := t.baggageRestrictionManager.(io.Closer); ok {
 		_ = mgr.Close()
 	}
@@ -383,8 +418,9 @@ func (t *Tracer) Close() error {
 
 // Tags returns a slice of tracer-level tags.
 func (t *Tracer) Tags() []opentracing.Tag {
-	tags := make([]opentracing.Tag, len(t.tags))
-	for i, tag := range t.tags {
+	tracerTags := t.getComponents().tags
+	tags := make([]opentracing.Tag, len(tracerTags))
+	for i, tag := range tracerTags {
 		tags[i] = opentracing.Tag{Key: tag.key, Value: tag.value}
 	}
 	return tags
@@ -393,7 +429,11 @@ func (t *Tracer) Tags() []opentracing.Tag {
 // getTag returns the value of specific tag, if not exists, return nil.
 // TODO only used by tests, move there.
 func (t *Tracer) getTag(key string) (interface{}, bool) {
-	for _, tag := range t.tags {
+	return getTagValue(t.getComponents().tags, key)
+}
+
+func getTagValue(tags []Tag, key string) (interface{}, bool) {
+	for _, tag := range tags {
 		if tag.key == key {
 			return tag.value, true
 		}
@@ -451,7 +491,9 @@ func (t *Tracer) reportSpan(sp *Span) {
 	// and then Release() it when no longer needed.
 	// Otherwise, the span may be reused for another trace and its data may be overwritten.
 	if ctx.IsSampled() {
-		t.reporter.Report(sp)
+		components := t.acquireReporter()
+		components.reporter.Report(sp)
+		components.reports.done()
 	}
 
 	sp.Release()
@@ -477,9 +519,14 @@ func (t *Tracer) isDebugAllowed(operation string) bool {
 	return t.debugThrottler.IsAllowed(operation)
 }
 
-// Sampler returns the sampler given to the tracer at creation.
+// Sampler returns the sampler given to the tracer at creation, or the one
+// that replaced it via Reconfigure.
 func (t *Tracer) Sampler() SamplerV2 {
-	return t.sampler
+	return t.getSampler()
+}
+
+func (t *Tracer) getSampler() SamplerV2 {
+	return t.getComponents().sampler
 }
 
 // SelfRef creates an opentracing compliant SpanReference from a jaeger
@@ -95,7 +95,7 @@ func (tracerOptions) PoolSpans(poolSpans bool) TracerOption {
 // The TracerOption is deprecated; the tracer will attempt to automatically detect the IP.
 func (tracerOptions) HostIPv4(hostIPv4 uint32) TracerOption {
 	return func(tracer *Tracer) {
-		tracer.hostIPv4 = hostIPv4
+		tracer.options.hostIPv4 = hostIPv4
 	}
 }
 
@@ -157,6 +157,78 @@ func (tracerOptions) MaxLogsPerSpan(maxLogsPerSpan int) TracerOption {
 	}
 }
 
+// MaxTagsPerSpan limits the number of tags in a span (if set to a nonzero value).
+// Once a span has this many tags, new tag keys are dropped, while the tags already
+// set can still be updated. The number of dropped tags is reported in the
+// jaeger.dropped_tags tag.
+func (tracerOptions) MaxTagsPerSpan(maxTagsPerSpan int) TracerOption {
+	return func(tracer *Tracer) {
+		tracer.options.maxTagsPerSpan = maxTagsPerSpan
+	}
+}
+
+// MaxReferencesPerSpan limits the number of references of a span (if set to a
+// nonzero value). The references beyond the limit are dropped, and their number
+// is reported in the jaeger.dropped_references tag.
+func (tracerOptions) MaxReferencesPerSpan(maxReferencesPerSpan int) TracerOption {
+	return func(tracer *Tracer) {
+		tracer.options.maxReferencesPerSpan = maxReferencesPerSpan
+	}
+}
+
+// MaxLinksPerSpan limits the number of links added to a span with Span.AddLink
+// (if set to a nonzero value). The links beyond the limit are dropped, and their
+// number is reported in the jaeger.dropped_links tag.
+func (tracerOptions) MaxLinksPerSpan(maxLinksPerSpan int) TracerOption {
+	return func(tracer *Tracer) {
+		tracer.options.maxLinksPerSpan = maxLinksPerSpan
+	}
+}
+
+// MaxFieldsPerLog limits the number of fields in each log of a span (if set to a
+// nonzero value). The fields beyond the limit are dropped, and their total number
+// is reported in the jaeger.dropped_log_fields tag.
+func (tracerOptions) MaxFieldsPerLog(maxFieldsPerLog int) TracerOption {
+	return func(tracer *Tracer) {
+		tracer.options.maxFieldsPerLog = maxFieldsPerLog
+	}
+}
+
+// MaxSpanSize limits the size in bytes of a span serialized with the Thrift compact
+// protocol, as sent to the agent (if set to a nonzero value). When a span exceeds
+// it on Finish, its newest logs and then its newest tags are dropped until it fits,
+// and their numbers are reported in the jaeger.dropped_logs and jaeger.dropped_tags tags.
+func (tracerOptions) MaxSpanSize(maxSpanSize int) TracerOption {
+	return func(tracer *Tracer) {
+		tracer.options.maxSpanSize = maxSpanSize
+	}
+}
+
+// PprofLabels sets the trace_id, span_id and operation pprof labels (see runtime/pprof)
+// on the goroutine running a sampled local root span, so that CPU profiles can be
+// attributed to traces. The labels are set by Tracer.StartSpanFromContext, in addition
+// to the labels of the context, which are restored by Finish, and the context it returns
+// holds the labels of the span, to be passed to pprof.Do. The spans of Tracer.Go and
+// Group.Go get the labels on the goroutine running the function. StartSpan does not set
+// labels, since it does not know the labels to restore.
+//
+// The labels are restored on the goroutine calling Finish, so the span must be finished
+// on the goroutine that started it.
+func (tracerOptions) PprofLabels(pprofLabels bool) TracerOption {
+	return func(tracer *Tracer) {
+		tracer.options.pprofLabels = pprofLabels
+	}
+}
+
+// RecordPanics makes Span.Finish behave like Span.FinishWithRecover when it is deferred:
+// a panic of the function deferring it is recorded on the span, which is finished and
+// reported synchronously before the panic is propagated.
+func (tracerOptions) RecordPanics(recordPanics bool) TracerOption {
+	return func(tracer *Tracer) {
+		tracer.options.recordPanics = recordPanics
+	}
+}
+
 func (tracerOptions) ZipkinSharedRPCSpan(zipkinSharedRPCSpan bool) TracerOption {
 	return func(tracer *Tracer) {
 		tracer.options.zipkinSharedRPCSpan = zipkinSharedRPCSpan
@@ -165,7 +237,7 @@ func (tracerOptions) ZipkinSharedRPCSpan(zipkinSharedRPCSpan bool) TracerOption
 
 func (tracerOptions) Tag(key string, value interface{}) TracerOption {
 	return func(tracer *Tracer) {
-		tracer.tags = append(tracer.tags, Tag{key: key, value: value})
+		tracer.options.tags = append(tracer.options.tags, Tag{key: key, value: value})
 	}
 }
 
@@ -0,0 +1,179 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// ReconfigureOption is a function that sets a component replaced by Tracer.Reconfigure.
+type ReconfigureOption func(r *reconfiguration)
+
+// ReconfigureOptions is a factory for all available ReconfigureOption's.
+var ReconfigureOptions reconfigureOptions
+
+type reconfigureOptions struct{}
+
+type reconfiguration struct {
+	sampler  SamplerV2
+	reporter Reporter
+	tags     []Tag
+	setTags  bool
+}
+
+// Sampler creates a ReconfigureOption that replaces the sampler of the tracer.
+func (reconfigureOptions) Sampler(sampler Sampler) ReconfigureOption {
+	return func(r *reconfiguration) {
+		r.sampler = samplerV1toV2(sampler)
+	}
+}
+
+// Reporter creates a ReconfigureOption that replaces the reporter of the tracer.
+func (reconfigureOptions) Reporter(reporter Reporter) ReconfigureOption {
+	return func(r *reconfiguration) {
+		r.reporter = reporter
+	}
+}
+
+// Tags creates a ReconfigureOption that replaces the tracer-level tags previously
+// set via TracerOptions.Tag. The client version, hostname and IP address tags are
+// added automatically, as in NewTracer.
+func (reconfigureOptions) Tags(tags []opentracing.Tag) ReconfigureOption {
+	return func(r *reconfiguration) {
+		r.tags = make([]Tag, len(tags))
+		for i, tag := range tags {
+			r.tags[i] = Tag{key: tag.Key, value: tag.Value}
+		}
+		r.setTags = true
+	}
+}
+
+// Reconfigure atomically replaces the sampler, the reporter and/or the tracer-level
+// tags of a running tracer. The spans started after the call use the new components,
+// while the spans in progress keep their sampling state.
+//
+// The replaced sampler and reporter are closed once the swap is done. Closing the
+// reporter waits for all the spans handed to it to be flushed, so Reconfigure blocks
+// until the previous reporter is drained. When only the tags are replaced, the reporter
+// is told about the new process if it implements ProcessSetter, as the remote reporter does.
+func (t *Tracer) Reconfigure(options ...ReconfigureOption) {
+	r := &reconfiguration{}
+	for _, option := range options {
+		option(r)
+	}
+
+	t.reconfigureLock.Lock()
+	old := t.getComponents()
+	components := *old
+	if r.sampler != nil {
+		components.sampler = r.sampler
+	}
+	if r.reporter != nil {
+		components.reporter = r.reporter
+		components.reports = newPendingReports()
+	}
+	if r.setTags {
+		components.tags, components.hostIPv4 = t.withDefaultTags(r.tags, old.hostIPv4)
+		components.process = Process{
+			Service: t.serviceName,
+			UUID:    old.process.UUID,
+			Tags:    components.tags,
+		}
+	}
+	t.components.Store(&components)
+	t.reconfigureLock.Unlock()
+
+	if r.setTags {
+		if throttler, ok := t.debugThrottler.(ProcessSetter); ok {
+			throttler.SetProcess(components.process)
+		}
+		if reporter, ok := components.reporter.(ProcessSetter); ok {
+			reporter.SetProcess(components.process)
+		}
+	}
+	if r.sampler != nil && r.sampler != old.sampler {
+		old.sampler.Close()
+	}
+	if r.reporter != nil && r.reporter != old.reporter {
+		old.reports.wait()
+		old.reporter.Close()
+	}
+	t.logger.Debugf("tracer reconfigured")
+}
+
+// tracerComponents are the parts of the tracer that can be replaced by Reconfigure.
+// They are never modified once stored in Tracer.components, Reconfigure stores a copy.
+type tracerComponents struct {
+	sampler  SamplerV2
+	reporter Reporter
+	reports  *pendingReports // the spans being handed to the reporter
+	tags     []Tag
+	process  Process
+	hostIPv4 uint32 // this is for zipkin endpoint conversion
+}
+
+func (t *Tracer) getComponents() *tracerComponents {
+	return t.components.Load().(*tracerComponents)
+}
+
+// acquireReporter returns the current components, with a span counted as pending
+// in their reports until reports.done() is called. The span is only counted if the
+// components are still current once the count is incremented, so that Reconfigure
+// cannot close the reporter without waiting for it.
+func (t *Tracer) acquireReporter() *tracerComponents {
+	for {
+		components := t.getComponents()
+		components.reports.add()
+		if t.getComponents() == components {
+			return components
+		}
+		components.reports.done()
+	}
+}
+
+// pendingReports counts the spans being handed to a reporter.
+type pendingReports struct {
+	count   int64 // must be first in the struct for 64-bit alignment
+	retired int32
+	once    sync.Once
+	drained chan struct{}
+}
+
+func newPendingReports() *pendingReports {
+	return &pendingReports{drained: make(chan struct{})}
+}
+
+func (p *pendingReports) add() {
+	atomic.AddInt64(&p.count, 1)
+}
+
+func (p *pendingReports) done() {
+	if atomic.AddInt64(&p.count, -1) == 0 && atomic.LoadInt32(&p.retired) == 1 {
+		p.once.Do(func() { close(p.drained) })
+	}
+}
+
+// wait blocks until all the pending spans are reported. It must only be called
+// once the reporter is replaced, so that no new span is counted.
+func (p *pendingReports) wait() {
+	atomic.StoreInt32(&p.retired, 1)
+	if atomic.LoadInt64(&p.count) == 0 {
+		p.once.Do(func() { close(p.drained) })
+	}
+	<-p.drained
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/jaeger-client-go/testutils"
+	j "github.com/uber/jaeger-client-go/thrift-gen/jaeger"
+)
+
+// closeCheckingReporter counts the reported spans and fails the test
+// if a span is reported after Close.
+type closeCheckingReporter struct {
+	t        *testing.T
+	reported int64
+	closed   int64
+}
+
+func (r *closeCheckingReporter) Report(span *Span) {
+	assert.EqualValues(r.t, 0, atomic.LoadInt64(&r.closed), "span reported after Close")
+	atomic.AddInt64(&r.reported, 1)
+}
+
+func (r *closeCheckingReporter) Close() {
+	atomic.AddInt64(&r.closed, 1)
+}
+
+func TestTracerReconfigure(t *testing.T) {
+	oldReporter := &closeCheckingReporter{t: t}
+	tracer, closer := NewTracer("svc", NewConstSampler(false), oldReporter, TracerOptions.Tag("version", "1"))
+	jTracer := tracer.(*Tracer)
+
+	tracer.StartSpan("before").Finish()
+	assert.EqualValues(t, 0, oldReporter.reported)
+
+	newReporter := &closeCheckingReporter{t: t}
+	jTracer.Reconfigure(
+		ReconfigureOptions.Sampler(NewConstSampler(true)),
+		ReconfigureOptions.Reporter(newReporter),
+		ReconfigureOptions.Tags([]opentracing.Tag{{Key: "version", Value: "2"}}),
+	)
+	assert.EqualValues(t, 1, oldReporter.closed)
+
+	tracer.StartSpan("after").Finish()
+	assert.EqualValues(t, 1, newReporter.reported)
+	assert.IsType(t, &ConstSampler{}, jTracer.Sampler())
+	assert.True(t, jTracer.Sampler().(*ConstSampler).Decision)
+
+	version, ok := jTracer.getTag("version")
+	require.True(t, ok)
+	assert.Equal(t, "2", version)
+	_, ok = jTracer.getTag(JaegerClientVersionTagKey)
+	assert.True(t, ok, "default tags must be kept")
+	assert.Equal(t, jTracer.getComponents().tags, jTracer.getComponents().process.Tags)
+
+	// components not provided are kept
+	uuid := jTracer.getComponents().process.UUID
+	jTracer.Reconfigure(ReconfigureOptions.Sampler(NewConstSampler(false)))
+	assert.EqualValues(t, 0, newReporter.closed)
+	assert.Equal(t, uuid, jTracer.getComponents().process.UUID)
+	version, _ = jTracer.getTag("version")
+	assert.Equal(t, "2", version)
+
+	require.NoError(t, closer.Close())
+	assert.EqualValues(t, 1, newReporter.closed)
+}
+
+func TestTracerReconfigureUpdatesThrottlerProcess(t *testing.T) {
+	throttler := &testDebugThrottler{}
+	tracer, closer := NewTracer("svc", NewConstSampler(true), NewNullReporter(), TracerOptions.DebugThrottler(throttler))
+	defer closer.Close()
+
+	tracer.(*Tracer).Reconfigure(ReconfigureOptions.Tags([]opentracing.Tag{{Key: "k", Value: "v"}}))
+	assert.Equal(t, tracer.(*Tracer).getComponents().process, throttler.process)
+	assert.Equal(t, "k", throttler.process.Tags[0].key)
+}
+
+func TestTracerReconfigureUnderLoad(t *testing.T) {
+	tracer, closer := NewTracer("svc", NewConstSampler(true), &closeCheckingReporter{t: t})
+	jTracer := tracer.(*Tracer)
+	reporters := []*closeCheckingReporter{jTracer.getComponents().reporter.(*closeCheckingReporter)}
+
+	const workers, spansPerWorker = 4, 500
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < spansPerWorker; j++ {
+				parent := tracer.StartSpan("parent")
+				tracer.StartSpan("child", opentracing.ChildOf(parent.Context())).Finish()
+				parent.Finish()
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		reporter := &closeCheckingReporter{t: t}
+		reporters = append(reporters, reporter)
+		jTracer.Reconfigure(
+			ReconfigureOptions.Reporter(reporter),
+			ReconfigureOptions.Sampler(NewConstSampler(true)),
+		)
+	}
+	wg.Wait()
+	require.NoError(t, closer.Close())
+
+	var reported int64
+	for _, r := range reporters {
+		assert.EqualValues(t, 1, r.closed)
+		reported += r.reported
+	}
+	assert.EqualValues(t, workers*spansPerWorker*2, reported)
+}
+
+func TestTracerReconfigureTagsWithUDPTransport(t *testing.T) {
+	agent, err := testutils.StartMockAgent()
+	require.NoError(t, err)
+	defer agent.Close()
+
+	sender, err := NewUDPTransport(agent.SpanServerAddr(), 0)
+	require.NoError(t, err)
+	reporter := NewRemoteReporter(sender, ReporterOptions.BufferFlushInterval(time.Millisecond))
+	tracer, closer := NewTracer("svc", NewConstSampler(true), reporter, TracerOptions.Tag("version", "1"))
+	waitForBatches := func(n int) []*j.Batch {
+		for i := 0; i < 1000 && len(agent.GetJaegerBatches()) < n; i++ {
+			time.Sleep(time.Millisecond)
+		}
+		batches := agent.GetJaegerBatches()
+		require.Len(t, batches, n)
+		return batches
+	}
+	tracer.StartSpan("before").Finish()
+	waitForBatches(1)
+	tracer.(*Tracer).Reconfigure(ReconfigureOptions.Tags([]opentracing.Tag{{Key: "version", Value: "2"}}))
+	tracer.StartSpan("after").Finish()
+	require.NoError(t, closer.Close())
+
+	batches := waitForBatches(2)
+	for i, version := range []string{"1", "2"} {
+		var tags []string
+		for _, tag := range batches[i].Process.Tags {
+			if tag.Key == "version" {
+				tags = append(tags, tag.GetVStr())
+			}
+		}
+		assert.Equal(t, []string{version}, tags)
+	}
+}
@@ -184,7 +184,7 @@ func (s *tracerSuite) TestTraceStartedOrJoinedMetrics() {
 	}
 	for _, test := range tests {
 		s.metricsFactory.Clear()
-		s.tracer.(*Tracer).sampler = NewConstSampler(test.sampled)
+		s.tracer.(*Tracer).Reconfigure(ReconfigureOptions.Sampler(NewConstSampler(test.sampled)))
 		sp1 := s.tracer.StartSpan("parent", ext.RPCServerOption(nil))
 		sp2 := s.tracer.StartSpan("child1", opentracing.ChildOf(sp1.Context()))
 		sp3 := s.tracer.StartSpan("child2", ext.RPCServerOption(sp2.Context()))
@@ -212,11 +212,11 @@ func (s *tracerSuite) TestSetOperationName() {
 }
 
 func (s *tracerSuite) TestSamplerEffects() {
-	s.tracer.(*Tracer).sampler = NewConstSampler(true)
+	s.tracer.(*Tracer).Reconfigure(ReconfigureOptions.Sampler(NewConstSampler(true)))
 	sp := s.tracer.StartSpan("test")
 	s.True(sp.(*Span).context.IsSampled())
 
-	s.tracer.(*Tracer).sampler = NewConstSampler(false)
+	s.tracer.(*Tracer).Reconfigure(ReconfigureOptions.Sampler(NewConstSampler(false)))
 	sp = s.tracer.StartSpan("test")
 	s.False(sp.(*Span).context.IsSampled())
 }
@@ -380,7 +380,7 @@ func TestDebugThrottler(t *testing.T) {
 	opentracingTracer, tc := NewTracer("x", NewConstSampler(true), NewNullReporter(), TracerOptions.DebugThrottler(throttler))
 	assert.NoError(t, tc.Close())
 	tracer := opentracingTracer.(*Tracer)
-	assert.Equal(t, tracer.process, throttler.process)
+	assert.Equal(t, tracer.getComponents().process, throttler.process)
 }
 
 func TestThrottling_SamplingPriority(t *testing.T) {
@@ -433,7 +433,7 @@ func TestSetGetTag(t *testing.T) {
 	assert.True(t, ok)
 	_, ok = value.(string)
 	assert.True(t, ok)
-	assert.True(t, tracer.hostIPv4 != 0)
+	assert.True(t, tracer.getComponents().hostIPv4 != 0)
 
 	ipStr := "11.22.33.44"
 	opentracer, tc = NewTracer("x", NewConstSampler(true), NewNullReporter(), TracerOptions.Tag(TracerIPTagKey, ipStr))
@@ -442,7 +442,7 @@ func TestSetGetTag(t *testing.T) {
 	value, ok = tracer.getTag(TracerIPTagKey)
 	assert.True(t, ok)
 	assert.True(t, value == ipStr)
-	assert.True(t, tracer.hostIPv4 != 0)
+	assert.True(t, tracer.getComponents().hostIPv4 != 0)
 
 	ipStrInvalid := "an invalid input"
 	opentracer, tc = NewTracer("x", NewConstSampler(true), NewNullReporter(), TracerOptions.Tag(TracerIPTagKey, ipStrInvalid))
@@ -451,7 +451,7 @@ func TestSetGetTag(t *testing.T) {
 	value, ok = tracer.getTag(TracerIPTagKey)
 	assert.True(t, ok)
 	assert.True(t, value == ipStrInvalid)
-	assert.True(t, tracer.hostIPv4 == 0)
+	assert.True(t, tracer.getComponents().hostIPv4 == 0)
 }
 
 func TestTracerGetSampler(t *testing.T) {
@@ -17,12 +17,15 @@ package transport
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"time"
 
+	"github.com/uber/jaeger-lib/metrics"
+
 	"github.com/uber/jaeger-client-go/thrift"
 
 	"github.com/uber/jaeger-client-go"
@@ -33,6 +36,8 @@ import (
 const defaultHTTPTimeout = time.Second * 5
 
 // HTTPTransport implements Transport by forwarding spans to a http server.
+// When several collector endpoints are configured, the batches are balanced
+// across them in round robin and a failed batch is retried on the next endpoint.
 type HTTPTransport struct {
 	url             string
 	client          *http.Client
@@ -40,7 +45,16 @@ type HTTPTransport struct {
 	spans           []*j.Span
 	process         *j.Process
 	httpCredentials *HTTPBasicAuthCredentials
+	credentials     jaeger.HTTPCredentials
 	headers         map[string]string
+
+	extraURLs               []string
+	srvName                 string
+	srvRefreshInterval      time.Duration
+	circuitFailureThreshold int
+	circuitOpenDuration     time.Duration
+	metrics                 metrics.Factory
+	endpoints               *httpEndpoints
 }
 
 // HTTPBasicAuthCredentials stores credentials for HTTP basic auth.
@@ -70,6 +84,57 @@ func HTTPBasicAuth(username string, password string) HTTPOption {
 	}
 }
 
+// HTTPCredentials sets the credentials applied to every request sent to the collector,
+// e.g. jaeger.NewTokenFileCredentials for bearer tokens. They replace the HTTPBasicAuth
+// credentials, if any.
+func HTTPCredentials(credentials jaeger.HTTPCredentials) HTTPOption {
+	return func(c *HTTPTransport) {
+		c.credentials = credentials
+	}
+}
+
+// HTTPEndpoints adds collector URLs to the one passed to NewHTTPTransport. The batches
+// are sent to the endpoints in round robin, and retried on the next endpoint when
+// the request fails with a network error or a 408, 429 or 5xx status code.
+func HTTPEndpoints(urls ...string) HTTPOption {
+	return func(c *HTTPTransport) {
+		c.extraURLs = append(c.extraURLs, urls...)
+	}
+}
+
+// HTTPSRVLookup discovers the collector endpoints by resolving the DNS SRV record with the
+// given name, e.g. "_jaeger-collector._tcp.example.com", every refreshInterval (default 30s).
+// The URLs of the endpoints are built from the url passed to NewHTTPTransport, replacing its
+// host with the target and port of each record. The record is resolved in the background:
+// the configured URLs are used until it is successfully resolved, and the last resolved
+// endpoints are kept if a lookup fails. The priority and weight of the records are ignored.
+func HTTPSRVLookup(name string, refreshInterval time.Duration) HTTPOption {
+	return func(c *HTTPTransport) {
+		c.srvName = name
+		c.srvRefreshInterval = refreshInterval
+	}
+}
+
+// HTTPCircuitBreaker configures the circuit breaker of each collector endpoint: after
+// failureThreshold consecutive failures (default 5) the endpoint is skipped for openDuration
+// (default 10s), then a single batch is sent to probe it. If the circuit breakers of all the
+// endpoints are open, the batches are dropped without sending any request.
+func HTTPCircuitBreaker(failureThreshold int, openDuration time.Duration) HTTPOption {
+	return func(c *HTTPTransport) {
+		c.circuitFailureThreshold = failureThreshold
+		c.circuitOpenDuration = openDuration
+	}
+}
+
+// HTTPMetrics sets the factory of the per-endpoint metrics: jaeger_tracer_collector_requests,
+// tagged with the endpoint host and result=ok|err, and jaeger_tracer_collector_circuit_breaks,
+// counting how many times the circuit breaker of an endpoint opened.
+func HTTPMetrics(factory metrics.Factory) HTTPOption {
+	return func(c *HTTPTransport) {
+		c.metrics = factory
+	}
+}
+
 // HTTPRoundTripper configures the underlying Transport on the *http.Client
 // that is used
 func HTTPRoundTripper(transport http.RoundTripper) HTTPOption {
@@ -78,6 +143,17 @@ func HTTPRoundTripper(transport http.RoundTripper) HTTPOption {
 	}
 }
 
+// HTTPTLSConfig sets the TLS configuration used to connect to an https collector,
+// e.g. to provide a custom CA or a client certificate. It replaces the transport of
+// the underlying *http.Client, so it should not be combined with HTTPRoundTripper.
+func HTTPTLSConfig(tlsConfig *tls.Config) HTTPOption {
+	return func(c *HTTPTransport) {
+		customTransport := http.DefaultTransport.(*http.Transport).Clone()
+		customTransport.TLSClientConfig = tlsConfig
+		c.client.Transport = customTransport
+	}
+}
+
 // HTTPHeaders defines the HTTP headers that will be attached to the jaeger client's HTTP request
 func HTTPHeaders(headers map[string]string) HTTPOption {
 	return func(c *HTTPTransport) {
@@ -94,11 +170,13 @@ func NewHTTPTransport(url string, options ...HTTPOption) *HTTPTransport {
 		client:    &http.Client{Timeout: defaultHTTPTimeout},
 		batchSize: 100,
 		spans:     []*j.Span{},
+		metrics:   metrics.NullFactory,
 	}
 
 	for _, option := range options {
 		option(c)
 	}
+	c.endpoints = newHTTPEndpoints(append([]string{url}, c.extraURLs...), c)
 	return c
 }
 
@@ -115,6 +193,12 @@ func (c *HTTPTransport) Append(span *jaeger.Span) (int, error) {
 	return 0, nil
 }
 
+// SetProcess implements jaeger.ProcessSetter. The process is built again from the next
+// appended span, which carries the current tracer-level tags.
+func (c *HTTPTransport) SetProcess(process jaeger.Process) {
+	c.process = nil
+}
+
 // Flush implements Transport.
 func (c *HTTPTransport) Flush() (int, error) {
 	count := len(c.spans)
@@ -140,29 +224,44 @@ func (c *HTTPTransport) send(spans []*j.Span) error {
 	if err != nil {
 		return err
 	}
-	req, err := http.NewRequest("POST", c.url, body)
+	return c.endpoints.do(func(url string) (bool, error) {
+		return c.post(url, body.Bytes())
+	})
+}
+
+// post sends the serialized batch to the collector at url, and reports whether
+// the request is worth retrying on another endpoint if it fails.
+func (c *HTTPTransport) post(url string, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
 	if err != nil {
-		return err
+		return false, err
 	}
 	req.Header.Set("Content-Type", "application/x-thrift")
 	for k, v := range c.headers {
 		req.Header.Set(k, v)
 	}
 
-	if c.httpCredentials != nil {
+	if c.credentials != nil {
+		if err := c.credentials.Apply(req); err != nil {
+			return false, err
+		}
+	} else if c.httpCredentials != nil {
 		req.SetBasicAuth(c.httpCredentials.username, c.httpCredentials.password)
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return err
+		return true, err
 	}
 	io.Copy(ioutil.Discard, resp.Body)
 	resp.Body.Close()
 	if resp.StatusCode >= http.StatusBadRequest {
-		return fmt.Errorf("error from collector: %d", resp.StatusCode)
+		retryable := resp.StatusCode >= http.StatusInternalServerError ||
+			resp.StatusCode == http.StatusRequestTimeout ||
+			resp.StatusCode == http.StatusTooManyRequests
+		return retryable, fmt.Errorf("error from collector: %d", resp.StatusCode)
 	}
-	return nil
+	return false, nil
 }
 
 func serializeThrift(obj thrift.TStruct) (*bytes.Buffer, error) {
@@ -0,0 +1,245 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/jaeger-lib/metrics"
+)
+
+const (
+	defaultCircuitFailureThreshold = 5
+	defaultCircuitOpenDuration     = 10 * time.Second
+	defaultSRVRefreshInterval      = 30 * time.Second
+	srvLookupTimeout               = 5 * time.Second
+)
+
+// errNoEndpointAvailable is returned when the circuit breakers of all the collector endpoints are open.
+var errNoEndpointAvailable = errors.New("no collector endpoint available, all circuit breakers are open")
+
+// httpEndpoint is a collector endpoint along with the state of its circuit breaker.
+type httpEndpoint struct {
+	url string
+
+	// consecutiveFailures is the number of requests that failed since the last success.
+	consecutiveFailures int
+	// openUntil is the time until which the circuit breaker is open, i.e. the endpoint is skipped.
+	openUntil time.Time
+
+	requestsOK    metrics.Counter
+	requestsErr   metrics.Counter
+	circuitOpened metrics.Counter
+}
+
+// httpEndpoints balances the requests across the collector endpoints in round robin,
+// skipping the endpoints whose circuit breaker is open. A circuit breaker opens after
+// failureThreshold consecutive failures, and lets a single request through once
+// openDuration has elapsed: the circuit closes if it succeeds, or stays open for
+// another openDuration otherwise.
+type httpEndpoints struct {
+	failureThreshold int
+	openDuration     time.Duration
+	metrics          metrics.Factory
+	timeNow          func() time.Time
+
+	// srvName, if set, is the DNS SRV record resolved every srvRefreshInterval
+	// into the endpoints, using the first configured endpoint as a template.
+	srvName            string
+	srvRefreshInterval time.Duration
+	lookupSRV          func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	template           *url.URL
+
+	sync.Mutex
+	endpoints    []*httpEndpoint
+	next         int
+	srvExpires   time.Time
+	srvResolving bool // whether a lookup of the SRV record is in progress
+}
+
+func newHTTPEndpoints(urls []string, t *HTTPTransport) *httpEndpoints {
+	e := &httpEndpoints{
+		failureThreshold:   t.circuitFailureThreshold,
+		openDuration:       t.circuitOpenDuration,
+		metrics:            t.metrics.Namespace(metrics.NSOptions{Name: "jaeger"}).Namespace(metrics.NSOptions{Name: "tracer"}),
+		timeNow:            time.Now,
+		srvName:            t.srvName,
+		srvRefreshInterval: t.srvRefreshInterval,
+		lookupSRV:          net.DefaultResolver.LookupSRV,
+	}
+	if e.failureThreshold <= 0 {
+		e.failureThreshold = defaultCircuitFailureThreshold
+	}
+	if e.openDuration <= 0 {
+		e.openDuration = defaultCircuitOpenDuration
+	}
+	if e.srvRefreshInterval <= 0 {
+		e.srvRefreshInterval = defaultSRVRefreshInterval
+	}
+	if e.srvName != "" {
+		// an invalid template is reported by the requests sent to the configured endpoints
+		e.template, _ = url.Parse(urls[0])
+	}
+	e.endpoints = e.newEndpoints(urls, nil)
+	return e
+}
+
+// newEndpoints creates the endpoints for the given URLs, reusing the previous
+// endpoints with the same URL to keep the state of their circuit breaker.
+func (e *httpEndpoints) newEndpoints(urls []string, previous []*httpEndpoint) []*httpEndpoint {
+	endpoints := make([]*httpEndpoint, 0, len(urls))
+	for _, u := range urls {
+		endpoint := findEndpoint(previous, u)
+		if endpoint == nil {
+			host := u
+			if parsed, err := url.Parse(u); err == nil && parsed.Host != "" {
+				host = parsed.Host
+			}
+			endpoint = &httpEndpoint{
+				url:           u,
+				requestsOK:    e.counter("collector_requests", host, "ok"),
+				requestsErr:   e.counter("collector_requests", host, "err"),
+				circuitOpened: e.counter("collector_circuit_breaks", host, ""),
+			}
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+func (e *httpEndpoints) counter(name, host, result string) metrics.Counter {
+	tags := map[string]string{"endpoint": host}
+	if result != "" {
+		tags["result"] = result
+	}
+	return e.metrics.Counter(metrics.Options{Name: name, Tags: tags})
+}
+
+func findEndpoint(endpoints []*httpEndpoint, url string) *httpEndpoint {
+	for _, endpoint := range endpoints {
+		if endpoint.url == url {
+			return endpoint
+		}
+	}
+	return nil
+}
+
+// do calls send with the URL of each available endpoint in round robin order, until
+// it succeeds or returns an error that is not worth retrying on another endpoint.
+// It returns the last error if all the endpoints failed.
+func (e *httpEndpoints) do(send func(url string) (retryable bool, err error)) error {
+	candidates := e.candidates()
+	if len(candidates) == 0 {
+		return errNoEndpointAvailable
+	}
+	var err error
+	for _, endpoint := range candidates {
+		var retryable bool
+		retryable, err = send(endpoint.url)
+		e.record(endpoint, err == nil || !retryable)
+		if err == nil {
+			endpoint.requestsOK.Inc(1)
+			return nil
+		}
+		endpoint.requestsErr.Inc(1)
+		if !retryable {
+			return err
+		}
+	}
+	return err
+}
+
+// candidates returns the endpoints whose circuit breaker is closed or can be probed,
+// starting with the next endpoint in round robin order.
+func (e *httpEndpoints) candidates() []*httpEndpoint {
+	e.Lock()
+	defer e.Unlock()
+	now := e.timeNow()
+	e.refreshSRVLocked(now)
+	if len(e.endpoints) == 0 {
+		return nil
+	}
+	start := e.next % len(e.endpoints)
+	e.next = start + 1
+	candidates := make([]*httpEndpoint, 0, len(e.endpoints))
+	for i := range e.endpoints {
+		endpoint := e.endpoints[(start+i)%len(e.endpoints)]
+		if !now.Before(endpoint.openUntil) {
+			candidates = append(candidates, endpoint)
+		}
+	}
+	return candidates
+}
+
+// record updates the circuit breaker of the endpoint with the outcome of a request.
+// A request that failed with a non-retryable error, e.g. because the collector rejected
+// the payload, shows that the endpoint is healthy.
+func (e *httpEndpoints) record(endpoint *httpEndpoint, healthy bool) {
+	e.Lock()
+	defer e.Unlock()
+	if healthy {
+		endpoint.consecutiveFailures = 0
+		endpoint.openUntil = time.Time{}
+		return
+	}
+	endpoint.consecutiveFailures++
+	if endpoint.consecutiveFailures >= e.failureThreshold {
+		if endpoint.openUntil.IsZero() {
+			endpoint.circuitOpened.Inc(1)
+		}
+		endpoint.openUntil = e.timeNow().Add(e.openDuration)
+	}
+}
+
+// refreshSRVLocked starts resolving the SRV record in the background if it expired,
+// so that the requests are sent to the current endpoints in the meantime.
+func (e *httpEndpoints) refreshSRVLocked(now time.Time) {
+	if e.srvName == "" || e.template == nil || e.srvResolving || now.Before(e.srvExpires) {
+		return
+	}
+	e.srvResolving = true
+	go e.resolveSRV()
+}
+
+// resolveSRV replaces the endpoints with the targets of the SRV record. The current
+// endpoints are kept if the lookup fails or returns no records. Like the configured
+// endpoints, the targets are used in round robin: their priority and weight are ignored.
+func (e *httpEndpoints) resolveSRV() {
+	ctx, cancel := context.WithTimeout(context.Background(), srvLookupTimeout)
+	defer cancel()
+	_, records, err := e.lookupSRV(ctx, "", "", e.srvName)
+
+	e.Lock()
+	defer e.Unlock()
+	e.srvResolving = false
+	e.srvExpires = e.timeNow().Add(e.srvRefreshInterval)
+	if err != nil || len(records) == 0 {
+		return
+	}
+	urls := make([]string, 0, len(records))
+	for _, record := range records {
+		u := *e.template
+		u.Host = net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port)))
+		urls = append(urls, u.String())
+	}
+	e.endpoints = e.newEndpoints(urls, e.endpoints)
+}
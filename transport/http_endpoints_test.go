@@ -0,0 +1,235 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+
+	j "github.com/uber/jaeger-client-go/thrift-gen/jaeger"
+)
+
+type collector struct {
+	*httptest.Server
+	status   int32
+	requests int32
+}
+
+func newCollector() *collector {
+	c := &collector{status: http.StatusAccepted}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&c.requests, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&c.status)))
+	}))
+	return c
+}
+
+func (c *collector) setStatus(status int) { atomic.StoreInt32(&c.status, int32(status)) }
+func (c *collector) count() int           { return int(atomic.LoadInt32(&c.requests)) }
+func (c *collector) host() string         { return c.Listener.Addr().String() }
+
+func sendBatches(t *testing.T, sender *HTTPTransport, n int) (errs []error) {
+	for i := 0; i < n; i++ {
+		if err := sender.send([]*j.Span{{}}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func TestHTTPEndpointsRoundRobin(t *testing.T) {
+	c1, c2 := newCollector(), newCollector()
+	defer c1.Close()
+	defer c2.Close()
+
+	factory := metricstest.NewFactory(0)
+	sender := NewHTTPTransport(c1.URL, HTTPEndpoints(c2.URL), HTTPMetrics(factory))
+	assert.Empty(t, sendBatches(t, sender, 4))
+	assert.Equal(t, 2, c1.count())
+	assert.Equal(t, 2, c2.count())
+	factory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{
+			Name:  "jaeger.tracer.collector_requests",
+			Tags:  map[string]string{"endpoint": c1.host(), "result": "ok"},
+			Value: 2,
+		},
+		metricstest.ExpectedMetric{
+			Name:  "jaeger.tracer.collector_requests",
+			Tags:  map[string]string{"endpoint": c2.host(), "result": "ok"},
+			Value: 2,
+		},
+	)
+}
+
+func TestHTTPEndpointsFailover(t *testing.T) {
+	c1, c2 := newCollector(), newCollector()
+	defer c1.Close()
+	defer c2.Close()
+	c1.setStatus(http.StatusServiceUnavailable)
+
+	factory := metricstest.NewFactory(0)
+	sender := NewHTTPTransport(c1.URL,
+		HTTPEndpoints(c2.URL),
+		HTTPCircuitBreaker(2, time.Minute),
+		HTTPMetrics(factory),
+	)
+	now := time.Now()
+	sender.endpoints.timeNow = func() time.Time { return now }
+
+	// the failed batches are sent again to the healthy endpoint
+	assert.Empty(t, sendBatches(t, sender, 4))
+	assert.Equal(t, 2, c1.count())
+	assert.Equal(t, 4, c2.count())
+
+	// the circuit breaker is open, so the unhealthy endpoint is skipped
+	assert.Empty(t, sendBatches(t, sender, 4))
+	assert.Equal(t, 2, c1.count())
+	assert.Equal(t, 8, c2.count())
+	factory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{
+			Name:  "jaeger.tracer.collector_requests",
+			Tags:  map[string]string{"endpoint": c1.host(), "result": "err"},
+			Value: 2,
+		},
+		metricstest.ExpectedMetric{
+			Name:  "jaeger.tracer.collector_circuit_breaks",
+			Tags:  map[string]string{"endpoint": c1.host()},
+			Value: 1,
+		},
+	)
+
+	// once the circuit breaker expires a single probe is sent
+	now = now.Add(time.Minute)
+	assert.Empty(t, sendBatches(t, sender, 4))
+	assert.Equal(t, 3, c1.count())
+
+	// the endpoint is used again after it recovers
+	now = now.Add(time.Minute)
+	c1.setStatus(http.StatusOK)
+	assert.Empty(t, sendBatches(t, sender, 4))
+	assert.Equal(t, 5, c1.count())
+	factory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name:  "jaeger.tracer.collector_circuit_breaks",
+		Tags:  map[string]string{"endpoint": c1.host()},
+		Value: 1,
+	})
+}
+
+func TestHTTPEndpointsErrors(t *testing.T) {
+	c1, c2 := newCollector(), newCollector()
+	defer c1.Close()
+	defer c2.Close()
+
+	// rejected batches are not sent again to another endpoint
+	c1.setStatus(http.StatusBadRequest)
+	c2.setStatus(http.StatusBadRequest)
+	sender := NewHTTPTransport(c1.URL, HTTPEndpoints(c2.URL), HTTPCircuitBreaker(1, time.Minute))
+	errs := sendBatches(t, sender, 4)
+	require.Len(t, errs, 4)
+	assert.EqualError(t, errs[0], "error from collector: 400")
+	assert.Equal(t, 2, c1.count())
+	assert.Equal(t, 2, c2.count())
+
+	// the last error is returned when all the endpoints fail
+	c1.setStatus(http.StatusInternalServerError)
+	c2.setStatus(http.StatusBadGateway)
+	errs = sendBatches(t, sender, 2)
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "error from collector: 502")
+	assert.Equal(t, errNoEndpointAvailable, errs[1])
+	assert.Equal(t, 3, c1.count())
+	assert.Equal(t, 3, c2.count())
+}
+
+func TestHTTPSRVLookup(t *testing.T) {
+	c1, c2 := newCollector(), newCollector()
+	defer c1.Close()
+	defer c2.Close()
+
+	sender := NewHTTPTransport(c1.URL+"/api/traces?format=jaeger.thrift",
+		HTTPSRVLookup("_jaeger-collector._tcp.example.com", time.Minute))
+	now := time.Now()
+	sender.endpoints.timeNow = func() time.Time { return now }
+	var lookups int32
+	var lookupErr error
+	release := make(chan struct{})
+	sender.endpoints.lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		atomic.AddInt32(&lookups, 1)
+		assert.Equal(t, "_jaeger-collector._tcp.example.com", name)
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline, "the lookup is bounded by a deadline")
+		<-release
+		if lookupErr != nil {
+			return "", nil, lookupErr
+		}
+		var records []*net.SRV
+		for _, c := range []*collector{c1, c2} {
+			u, err := url.Parse(c.URL)
+			require.NoError(t, err)
+			port, err := strconv.Atoi(u.Port())
+			require.NoError(t, err)
+			records = append(records, &net.SRV{Target: u.Hostname() + ".", Port: uint16(port)})
+		}
+		return "", records, nil
+	}
+	waitForLookup := func() {
+		for i := 0; i < 1000; i++ {
+			sender.endpoints.Lock()
+			resolving := sender.endpoints.srvResolving
+			sender.endpoints.Unlock()
+			if !resolving {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatal("the SRV lookup did not complete")
+	}
+
+	// the batches are sent to the configured endpoint while the record is resolved
+	assert.Empty(t, sendBatches(t, sender, 1))
+	assert.Equal(t, 1, c1.count())
+	close(release)
+	waitForLookup()
+	assert.EqualValues(t, 1, atomic.LoadInt32(&lookups))
+	require.Len(t, sender.endpoints.endpoints, 2)
+	assert.Equal(t, c2.URL+"/api/traces?format=jaeger.thrift", sender.endpoints.endpoints[1].url)
+
+	assert.Empty(t, sendBatches(t, sender, 4))
+	assert.Equal(t, 3, c1.count())
+	assert.Equal(t, 2, c2.count())
+	assert.EqualValues(t, 1, atomic.LoadInt32(&lookups))
+
+	// the resolved endpoints are kept when the lookup fails
+	now = now.Add(time.Minute)
+	lookupErr = errors.New("no such host")
+	assert.Empty(t, sendBatches(t, sender, 2))
+	waitForLookup()
+	assert.EqualValues(t, 2, atomic.LoadInt32(&lookups))
+	assert.Len(t, sender.endpoints.endpoints, 2)
+	assert.Equal(t, 4, c1.count())
+	assert.Equal(t, 3, c2.count())
+}
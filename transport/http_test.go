@@ -16,12 +16,16 @@ package transport
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/opentracing/opentracing-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/uber/jaeger-client-go/thrift"
@@ -100,6 +104,37 @@ func TestHTTPOptions(t *testing.T) {
 	assert.Equal(t, "my-value", sender.headers["my-key"])
 }
 
+func TestHTTPTLSConfig(t *testing.T) {
+	tlsConfig := &tls.Config{ServerName: "collector"}
+	sender := NewHTTPTransport("https://collector:14268/api/traces", HTTPTLSConfig(tlsConfig))
+	transport, ok := sender.client.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.Same(t, tlsConfig, transport.TLSClientConfig)
+	assert.NotSame(t, http.DefaultTransport, transport)
+}
+
+type failingCredentials struct{}
+
+func (failingCredentials) Apply(*http.Request) error { return errors.New("token expired") }
+
+func TestHTTPCredentials(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	sender := NewHTTPTransport(server.URL,
+		HTTPBasicAuth("Bender", "Rodriguez"),
+		HTTPCredentials(jaeger.NewStaticTokenCredentials("t0k3n")),
+	)
+	require.NoError(t, sender.send([]*j.Span{{}}))
+	assert.Equal(t, "Bearer t0k3n", authorization)
+
+	sender = NewHTTPTransport(server.URL, HTTPCredentials(failingCredentials{}))
+	assert.EqualError(t, sender.send([]*j.Span{{}}), "token expired")
+}
+
 type httpServer struct {
 	t               *testing.T
 	batches         []*j.Batch
@@ -176,3 +211,54 @@ func newHTTPServer(t *testing.T) *httpServer {
 
 	return server
 }
+
+func TestHTTPTransportReconfiguredTags(t *testing.T) {
+	var mutex sync.Mutex
+	var processes []*j.Process
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		buffer := thrift.NewTMemoryBuffer()
+		_, err = buffer.Write(body)
+		require.NoError(t, err)
+		batch := &j.Batch{}
+		require.NoError(t, batch.Read(context.Background(), thrift.NewTBinaryProtocolTransport(buffer)))
+		mutex.Lock()
+		defer mutex.Unlock()
+		processes = append(processes, batch.Process)
+	}))
+	defer server.Close()
+	getProcesses := func() []*j.Process {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return processes
+	}
+
+	tracer, closer := jaeger.NewTracer(
+		"test",
+		jaeger.NewConstSampler(true),
+		jaeger.NewRemoteReporter(NewHTTPTransport(server.URL), jaeger.ReporterOptions.BufferFlushInterval(time.Millisecond)),
+		jaeger.TracerOptions.Tag("version", "1"),
+	)
+	tracer.StartSpan("before").Finish()
+	for i := 0; i < 1000 && len(getProcesses()) == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	tracer.(*jaeger.Tracer).Reconfigure(jaeger.ReconfigureOptions.Tags([]opentracing.Tag{{Key: "version", Value: "2"}}))
+	tracer.StartSpan("after").Finish()
+	require.NoError(t, closer.Close())
+
+	got := getProcesses()
+	require.Len(t, got, 2)
+	assert.Equal(t, "1", getProcessTag(got[0], "version"))
+	assert.Equal(t, "2", getProcessTag(got[1], "version"))
+}
+
+func getProcessTag(process *j.Process, key string) string {
+	for _, tag := range process.Tags {
+		if tag.Key == key {
+			return tag.GetVStr()
+		}
+	}
+	return ""
+}
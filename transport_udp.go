@@ -115,6 +115,14 @@ func (s *udpSender) SetReporterStats(rs reporterstats.ReporterStats) {
 	s.reporterStats = rs
 }
 
+// SetProcess implements ProcessSetter. The process is built again from the next appended span,
+// which carries the current tracer-level tags.
+func (s *udpSender) SetProcess(process Process) {
+	s.byteBufferSize -= s.processByteSize
+	s.process = nil
+	s.processByteSize = 0
+}
+
 func (s *udpSender) calcSizeOfSerializedThrift(thriftStruct thrift.TStruct) int {
 	s.thriftBuffer.Reset()
 	_ = thriftStruct.Write(context.Background(), s.thriftProtocol)
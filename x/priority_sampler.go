@@ -58,6 +58,9 @@ func (s *prioritySamplerState) markSamplerFinished(index int) {
 	}
 }
 
+// SamplerTypePriority is the type of PrioritySampler reported by its State.
+const SamplerTypePriority = "priority"
+
 // PrioritySampler contains a list of samplers that it interrogates in order.
 // Sampling methods return as soon as one of the samplers returns sample=true.
 // The retryable state for each underlying sampler is stored in the extended context
@@ -69,10 +72,11 @@ type PrioritySampler struct {
 	delegates []jaeger.SamplerV2
 }
 
-type extendedStateKey string
-
-// TODO this may need to be unique per instance of the sampler.
-const prioritySamplerExtendedStateKey = extendedStateKey("priority-sampler")
+// extendedStateKey identifies the state of a PrioritySampler instance in the extended
+// sampling state of the spans, so that nested priority samplers do not share it.
+type extendedStateKey struct {
+	sampler *PrioritySampler
+}
 
 // NewPrioritySampler creates a new PrioritySampler with given delegates.
 func NewPrioritySampler(delegates ...jaeger.SamplerV2) *PrioritySampler {
@@ -126,10 +130,19 @@ func (s *PrioritySampler) Close() {
 	}
 }
 
+// State implements the introspection API used by jaeger.NewSamplerState.
+func (s *PrioritySampler) State() jaeger.SamplerState {
+	delegates := make([]jaeger.SamplerState, len(s.delegates))
+	for i, d := range s.delegates {
+		delegates[i] = jaeger.NewSamplerState(d)
+	}
+	return jaeger.SamplerState{Type: SamplerTypePriority, Delegates: delegates}
+}
+
 func (s *PrioritySampler) getState(span *jaeger.Span) *prioritySamplerState {
 	ctx := span.Context().(jaeger.SpanContext)
 	return ctx.ExtendedSamplingState(
-		prioritySamplerExtendedStateKey,
+		extendedStateKey{sampler: s},
 		func() interface{} {
 			return newPrioritySamplerState(len(s.delegates))
 		},
@@ -19,6 +19,7 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/uber/jaeger-client-go"
 )
 
@@ -66,6 +67,19 @@ func TestPrioritySampler(t *testing.T) {
 	assert.True(t, span.Context().(jaeger.SpanContext).IsSamplingFinalized())
 }
 
+func TestNestedPrioritySamplers(t *testing.T) {
+	tagSampler := NewTagMatchingSampler("theWho", []TagMatcher{{TagValue: "Leela"}})
+	sampler := NewPrioritySampler(tagSampler, NewPrioritySampler(jaeger.NewConstSampler(false)))
+	tracer, closer := jaeger.NewTracer("svc", sampler, jaeger.NewNullReporter())
+	defer closer.Close()
+
+	span := tracer.StartSpan("op1")
+	assert.False(t, span.Context().(jaeger.SpanContext).IsSampled())
+	span.SetTag("theWho", "Leela")
+	assert.True(t, span.Context().(jaeger.SpanContext).IsSampled(), "the nested sampler has its own state")
+	assert.True(t, span.Context().(jaeger.SpanContext).IsSamplingFinalized())
+}
+
 func TestPrioritySamplerFirehose(t *testing.T) {
 	tracer, closer := jaeger.NewTracer("svc", makePrioritySampler(t), jaeger.NewNullReporter())
 	defer closer.Close()
@@ -86,3 +100,23 @@ func TestPrioritySamplerFirehose(t *testing.T) {
 		assert.Equal(t, test.expectFirehose, span.Context().(jaeger.SpanContext).IsFirehose())
 	}
 }
+
+func TestPrioritySamplerState(t *testing.T) {
+	state := jaeger.NewSamplerState(makePrioritySampler(t))
+	assert.Equal(t, "priority", state.Type)
+	require.Len(t, state.Delegates, 2)
+	assert.Equal(t, jaeger.SamplerState{
+		Type: SamplerTypeTagMatching,
+		Params: map[string]interface{}{
+			"key": "theWho",
+			"matchers": []TagMatcher{
+				{TagValue: "Bender", Firehose: false},
+				{TagValue: "Leela", Firehose: true},
+			},
+		},
+	}, state.Delegates[0])
+	assert.Equal(t, jaeger.SamplerState{
+		Type:   jaeger.SamplerTypeConst,
+		Params: map[string]interface{}{"decision": false},
+	}, state.Delegates[1])
+}
@@ -0,0 +1,394 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/utils"
+)
+
+// Sources of the values matched by RuleCondition.
+const (
+	RuleSourceOperation = "operation"
+	RuleSourceTag       = "tag"
+	RuleSourceBaggage   = "baggage"
+)
+
+// SamplerTypeRules is the type of RulesSampler reported by its State
+// and in the sampler.type tag.
+const SamplerTypeRules = "rules"
+
+// Actions applied by RulesSampler when a SamplingRule matches.
+const (
+	RuleActionSample      = "sample"
+	RuleActionDrop        = "drop"
+	RuleActionDebug       = "debug"
+	RuleActionFirehose    = "firehose"
+	RuleActionRateLimited = "rateLimited"
+)
+
+// RuleCondition matches a single value of the span: its operation name,
+// a tag or a baggage item. Exactly one of Equals, Prefix, Regex or the
+// numeric range Min/Max must be specified.
+type RuleCondition struct {
+	// Source is one of "operation", "tag" or "baggage".
+	Source string `json:"source" yaml:"source"`
+
+	// Key is the tag key or baggage key. Not used for "operation".
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+
+	// Equals matches values equal to this one. Numeric values are compared
+	// as numbers, all other values are compared by their string representation.
+	Equals interface{} `json:"equals,omitempty" yaml:"equals,omitempty"`
+
+	// Prefix matches values whose string representation starts with it.
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+
+	// Regex matches values whose string representation matches the regular expression.
+	Regex string `json:"regex,omitempty" yaml:"regex,omitempty"`
+
+	// Min and Max match numeric values in the inclusive range [Min, Max].
+	// Either bound can be omitted.
+	Min *float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max *float64 `json:"max,omitempty" yaml:"max,omitempty"`
+}
+
+// SamplingRule applies Action to spans matching all of its Conditions.
+type SamplingRule struct {
+	// Name is reported in the sampler.param tag of spans sampled by the rule.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	Conditions []RuleCondition `json:"conditions" yaml:"conditions"`
+
+	// Action is one of "sample", "drop", "debug", "firehose" or "rateLimited".
+	Action string `json:"action" yaml:"action"`
+
+	// MaxTracesPerSecond is the rate limit for the "rateLimited" action.
+	MaxTracesPerSecond float64 `json:"maxTracesPerSecond,omitempty" yaml:"maxTracesPerSecond,omitempty"`
+}
+
+// RulesSamplingStrategy defines JSON and YAML format for RulesSampler strategy.
+type RulesSamplingStrategy struct {
+	Rules []SamplingRule `json:"rules" yaml:"rules"`
+}
+
+type compiledCondition struct {
+	RuleCondition
+	regex *regexp.Regexp
+}
+
+type compiledRule struct {
+	SamplingRule
+	conditions  []compiledCondition
+	rateLimiter utils.RateLimiter
+	samplerTags []jaeger.Tag
+}
+
+// RulesSampler samples traces based on an ordered list of rules that match spans
+// by operation name, tags and baggage. The first rule whose conditions all match
+// the span determines the sampling decision. If no rule matches, the decision
+// is left open, so that the sampler can be combined with others via PrioritySampler.
+//
+// Note that the "drop" action only finalizes the decision of this sampler; when
+// used as a delegate of PrioritySampler, the following delegates may still sample the span.
+type RulesSampler struct {
+	jaeger.SamplerV2Base
+
+	strategy  RulesSamplingStrategy
+	rules     []compiledRule
+	undecided jaeger.SamplingDecision
+	dropped   jaeger.SamplingDecision
+}
+
+// NewRulesSampler creates RulesSampler with the given rules.
+func NewRulesSampler(rules []SamplingRule) (*RulesSampler, error) {
+	compiled := make([]compiledRule, len(rules))
+	for i, rule := range rules {
+		if len(rule.Conditions) == 0 {
+			return nil, fmt.Errorf("rules[%d]: no conditions", i)
+		}
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("rules[%d]", i)
+		}
+		compiled[i] = compiledRule{
+			SamplingRule: rule,
+			conditions:   make([]compiledCondition, len(rule.Conditions)),
+			samplerTags: []jaeger.Tag{
+				jaeger.NewTag(jaeger.SamplerTypeTagKey, SamplerTypeRules),
+				jaeger.NewTag(jaeger.SamplerParamTagKey, name),
+			},
+		}
+		for j, c := range rule.Conditions {
+			cc, err := compileCondition(c)
+			if err != nil {
+				return nil, fmt.Errorf("rules[%d].conditions[%d]: %v", i, j, err)
+			}
+			compiled[i].conditions[j] = cc
+		}
+		switch rule.Action {
+		case RuleActionSample, RuleActionDrop, RuleActionDebug, RuleActionFirehose:
+		case RuleActionRateLimited:
+			if rule.MaxTracesPerSecond <= 0 {
+				return nil, fmt.Errorf("rules[%d]: maxTracesPerSecond must be positive for action %q", i, rule.Action)
+			}
+			compiled[i].rateLimiter = utils.NewRateLimiter(
+				rule.MaxTracesPerSecond,
+				math.Max(rule.MaxTracesPerSecond, 1.0),
+			)
+		default:
+			return nil, fmt.Errorf("rules[%d]: unknown action %q", i, rule.Action)
+		}
+	}
+	return &RulesSampler{
+		strategy:  RulesSamplingStrategy{Rules: rules},
+		rules:     compiled,
+		undecided: jaeger.SamplingDecision{Sample: false, Retryable: true},
+		dropped:   jaeger.SamplingDecision{Sample: false, Retryable: false},
+	}, nil
+}
+
+func compileCondition(c RuleCondition) (compiledCondition, error) {
+	cc := compiledCondition{RuleCondition: c}
+	switch c.Source {
+	case RuleSourceOperation:
+	case RuleSourceTag, RuleSourceBaggage:
+		if c.Key == "" {
+			return cc, fmt.Errorf("key is required for source %q", c.Source)
+		}
+	default:
+		return cc, fmt.Errorf("unknown source %q", c.Source)
+	}
+	matchers := 0
+	if c.Equals != nil {
+		matchers++
+	}
+	if c.Prefix != "" {
+		matchers++
+	}
+	if c.Regex != "" {
+		matchers++
+		regex, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return cc, err
+		}
+		cc.regex = regex
+	}
+	if c.Min != nil || c.Max != nil {
+		matchers++
+	}
+	if matchers != 1 {
+		return cc, fmt.Errorf("exactly one of equals, prefix, regex or min/max must be specified")
+	}
+	return cc, nil
+}
+
+// NewRulesSamplerFromStrategy creates RulesSampler from a strategy.
+func NewRulesSamplerFromStrategy(strategy RulesSamplingStrategy) (*RulesSampler, error) {
+	return NewRulesSampler(strategy.Rules)
+}
+
+// NewRulesSamplerFromStrategyJSON creates the sampler from a JSON configuration of the following form:
+//
+//     {
+//       "rules": [
+//         {
+//           "name": "errors",
+//           "conditions": [
+//             {"source": "tag", "key": "http.status_code", "min": 500, "max": 599}
+//           ],
+//           "action": "sample"
+//         },
+//         {
+//           "conditions": [
+//             {"source": "operation", "prefix": "GET /health"}
+//           ],
+//           "action": "drop"
+//         },
+//         {
+//           "conditions": [
+//             {"source": "baggage", "key": "tenant", "equals": "acme"},
+//             {"source": "operation", "regex": "^checkout"}
+//           ],
+//           "action": "rateLimited",
+//           "maxTracesPerSecond": 10
+//         }
+//       ]
+//     }
+func NewRulesSamplerFromStrategyJSON(jsonString []byte) (*RulesSampler, error) {
+	var strategy RulesSamplingStrategy
+	if err := json.Unmarshal(jsonString, &strategy); err != nil {
+		return nil, err
+	}
+	return NewRulesSamplerFromStrategy(strategy)
+}
+
+// NewRulesSamplerFromStrategyYAML creates the sampler from a YAML configuration
+// with the same structure as accepted by NewRulesSamplerFromStrategyJSON.
+func NewRulesSamplerFromStrategyYAML(yamlString []byte) (*RulesSampler, error) {
+	var strategy RulesSamplingStrategy
+	if err := yaml.Unmarshal(yamlString, &strategy); err != nil {
+		return nil, err
+	}
+	return NewRulesSamplerFromStrategy(strategy)
+}
+
+// Strategy returns the strategy the sampler was created from.
+func (s *RulesSampler) Strategy() RulesSamplingStrategy {
+	return s.strategy
+}
+
+// State implements the introspection API used by jaeger.NewSamplerState.
+func (s *RulesSampler) State() jaeger.SamplerState {
+	return jaeger.SamplerState{
+		Type:   SamplerTypeRules,
+		Params: map[string]interface{}{"rules": s.strategy.Rules},
+	}
+}
+
+// OnCreateSpan evaluates the rules against the new span.
+func (s *RulesSampler) OnCreateSpan(span *jaeger.Span) jaeger.SamplingDecision {
+	return s.decide(span, span.OperationName(), "", nil)
+}
+
+// OnSetOperationName evaluates the rules against the new operation name.
+func (s *RulesSampler) OnSetOperationName(span *jaeger.Span, operationName string) jaeger.SamplingDecision {
+	return s.decide(span, operationName, "", nil)
+}
+
+// OnSetTag evaluates the rules against the span including the new tag.
+func (s *RulesSampler) OnSetTag(span *jaeger.Span, key string, value interface{}) jaeger.SamplingDecision {
+	return s.decide(span, span.OperationName(), key, value)
+}
+
+// OnFinishSpan never samples.
+func (s *RulesSampler) OnFinishSpan(span *jaeger.Span) jaeger.SamplingDecision {
+	return s.undecided
+}
+
+// decide applies the first matching rule. The tag being set is passed explicitly
+// because it is not yet stored in the span when the sampler is invoked.
+func (s *RulesSampler) decide(
+	span *jaeger.Span,
+	operationName string,
+	tagKey string,
+	tagValue interface{},
+) jaeger.SamplingDecision {
+	var tags map[string]interface{}
+	lookup := func(c *compiledCondition) (interface{}, bool) {
+		switch c.Source {
+		case RuleSourceOperation:
+			return operationName, true
+		case RuleSourceBaggage:
+			v := span.BaggageItem(c.Key)
+			return v, v != ""
+		default:
+			if tagValue != nil && c.Key == tagKey {
+				return tagValue, true
+			}
+			if tags == nil {
+				tags = span.Tags()
+			}
+			v, ok := tags[c.Key]
+			return v, ok
+		}
+	}
+	for i := range s.rules {
+		rule := &s.rules[i]
+		if rule.matches(lookup) {
+			return s.apply(span, rule)
+		}
+	}
+	return s.undecided
+}
+
+func (s *RulesSampler) apply(span *jaeger.Span, rule *compiledRule) jaeger.SamplingDecision {
+	switch rule.Action {
+	case RuleActionDrop:
+		return s.dropped
+	case RuleActionRateLimited:
+		if !rule.rateLimiter.CheckCredit(1.0) {
+			return s.dropped
+		}
+	case RuleActionDebug:
+		jaeger.EnableDebug(span)
+	case RuleActionFirehose:
+		jaeger.EnableFirehose(span)
+	}
+	return jaeger.SamplingDecision{Sample: true, Retryable: false, Tags: rule.samplerTags}
+}
+
+func (r *compiledRule) matches(lookup func(c *compiledCondition) (interface{}, bool)) bool {
+	for i := range r.conditions {
+		c := &r.conditions[i]
+		value, ok := lookup(c)
+		if !ok || !c.matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *compiledCondition) matches(value interface{}) bool {
+	switch {
+	case c.Equals != nil:
+		if a, ok := numericValue(value); ok {
+			if b, ok := numericValue(c.Equals); ok {
+				return a == b
+			}
+		}
+		return fmt.Sprint(value) == fmt.Sprint(c.Equals)
+	case c.Prefix != "":
+		return strings.HasPrefix(fmt.Sprint(value), c.Prefix)
+	case c.regex != nil:
+		return c.regex.MatchString(fmt.Sprint(value))
+	default:
+		number, ok := numericValue(value)
+		if !ok {
+			if str, isString := value.(string); isString {
+				var err error
+				number, err = strconv.ParseFloat(str, 64)
+				ok = err == nil
+			}
+		}
+		if !ok {
+			return false
+		}
+		return (c.Min == nil || number >= *c.Min) && (c.Max == nil || number <= *c.Max)
+	}
+}
+
+func numericValue(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
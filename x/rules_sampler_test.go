@@ -0,0 +1,326 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x
+
+import (
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
+)
+
+const rulesStrategyJSON = `
+    {
+      "rules": [
+        {
+          "name": "errors",
+          "conditions": [
+            {"source": "tag", "key": "http.status_code", "min": 500, "max": 599}
+          ],
+          "action": "sample"
+        },
+        {
+          "conditions": [
+            {"source": "operation", "prefix": "GET /health"}
+          ],
+          "action": "drop"
+        },
+        {
+          "name": "acme",
+          "conditions": [
+            {"source": "baggage", "key": "tenant", "equals": "acme"},
+            {"source": "operation", "regex": "^checkout"}
+          ],
+          "action": "debug"
+        },
+        {
+          "name": "vip",
+          "conditions": [
+            {"source": "tag", "key": "customer.tier", "equals": 1}
+          ],
+          "action": "firehose"
+        },
+        {
+          "name": "search",
+          "conditions": [
+            {"source": "operation", "equals": "search"}
+          ],
+          "action": "rateLimited",
+          "maxTracesPerSecond": 1
+        }
+      ]
+    }
+`
+
+const rulesStrategyYAML = `
+rules:
+  - name: errors
+    conditions:
+      - source: tag
+        key: http.status_code
+        min: 500
+        max: 599
+    action: sample
+  - conditions:
+      - source: operation
+        prefix: GET /health
+    action: drop
+  - name: acme
+    conditions:
+      - source: baggage
+        key: tenant
+        equals: acme
+      - source: operation
+        regex: ^checkout
+    action: debug
+  - name: vip
+    conditions:
+      - source: tag
+        key: customer.tier
+        equals: 1
+    action: firehose
+  - name: search
+    conditions:
+      - source: operation
+        equals: search
+    action: rateLimited
+    maxTracesPerSecond: 1
+`
+
+func rulesSampler(t *testing.T) *RulesSampler {
+	sampler, err := NewRulesSamplerFromStrategyJSON([]byte(rulesStrategyJSON))
+	require.NoError(t, err)
+	return sampler
+}
+
+func TestRulesSamplerFromYAML(t *testing.T) {
+	sampler, err := NewRulesSamplerFromStrategyYAML([]byte(rulesStrategyYAML))
+	require.NoError(t, err)
+	require.Len(t, sampler.rules, 5)
+	fromJSON := rulesSampler(t)
+	for i := range sampler.rules {
+		assert.Equal(t, fromJSON.rules[i].Name, sampler.rules[i].Name)
+		assert.Equal(t, fromJSON.rules[i].Action, sampler.rules[i].Action)
+		assert.Len(t, sampler.rules[i].conditions, len(fromJSON.rules[i].conditions))
+	}
+
+	_, err = NewRulesSamplerFromStrategyYAML([]byte("rules: ["))
+	assert.Error(t, err)
+}
+
+func TestRulesSampler(t *testing.T) {
+	tests := []struct {
+		name           string
+		operation      string
+		tags           opentracing.Tags
+		baggage        map[string]string
+		setOperation   string
+		expectSampled  bool
+		expectFinal    bool
+		expectDebug    bool
+		expectFirehose bool
+		expectParam    string
+	}{
+		{
+			name:      "no match",
+			operation: "op1",
+			tags:      opentracing.Tags{"http.status_code": 200},
+		},
+		{
+			name:          "numeric range",
+			operation:     "op1",
+			tags:          opentracing.Tags{"http.status_code": 503},
+			expectSampled: true,
+			expectFinal:   true,
+			expectParam:   "errors",
+		},
+		{
+			name:          "numeric range with string value",
+			operation:     "op1",
+			tags:          opentracing.Tags{"http.status_code": "500"},
+			expectSampled: true,
+			expectFinal:   true,
+			expectParam:   "errors",
+		},
+		{
+			name:        "prefix drop",
+			operation:   "GET /health/live",
+			expectFinal: true,
+		},
+		{
+			name:          "baggage and regex debug",
+			operation:     "op1",
+			baggage:       map[string]string{"tenant": "acme"},
+			setOperation:  "checkout-cart",
+			expectSampled: true,
+			expectFinal:   true,
+			expectDebug:   true,
+			expectParam:   "acme",
+		},
+		{
+			name:         "baggage mismatch",
+			operation:    "op1",
+			baggage:      map[string]string{"tenant": "other"},
+			setOperation: "checkout-cart",
+		},
+		{
+			name:           "numeric equals firehose",
+			operation:      "op1",
+			tags:           opentracing.Tags{"customer.tier": int64(1)},
+			expectSampled:  true,
+			expectFinal:    true,
+			expectFirehose: true,
+			expectParam:    "vip",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracer, closer := jaeger.NewTracer("svc", rulesSampler(t), jaeger.NewNullReporter())
+			defer closer.Close()
+
+			span := tracer.StartSpan(test.operation).(*jaeger.Span)
+			for k, v := range test.baggage {
+				span.SetBaggageItem(k, v)
+			}
+			for k, v := range test.tags {
+				span.SetTag(k, v)
+			}
+			if test.setOperation != "" {
+				span.SetOperationName(test.setOperation)
+			}
+			ctx := span.SpanContext()
+			assert.Equal(t, test.expectSampled, ctx.IsSampled())
+			assert.Equal(t, test.expectFinal, ctx.IsSamplingFinalized())
+			assert.Equal(t, test.expectDebug, ctx.IsDebug())
+			assert.Equal(t, test.expectFirehose, ctx.IsFirehose())
+			if test.expectParam != "" {
+				assert.Equal(t, SamplerTypeRules, span.Tags()["sampler.type"])
+				assert.Equal(t, test.expectParam, span.Tags()["sampler.param"])
+			}
+		})
+	}
+}
+
+func TestRulesSamplerStartSpanTags(t *testing.T) {
+	tracer, closer := jaeger.NewTracer("svc", rulesSampler(t), jaeger.NewNullReporter())
+	defer closer.Close()
+
+	span := tracer.StartSpan("op1", opentracing.Tag{Key: "http.status_code", Value: 500})
+	assert.True(t, span.Context().(jaeger.SpanContext).IsSampled())
+}
+
+func TestRulesSamplerRateLimited(t *testing.T) {
+	tracer, closer := jaeger.NewTracer("svc", rulesSampler(t), jaeger.NewNullReporter())
+	defer closer.Close()
+
+	span1 := tracer.StartSpan("search")
+	assert.True(t, span1.Context().(jaeger.SpanContext).IsSampled())
+	span2 := tracer.StartSpan("search")
+	assert.False(t, span2.Context().(jaeger.SpanContext).IsSampled())
+	assert.True(t, span2.Context().(jaeger.SpanContext).IsSamplingFinalized())
+}
+
+func TestRulesSamplerWithPrioritySampler(t *testing.T) {
+	sampler := NewPrioritySampler(rulesSampler(t), jaeger.NewConstSampler(false))
+	tracer, closer := jaeger.NewTracer("svc", sampler, jaeger.NewNullReporter())
+	defer closer.Close()
+
+	span := tracer.StartSpan("op1")
+	assert.False(t, span.Context().(jaeger.SpanContext).IsSampled())
+	assert.False(t, span.Context().(jaeger.SpanContext).IsSamplingFinalized())
+	span.SetTag("http.status_code", 500)
+	assert.True(t, span.Context().(jaeger.SpanContext).IsSampled())
+}
+
+func TestRulesSamplerErrors(t *testing.T) {
+	min := 1.0
+	tests := []struct {
+		name  string
+		rule  SamplingRule
+		error string
+	}{
+		{
+			name:  "no conditions",
+			rule:  SamplingRule{Action: RuleActionSample},
+			error: "rules[0]: no conditions",
+		},
+		{
+			name: "unknown source",
+			rule: SamplingRule{
+				Conditions: []RuleCondition{{Source: "span", Equals: "x"}},
+				Action:     RuleActionSample,
+			},
+			error: `rules[0].conditions[0]: unknown source "span"`,
+		},
+		{
+			name: "missing key",
+			rule: SamplingRule{
+				Conditions: []RuleCondition{{Source: RuleSourceTag, Equals: "x"}},
+				Action:     RuleActionSample,
+			},
+			error: `rules[0].conditions[0]: key is required for source "tag"`,
+		},
+		{
+			name: "multiple matchers",
+			rule: SamplingRule{
+				Conditions: []RuleCondition{{Source: RuleSourceOperation, Prefix: "x", Min: &min}},
+				Action:     RuleActionSample,
+			},
+			error: "rules[0].conditions[0]: exactly one of equals, prefix, regex or min/max must be specified",
+		},
+		{
+			name: "bad regex",
+			rule: SamplingRule{
+				Conditions: []RuleCondition{{Source: RuleSourceOperation, Regex: "("}},
+				Action:     RuleActionSample,
+			},
+			error: "rules[0].conditions[0]: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name: "unknown action",
+			rule: SamplingRule{
+				Conditions: []RuleCondition{{Source: RuleSourceOperation, Equals: "x"}},
+				Action:     "maybe",
+			},
+			error: `rules[0]: unknown action "maybe"`,
+		},
+		{
+			name: "missing rate",
+			rule: SamplingRule{
+				Conditions: []RuleCondition{{Source: RuleSourceOperation, Equals: "x"}},
+				Action:     RuleActionRateLimited,
+			},
+			error: `rules[0]: maxTracesPerSecond must be positive for action "rateLimited"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewRulesSampler([]SamplingRule{test.rule})
+			assert.EqualError(t, err, test.error)
+		})
+	}
+
+	_, err := NewRulesSamplerFromStrategyJSON([]byte("bad json"))
+	assert.Error(t, err)
+}
+
+func TestRulesSamplerState(t *testing.T) {
+	sampler := rulesSampler(t)
+	state := jaeger.NewSamplerState(sampler)
+	assert.Equal(t, SamplerTypeRules, state.Type)
+	assert.Equal(t, sampler.Strategy().Rules, state.Params["rules"])
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/thrift-gen/sampling"
+)
+
+// RulesSamplingStrategyResponse extends the standard sampling strategy response
+// with an optional RulesSampler strategy.
+type RulesSamplingStrategyResponse struct {
+	sampling.SamplingStrategyResponse
+
+	RulesSampling *RulesSamplingStrategy `json:"rulesSampling,omitempty"`
+}
+
+// RulesSamplingStrategyParser is a jaeger.SamplingStrategyParser that parses
+// responses into RulesSamplingStrategyResponse.
+type RulesSamplingStrategyParser struct{}
+
+// Parse implements Parse of jaeger.SamplingStrategyParser.
+func (p RulesSamplingStrategyParser) Parse(response []byte) (interface{}, error) {
+	strategy := new(RulesSamplingStrategyResponse)
+	if err := json.Unmarshal(response, strategy); err != nil {
+		return nil, err
+	}
+	return strategy, nil
+}
+
+// RulesSamplerUpdater is a jaeger.SamplerUpdater that applies RulesSamplingStrategyResponse
+// produced by RulesSamplingStrategyParser. The standard part of the response is applied by
+// the delegate updaters, and if the response contains rules the resulting sampler is combined
+// with a RulesSampler via PrioritySampler, with the rules taking precedence, e.g.
+//
+//     sampler := jaeger.NewRemotelyControlledSampler(
+//         serviceName,
+//         jaeger.SamplerOptions.SamplingStrategyParser(x.RulesSamplingStrategyParser{}),
+//         jaeger.SamplerOptions.Updaters(x.NewRulesSamplerUpdater()),
+//     )
+type RulesSamplerUpdater struct {
+	updaters []jaeger.SamplerUpdater
+
+	lock          sync.Mutex
+	rules         *RulesSampler
+	mainSampler   jaeger.SamplerV2
+	activeSampler jaeger.SamplerV2
+}
+
+// NewRulesSamplerUpdater creates RulesSamplerUpdater with the given delegate updaters.
+// If no updaters are given, the adaptive, probabilistic and rate limiting updaters are used.
+func NewRulesSamplerUpdater(updaters ...jaeger.SamplerUpdater) *RulesSamplerUpdater {
+	if len(updaters) == 0 {
+		updaters = []jaeger.SamplerUpdater{
+			new(jaeger.AdaptiveSamplerUpdater),
+			new(jaeger.ProbabilisticSamplerUpdater),
+			new(jaeger.RateLimitingSamplerUpdater),
+		}
+	}
+	return &RulesSamplerUpdater{updaters: updaters}
+}
+
+// Update implements Update of jaeger.SamplerUpdater.
+func (u *RulesSamplerUpdater) Update(sampler jaeger.SamplerV2, strategy interface{}) (jaeger.SamplerV2, error) {
+	resp, ok := strategy.(*RulesSamplingStrategyResponse)
+	if !ok {
+		return nil, nil
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	// unwrap the sampler we created on the previous update
+	mainSampler := sampler
+	if u.activeSampler != nil && sampler == u.activeSampler {
+		mainSampler = u.mainSampler
+	}
+
+	updated, err := u.applyUpdaters(mainSampler, &resp.SamplingStrategyResponse)
+	if err != nil {
+		return nil, err
+	}
+	if updated != nil {
+		mainSampler = updated
+	} else if resp.RulesSampling == nil {
+		return nil, nil
+	}
+	u.mainSampler = mainSampler
+
+	if resp.RulesSampling == nil {
+		u.rules, u.activeSampler = nil, nil
+		return mainSampler, nil
+	}
+	// keep the existing rules sampler (and its rate limiters) if the rules did not change
+	if u.rules == nil || !reflect.DeepEqual(u.rules.Strategy(), *resp.RulesSampling) {
+		rules, err := NewRulesSamplerFromStrategy(*resp.RulesSampling)
+		if err != nil {
+			return nil, err
+		}
+		u.rules = rules
+	}
+	u.activeSampler = NewPrioritySampler(u.rules, mainSampler)
+	return u.activeSampler, nil
+}
+
+func (u *RulesSamplerUpdater) applyUpdaters(
+	sampler jaeger.SamplerV2,
+	res *sampling.SamplingStrategyResponse,
+) (jaeger.SamplerV2, error) {
+	for _, updater := range u.updaters {
+		sampler, err := updater.Update(sampler, res)
+		if err != nil {
+			return nil, err
+		}
+		if sampler != nil {
+			return sampler, nil
+		}
+	}
+	return nil, nil
+}
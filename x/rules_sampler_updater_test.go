@@ -0,0 +1,119 @@
+// Copyright (c) 2021 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/testutils"
+	"github.com/uber/jaeger-client-go/thrift-gen/sampling"
+)
+
+func TestRulesSamplerUpdater(t *testing.T) {
+	agent, err := testutils.StartMockAgent()
+	require.NoError(t, err)
+	defer agent.Close()
+
+	updater := NewRulesSamplerUpdater()
+	sampler := jaeger.NewRemotelyControlledSampler(
+		"service",
+		jaeger.SamplerOptions.SamplingServerURL("http://"+agent.SamplingServerAddr()),
+		jaeger.SamplerOptions.SamplingRefreshInterval(time.Minute),
+		jaeger.SamplerOptions.SamplingStrategyParser(RulesSamplingStrategyParser{}),
+		jaeger.SamplerOptions.Updaters(updater),
+	)
+	sampler.Close() // stop timer-based updates, we want to call them manually
+
+	tracer, closer := jaeger.NewTracer("service", sampler, jaeger.NewNullReporter())
+	defer closer.Close()
+
+	neverSample := sampling.SamplingStrategyResponse{
+		StrategyType: sampling.SamplingStrategyType_PROBABILISTIC,
+		ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{
+			SamplingRate: 0.0,
+		},
+	}
+	rules := &RulesSamplingStrategy{
+		Rules: []SamplingRule{
+			{
+				Name:       "checkout",
+				Conditions: []RuleCondition{{Source: RuleSourceOperation, Equals: "checkout"}},
+				Action:     RuleActionSample,
+			},
+		},
+	}
+
+	// step 1 - probabilistic sampler only
+	agent.AddSamplingStrategy("service", &neverSample)
+	sampler.UpdateSampler()
+	assert.IsType(t, new(jaeger.ProbabilisticSampler), sampler.Sampler())
+
+	// step 2 - rules take precedence over the probabilistic sampler
+	agent.AddSamplingStrategy("service", &RulesSamplingStrategyResponse{
+		SamplingStrategyResponse: neverSample,
+		RulesSampling:            rules,
+	})
+	sampler.UpdateSampler()
+	require.IsType(t, new(PrioritySampler), sampler.Sampler())
+	firstRules := updater.rules
+	assert.Equal(t, SamplerTypePriority, sampler.State().Type)
+
+	span := tracer.StartSpan("checkout")
+	assert.True(t, span.Context().(jaeger.SpanContext).IsSampled())
+	span = tracer.StartSpan("other")
+	assert.False(t, span.Context().(jaeger.SpanContext).IsSampled())
+
+	// step 3 - unchanged rules keep the same rules sampler
+	sampler.UpdateSampler()
+	require.IsType(t, new(PrioritySampler), sampler.Sampler())
+	assert.Same(t, firstRules, updater.rules)
+	assert.IsType(t, new(jaeger.ProbabilisticSampler), updater.mainSampler)
+
+	// step 4 - rules removed
+	agent.AddSamplingStrategy("service", &neverSample)
+	sampler.UpdateSampler()
+	assert.IsType(t, new(jaeger.ProbabilisticSampler), sampler.Sampler())
+}
+
+func TestRulesSamplerUpdaterIgnoresOtherStrategies(t *testing.T) {
+	updater := NewRulesSamplerUpdater()
+	s, err := updater.Update(jaeger.NewConstSampler(true), new(sampling.SamplingStrategyResponse))
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	s, err = updater.Update(jaeger.NewConstSampler(true), new(RulesSamplingStrategyResponse))
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	_, err = updater.Update(jaeger.NewConstSampler(true), &RulesSamplingStrategyResponse{
+		RulesSampling: &RulesSamplingStrategy{Rules: []SamplingRule{{Action: RuleActionSample}}},
+	})
+	assert.EqualError(t, err, "rules[0]: no conditions")
+}
+
+func TestRulesSamplingStrategyParser(t *testing.T) {
+	strategy, err := RulesSamplingStrategyParser{}.Parse([]byte(`{"rulesSampling":` + rulesStrategyJSON + `}`))
+	require.NoError(t, err)
+	resp := strategy.(*RulesSamplingStrategyResponse)
+	require.NotNil(t, resp.RulesSampling)
+	assert.Len(t, resp.RulesSampling.Rules, 5)
+
+	_, err = RulesSamplingStrategyParser{}.Parse([]byte("bad json"))
+	assert.Error(t, err)
+}
@@ -17,16 +17,20 @@ package x
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/uber/jaeger-client-go"
 )
 
 // TagMatcher describes which values TagMatchingSampler will match.
 type TagMatcher struct {
-	TagValue interface{} `json:"value"`
-	Firehose bool        `json:"firehose"`
+	TagValue interface{} `json:"value" yaml:"value"`
+	Firehose bool        `json:"firehose" yaml:"firehose"`
 }
 
+// SamplerTypeTagMatching is the type of TagMatchingSampler reported by its State.
+const SamplerTypeTagMatching = "tagmatching"
+
 // TagMatchingSampler samples traces that have spans with a particular tag value(s).
 type TagMatchingSampler struct {
 	jaeger.SamplerV2Base
@@ -112,6 +116,24 @@ func NewTagMatchingSamplerFromStrategy(strategy TagMatchingSamplingStrategy) *Ta
 	return NewTagMatchingSampler(strategy.Key, strategy.Matchers)
 }
 
+// State implements the introspection API used by jaeger.NewSamplerState.
+func (s *TagMatchingSampler) State() jaeger.SamplerState {
+	matchers := make([]TagMatcher, 0, len(s.matchersByValue))
+	for _, m := range s.matchersByValue {
+		matchers = append(matchers, m)
+	}
+	sort.Slice(matchers, func(i, j int) bool {
+		return fmt.Sprint(matchers[i].TagValue) < fmt.Sprint(matchers[j].TagValue)
+	})
+	return jaeger.SamplerState{
+		Type: SamplerTypeTagMatching,
+		Params: map[string]interface{}{
+			"key":      s.tagKey,
+			"matchers": matchers,
+		},
+	}
+}
+
 func (s *TagMatchingSampler) decide(span *jaeger.Span, value interface{}) jaeger.SamplingDecision {
 	matcher, ok := s.matchersByValue[value]
 	if !ok {
@@ -58,7 +58,7 @@ func BuildZipkinThrift(s *Span) *z.Span {
 	duration := span.duration.Nanoseconds() / int64(time.Microsecond)
 	endpoint := &z.Endpoint{
 		ServiceName: span.tracer.serviceName,
-		Ipv4:        int32(span.tracer.hostIPv4)}
+		Ipv4:        int32(span.tracer.getComponents().hostIPv4)}
 	thriftSpan := &z.Span{
 		TraceID:           int64(span.context.traceID.Low),
 		TraceIDHigh:       ptrTraceIDHigh,
@@ -156,6 +156,11 @@ func buildBinaryAnnotations(span *zipkinSpan, endpoint *z.Endpoint) []*z.BinaryA
 			annotations = append(annotations, anno)
 		}
 	}
+	for _, tag := range span.droppedCountTags() {
+		if anno := buildBinaryAnnotation(tag.key, tag.value, span.tracer.options.maxTagValueLength, nil); anno != nil {
+			annotations = append(annotations, anno)
+		}
+	}
 	return annotations
 }
 
@@ -183,8 +188,7 @@ func buildBinaryAnnotation(key string, val interface{}, maxTagValueLength int, e
 		bann.Value = []byte{boolToByte(value)}
 		bann.AnnotationType = z.AnnotationType_BOOL
 	} else {
-		value := stringify(val)
-		bann.Value = []byte(truncateString(value, maxTagValueLength))
+		bann.Value = []byte(stringifyTagValue(val, maxTagValueLength))
 		bann.AnnotationType = z.AnnotationType_STRING
 	}
 	return bann
@@ -249,7 +253,7 @@ func (s *zipkinSpan) handleSpecialTags() {
 	defer s.Unlock()
 	if s.firstInProcess {
 		// append the process tags
-		s.tags = append(s.tags, s.tracer.tags...)
+		s.tags = append(s.tags, s.tracer.getComponents().tags...)
 	}
 	filteredTags := make([]Tag, 0, len(s.tags))
 	for _, tag := range s.tags {